@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+	auditdomain "github.com/huavcjj/flux/internal/domain/audit"
+)
+
+const auditUsage = `usage:
+  server audit export <user-id>   write the audit events of a user to stdout as JSON lines`
+
+func runAudit(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 2 || args[0] != "export" {
+		return errors.New(auditUsage)
+	}
+
+	container, err := di.NewContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	userID := args[1]
+	written, err := container.AuditService.ExportJSONL(ctx, userID, os.Stdout)
+
+	result := auditdomain.ResultSuccess
+	if err != nil {
+		result = auditdomain.ResultFailure
+	}
+	container.AuditService.Log(ctx, auditdomain.Event{
+		Actor:         auditdomain.ActorAdminPrefix + "cli",
+		SubjectUserID: &userID,
+		Action:        auditdomain.ActionAdminPrefix + "audit.export",
+		Result:        result,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d events\n", written)
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+)
+
+const backfillUsage = `usage:
+  server backfill -since <duration|date> [-user line-user-id] [-notify]`
+
+func runBackfill(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	sinceFlag := fs.String("since", "", `start of the backfill, as a duration ("48h") or date ("2025-11-01")`)
+	lineUserID := fs.String("user", "", "only backfill this LINE user")
+	notify := fs.Bool("notify", false, "send notifications for the backfilled messages")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sinceFlag == "" || fs.NArg() > 0 {
+		return errors.New(backfillUsage)
+	}
+
+	since, err := parseSince(*sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	container, err := di.NewContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	lineUserIDs := []string{*lineUserID}
+	if *lineUserID == "" {
+		users, err := container.UserRepo.GetAllActiveUsers(ctx)
+		if err != nil {
+			return err
+		}
+		lineUserIDs = lineUserIDs[:0]
+		for _, u := range users {
+			if u.MailAccessToken != nil && *u.MailAccessToken != "" {
+				lineUserIDs = append(lineUserIDs, u.LineUserID)
+			}
+		}
+	}
+
+	failed := 0
+	for _, id := range lineUserIDs {
+		n, err := container.NotificationService.Backfill(ctx, id, since, *notify)
+		if err != nil {
+			slog.Error("failed to backfill", "user_id", id, "error", err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s\t%d messages\n", id, n)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to backfill %d of %d users", failed, len(lineUserIDs))
+	}
+	return nil
+}
+
+func parseSince(v string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid -since %q: use a duration like 48h or a date like 2025-11-01", v)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -9,14 +11,46 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"github.com/huavcjj/flux/internal/config"
 	"github.com/huavcjj/flux/internal/di"
+	"github.com/huavcjj/flux/internal/handler/admin"
+	"github.com/huavcjj/flux/internal/handler/export"
 	"github.com/huavcjj/flux/internal/handler/oauth"
 	"github.com/huavcjj/flux/internal/handler/webhook"
+	"github.com/huavcjj/flux/internal/metrics"
+	"github.com/huavcjj/flux/internal/service/notification"
+	"github.com/huavcjj/flux/internal/tracing"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+const usage = `usage: server [config flags] <command> [args]
+
+commands:
+  serve      start the HTTP server (default)
+  migrate    apply or roll back database migrations
+  users      list users or resync a mailbox
+  watch      renew Gmail watches
+  notify     send a test notification
+  backfill   store messages missed since a point in time
+  richmenu   provision LINE rich menus
+  audit      export the audit trail of a user`
+
+// requirements lists the integrations each command uses besides the
+// database, so operator commands run without the secrets of the server.
+var requirements = map[string]config.Requirement{
+	"serve":    config.RequireServer,
+	"migrate":  0,
+	"users":    0,
+	"watch":    config.RequireMail,
+	"notify":   config.RequireLine,
+	"backfill": config.RequireMail,
+	"richmenu": config.RequireLine,
+	"audit":    0,
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		slog.Warn("no .env file found")
@@ -26,27 +60,72 @@ func main() {
 		syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	defer cancel()
 
-	if err := run(ctx); err != nil {
-		slog.Error("application error", "error", err)
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
 		os.Exit(1)
 	}
-}
 
-func run(ctx context.Context) error {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// A config printed for inspection may still be incomplete.
+	if cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		return
+	}
+
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	if required, ok := requirements[command]; ok {
+		if err := cfg.Validate(required); err != nil {
+			slog.Error("invalid configuration", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	switch command {
+	case "serve":
+		err = run(ctx, cfg)
+	case "migrate":
+		err = runMigrate(ctx, cfg, args)
+	case "users":
+		err = runUsers(ctx, cfg, args)
+	case "watch":
+		err = runWatch(ctx, cfg, args)
+	case "notify":
+		err = runNotify(ctx, cfg, args)
+	case "backfill":
+		err = runBackfill(ctx, cfg, args)
+	case "richmenu":
+		err = runRichMenu(ctx, cfg, args)
+	case "audit":
+		err = runAudit(ctx, cfg, args)
+	default:
+		err = errors.New(usage)
 	}
 
-	cfg := di.Config{
-		LineChannelToken:     os.Getenv("LINE_CHANNEL_TOKEN"),
-		GmailCredentialsPath: os.Getenv("GMAIL_CREDENTIALS_PATH"),
-		DBHost:               os.Getenv("DB_HOST"),
-		DBPort:               os.Getenv("DB_PORT"),
-		DBUser:               os.Getenv("DB_USER"),
-		DBPassword:           os.Getenv("DB_PASSWORD"),
-		DBName:               os.Getenv("DB_NAME"),
+	if err != nil {
+		slog.Error("application error", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
 
 	container, err := di.NewContainer(ctx, cfg)
 	if err != nil {
@@ -54,31 +133,102 @@ func run(ctx context.Context) error {
 	}
 	defer container.Close()
 
-	lineWebhookHandler := webhook.NewLineWebhookHandler(container.NotificationService)
-	pubsubWebhookHandler := webhook.NewPubSubWebhookHandler(container.NotificationService)
-	gmailOAuthHandler := oauth.NewGmailOAuthHandler(container.NotificationService)
+	if err := migrateOnStart(ctx, container.DB, cfg.Database.Driver, cfg.Database.MigrateOnStart); err != nil {
+		return err
+	}
+
+	// Emails stored before notifications went through the outbox would
+	// otherwise never be notified.
+	if _, err := container.NotificationService.BackfillOutbox(ctx); err != nil {
+		slog.Error("failed to backfill notification outbox", "error", err)
+	}
+
+	lineWebhookHandler := webhook.NewLineWebhookHandler(container.NotificationService, container.EventRepo, webhook.LineWebhookConfig{
+		ChannelSecret:    cfg.Line.ChannelSecret,
+		MailListLimit:    cfg.Mail.ListDefault,
+		MailListMaxLimit: cfg.Mail.ListMaxLimit,
+	})
+	exportHandler := export.NewExportHandler(container.NotificationService, container.AuditService)
+
+	if err := prometheus.Register(metrics.NewStoreCollector(container.JobRepo, container.OutboxRepo, container.UserRepo)); err != nil {
+		return fmt.Errorf("failed to register metrics collector: %w", err)
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook/line", lineWebhookHandler.HandleWebhook)
-	mux.HandleFunc("/webhook/pubsub", pubsubWebhookHandler.HandlePubSub)
-	mux.HandleFunc("/oauth/gmail/callback", gmailOAuthHandler.HandleCallback)
+	handle := func(route string, h http.HandlerFunc) {
+		mux.Handle(route, otelhttp.NewHandler(metrics.InstrumentHandler(route, h), route))
+	}
+	handle("/webhook/line", lineWebhookHandler.HandleWebhook)
+	if cfg.Gmail.Enabled() {
+		pubsubWebhookHandler := webhook.NewPubSubWebhookHandler(container.QueueService)
+		gmailOAuthHandler := oauth.NewGmailOAuthHandler(container.NotificationService, container.AuditService)
+		handle("/webhook/pubsub", pubsubWebhookHandler.HandlePubSub)
+		handle("/oauth/gmail/callback", gmailOAuthHandler.HandleCallback)
+	}
+	if cfg.Outlook.Enabled {
+		outlookWebhookHandler := webhook.NewOutlookWebhookHandler(container.QueueService, cfg.Outlook.ClientState)
+		outlookOAuthHandler := oauth.NewOutlookOAuthHandler(container.NotificationService, container.AuditService)
+		handle("/webhook/outlook", outlookWebhookHandler.HandleWebhook)
+		handle("/oauth/outlook/callback", outlookOAuthHandler.HandleCallback)
+	}
+	if cfg.Telegram.Enabled {
+		telegramWebhookHandler := webhook.NewTelegramWebhookHandler(container.NotificationService, container.EventRepo, container.TelegramRepo, webhook.TelegramWebhookConfig{
+			SecretToken:      cfg.Telegram.WebhookSecret,
+			MailListLimit:    cfg.Mail.ListDefault,
+			MailListMaxLimit: cfg.Mail.ListMaxLimit,
+		})
+		handle("/webhook/telegram", telegramWebhookHandler.HandleWebhook)
+	}
+	handle(notification.ExportPath, exportHandler.HandleDownload)
+	if cfg.Admin.Enabled() {
+		adminHandler := admin.NewAdminHandler(container.AdminService, container.AuditService, container.HookService, admin.Config{
+			Token:           cfg.Admin.Token,
+			AllowClientCert: cfg.Admin.ClientCAFile != "",
+		})
+		mux.Handle("/admin/", otelhttp.NewHandler(metrics.InstrumentHandler("/admin/", adminHandler.Routes().ServeHTTP), "/admin/"))
+		// The metrics expose user and queue counts, so scrapers authenticate
+		// like admin clients.
+		mux.Handle("/metrics", adminHandler.Protect(metrics.Handler()))
+	} else {
+		slog.Info("admin authentication is not configured, /metrics is disabled")
+	}
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, "OK")
 	})
 
 	server := &http.Server{
-		Addr:         ":" + port,
+		Addr:         ":" + cfg.Server.Port,
 		Handler:      mux,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	if cfg.Admin.ClientCAFile != "" {
+		tlsConfig, err := clientCertTLSConfig(cfg.Admin.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = tlsConfig
 	}
 
+	container.QueueService.Start(ctx)
+	container.OutboxDispatcher.Start(ctx)
+	container.HookDispatcher.Start(ctx)
+	container.EmailPruner.Start(ctx)
+	container.WatchRenewer.Start(ctx)
+
 	serverErr := make(chan error, 1)
 	go func() {
 		slog.Info("starting server", "address", server.Addr)
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if cfg.Server.TLSCertFile != "" {
+			err = server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverErr <- err
 		}
 	}()
@@ -90,13 +240,51 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("server error: %w", err)
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("shutdown error: %w", err)
 	}
 
+	if err := container.QueueService.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("job queue shutdown error: %w", err)
+	}
+
+	if err := container.OutboxDispatcher.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("outbox dispatcher shutdown error: %w", err)
+	}
+	if err := container.HookDispatcher.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("webhook dispatcher shutdown error: %w", err)
+	}
+
+	if err := container.EmailPruner.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("email pruner shutdown error: %w", err)
+	}
+	if err := container.WatchRenewer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("watch renewer shutdown error: %w", err)
+	}
+
 	slog.Info("shutdown completed")
 	return nil
 }
+
+// clientCertTLSConfig asks clients for a certificate signed by the CAs in
+// caFile. Other clients can still connect, since only /admin/ relies on it.
+func clientCertTLSConfig(caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admin client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
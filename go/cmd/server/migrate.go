@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/huavcjj/flux/db"
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+	"github.com/huavcjj/flux/internal/migrate"
+)
+
+const migrateUsage = `usage:
+  server migrate up
+  server migrate down
+  server migrate status`
+
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	dbConn, err := di.OpenDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	migrator, err := newMigrator(dbConn, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return nil
+
+	case "down":
+		m, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("no migration to roll back")
+			return nil
+		}
+		fmt.Printf("rolled back %s\n", m.Name)
+		return nil
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		pending := 0
+		for _, s := range statuses {
+			state := "applied"
+			if !s.Applied {
+				state = "pending"
+				pending++
+			}
+			fmt.Fprintf(w, "%s\t%s\n", state, s.Migration.Name)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d applied, %d pending\n", len(statuses)-pending, pending)
+
+		_, err = migrator.Check(ctx)
+		return err
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func newMigrator(dbConn *sql.DB, driver string) (*migrate.Migrator, error) {
+	return migrate.New(dbConn, driver, db.Migrations, db.MigrationsDir(driver))
+}
+
+// migrateOnStart brings the schema up to date before the server starts, or
+// only verifies it when automatic migration is disabled. Either way the
+// server refuses to run against a schema from a newer release.
+func migrateOnStart(ctx context.Context, dbConn *sql.DB, driver string, apply bool) error {
+	migrator, err := newMigrator(dbConn, driver)
+	if err != nil {
+		return err
+	}
+
+	if !apply {
+		pending, err := migrator.Check(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check schema: %w", err)
+		}
+		if pending > 0 {
+			slog.Warn("database has pending migrations", "count", pending)
+		}
+		return nil
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		slog.Info("migration applied", "migration", m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
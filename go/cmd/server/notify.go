@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+)
+
+const notifyUsage = `usage:
+  server notify test <line-user-id>`
+
+func runNotify(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 2 || args[0] != "test" {
+		return errors.New(notifyUsage)
+	}
+
+	container, err := di.NewContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	if err := container.NotificationService.SendTestNotification(ctx, args[1]); err != nil {
+		return err
+	}
+	fmt.Println("test notification sent")
+	return nil
+}
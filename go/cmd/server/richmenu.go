@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+	linedomain "github.com/huavcjj/flux/internal/domain/line"
+	"github.com/huavcjj/flux/internal/service/richmenu"
+)
+
+const richMenuUsage = `usage:
+  server richmenu apply [-file richmenu/richmenu.json]
+  server richmenu link <line-user-id> [alias]
+  server richmenu unlink <line-user-id>`
+
+func runRichMenu(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(richMenuUsage)
+	}
+
+	container, err := di.NewContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	switch args[0] {
+	case "apply":
+		fs := flag.NewFlagSet("richmenu apply", flag.ContinueOnError)
+		file := fs.String("file", "richmenu/richmenu.json", "rich menu definition file")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		def, err := richmenu.LoadDefinition(*file)
+		if err != nil {
+			return err
+		}
+		return container.RichMenuService.Apply(ctx, def)
+
+	case "link":
+		if len(args) < 2 {
+			return errors.New(richMenuUsage)
+		}
+		alias := linedomain.RichMenuAliasLinked
+		if len(args) > 2 {
+			alias = args[2]
+		}
+		return container.RichMenuService.Link(ctx, args[1], alias)
+
+	case "unlink":
+		if len(args) < 2 {
+			return errors.New(richMenuUsage)
+		}
+		return container.RichMenuService.Unlink(ctx, args[1])
+
+	default:
+		return errors.New(richMenuUsage)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+	userdomain "github.com/huavcjj/flux/internal/domain/user"
+)
+
+const usersUsage = `usage:
+  server users list [-q query] [-limit n] [-offset n]
+  server users resync <user-id>`
+
+func runUsers(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usersUsage)
+	}
+
+	// Listing users only reads the database.
+	if args[0] == "resync" {
+		if err := cfg.Validate(config.RequireMail); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	container, err := di.NewContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("users list", flag.ContinueOnError)
+		query := fs.String("q", "", "user ID or part of the LINE user ID")
+		limit := fs.Int("limit", 50, "maximum number of users")
+		offset := fs.Int("offset", 0, "number of users to skip")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		users, err := container.AdminService.ListUsers(ctx, userdomain.ListFilter{Query: *query, Limit: *limit, Offset: *offset})
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tLINE USER ID\tACTIVE\tGMAIL\tWATCH EXPIRES")
+		for _, u := range users {
+			linked := u.MailAccessToken != nil && *u.MailAccessToken != ""
+			expires := "-"
+			if u.MailWatchExpiresAt != nil {
+				expires = time.Unix(*u.MailWatchExpiresAt, 0).Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%s\n", u.ID, u.LineUserID, u.IsActive, linked, expires)
+		}
+		return w.Flush()
+
+	case "resync":
+		if len(args) != 2 {
+			return errors.New(usersUsage)
+		}
+
+		user, err := container.AdminService.GetUser(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if err := container.NotificationService.ResyncUser(ctx, user.LineUserID); err != nil {
+			return err
+		}
+		fmt.Println("resync completed; new notifications are delivered by the running server")
+		return nil
+
+	default:
+		return errors.New(usersUsage)
+	}
+}
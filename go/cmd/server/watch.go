@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/huavcjj/flux/internal/config"
+	"github.com/huavcjj/flux/internal/di"
+)
+
+const watchUsage = `usage:
+  server watch renew <line-user-id>
+  server watch renew -all`
+
+func runWatch(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "renew" {
+		return errors.New(watchUsage)
+	}
+
+	fs := flag.NewFlagSet("watch renew", flag.ContinueOnError)
+	all := fs.Bool("all", false, "renew the watch of every linked user")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *all == (fs.NArg() == 1) || fs.NArg() > 1 {
+		return errors.New(watchUsage)
+	}
+
+	container, err := di.NewContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	lineUserIDs := fs.Args()
+	if *all {
+		users, err := container.UserRepo.GetAllActiveUsers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if u.MailAccessToken != nil && *u.MailAccessToken != "" {
+				lineUserIDs = append(lineUserIDs, u.LineUserID)
+			}
+		}
+	}
+
+	failed := 0
+	for _, id := range lineUserIDs {
+		watch, err := container.NotificationService.RenewWatch(ctx, id)
+		if err != nil {
+			slog.Error("failed to renew watch", "user_id", id, "error", err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s\texpires %s\n", id, watch.Expiration.Format(time.RFC3339))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to renew %d of %d watches", failed, len(lineUserIDs))
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+// Package db embeds the SQL migrations so the binary can apply them itself.
+package db
+
+import "embed"
+
+//go:embed migrations/*.sql postgres/migrations/*.sql sqlite/migrations/*.sql
+var Migrations embed.FS
+
+// MigrationsDir returns the directory of Migrations that holds the
+// migrations for the database driver.
+func MigrationsDir(driver string) string {
+	switch driver {
+	case "postgres":
+		return "postgres/migrations"
+	case "sqlite":
+		return "sqlite/migrations"
+	default:
+		return "migrations"
+	}
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// sharedSince is the first migration every driver has; each later one is
+// created in all trees by make migrate-new.
+const sharedSince = "20251121090000"
+
+func TestMigrationsShared(t *testing.T) {
+	versions := map[string][]string{}
+	for _, driver := range []string{"mysql", "sqlite", "postgres"} {
+		entries, err := fs.ReadDir(Migrations, MigrationsDir(driver))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			version, _, _ := strings.Cut(e.Name(), "_")
+			if version >= sharedSince {
+				versions[version] = append(versions[version], driver)
+			}
+		}
+	}
+
+	for version, drivers := range versions {
+		if len(drivers) != 3 {
+			t.Errorf("migration %s only exists for %s; create it for every driver", version, strings.Join(drivers, ", "))
+		}
+	}
+	if _, ok := versions[sharedSince]; !ok {
+		t.Errorf("migration %s is missing", sharedSince)
+	}
+}
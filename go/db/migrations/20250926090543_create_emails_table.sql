@@ -14,3 +14,4 @@ CREATE TABLE emails (
 
 -- migrate:down
 
+DROP TABLE emails;
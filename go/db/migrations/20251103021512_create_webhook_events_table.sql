@@ -0,0 +1,10 @@
+-- migrate:up
+CREATE TABLE webhook_events (
+    event_id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_webhook_events_expires_at (expires_at)
+);
+
+-- migrate:down
+DROP TABLE webhook_events;
@@ -0,0 +1,7 @@
+-- migrate:up
+
+ALTER TABLE users ADD COLUMN gmail_watch_expires_at BIGINT DEFAULT NULL AFTER gmail_history_id;
+
+-- migrate:down
+
+ALTER TABLE users DROP COLUMN gmail_watch_expires_at;
@@ -0,0 +1,9 @@
+-- migrate:up
+
+ALTER TABLE jobs ADD COLUMN trace_parent VARCHAR(55) DEFAULT NULL AFTER last_error;
+ALTER TABLE notification_outbox ADD COLUMN trace_parent VARCHAR(55) DEFAULT NULL AFTER last_error;
+
+-- migrate:down
+
+ALTER TABLE notification_outbox DROP COLUMN trace_parent;
+ALTER TABLE jobs DROP COLUMN trace_parent;
@@ -0,0 +1,7 @@
+-- migrate:up
+
+ALTER TABLE users ADD COLUMN email_retention_days INT DEFAULT NULL AFTER gmail_watch_expires_at;
+
+-- migrate:down
+
+ALTER TABLE users DROP COLUMN email_retention_days;
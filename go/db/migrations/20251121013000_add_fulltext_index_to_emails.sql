@@ -0,0 +1,7 @@
+-- migrate:up
+
+ALTER TABLE emails ADD FULLTEXT INDEX ft_emails_content (sender_email, subject, body_preview) WITH PARSER ngram;
+
+-- migrate:down
+
+ALTER TABLE emails DROP INDEX ft_emails_content;
@@ -0,0 +1,11 @@
+-- migrate:up
+
+ALTER TABLE emails
+    ADD INDEX idx_emails_user_received (user_id, received_at, id),
+    ADD INDEX idx_emails_user_notified_received (user_id, is_notified, received_at, id);
+
+-- migrate:down
+
+ALTER TABLE emails
+    DROP INDEX idx_emails_user_notified_received,
+    DROP INDEX idx_emails_user_received;
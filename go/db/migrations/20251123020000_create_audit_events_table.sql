@@ -0,0 +1,21 @@
+-- migrate:up
+
+-- subject_user_id has no foreign key, so the trail outlives erased users.
+-- The application never updates or deletes rows; revoke UPDATE and DELETE
+-- on the table from its database user to enforce that.
+CREATE TABLE audit_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    subject_user_id VARCHAR(36),
+    action VARCHAR(64) NOT NULL,
+    result VARCHAR(16) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(512),
+    detail TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_events_subject_user_id (subject_user_id, id)
+);
+
+-- migrate:down
+
+DROP TABLE audit_events;
@@ -0,0 +1,17 @@
+-- migrate:up
+
+-- Channels a user receives notifications on in addition to LINE. target is
+-- the channel specific destination, e.g. a webhook.
+CREATE TABLE notification_channels (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(32) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_notification_channels_user_channel (user_id, channel)
+);
+
+-- migrate:down
+
+DROP TABLE notification_channels;
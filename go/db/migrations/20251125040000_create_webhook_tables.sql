@@ -0,0 +1,40 @@
+-- migrate:up
+
+-- Outbound webhooks: an HTTPS endpoint per user that receives signed
+-- events. secret signs the payloads.
+CREATE TABLE webhook_endpoints (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Deliveries are the queue and the delivery log. A redelivery is a new row
+-- with the payload and event_id of the original.
+CREATE TABLE webhook_deliveries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    endpoint_id BIGINT UNSIGNED NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    response_status INT,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL,
+    redelivery_of BIGINT UNSIGNED,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_webhook_deliveries_status_next_attempt_at (status, next_attempt_at),
+    INDEX idx_webhook_deliveries_user_id (user_id, id)
+);
+
+-- migrate:down
+
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_endpoints;
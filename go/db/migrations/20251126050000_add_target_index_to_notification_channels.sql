@@ -0,0 +1,10 @@
+-- migrate:up
+
+-- Inbound messages of chat channels are mapped to their user by target.
+ALTER TABLE notification_channels
+    ADD INDEX idx_notification_channels_channel_target (channel, target);
+
+-- migrate:down
+
+ALTER TABLE notification_channels
+    DROP INDEX idx_notification_channels_channel_target;
@@ -0,0 +1,101 @@
+-- migrate:up
+CREATE TABLE users (
+    id VARCHAR(36) PRIMARY KEY,
+    line_user_id VARCHAR(255) NOT NULL UNIQUE,
+    gmail_access_token TEXT,
+    gmail_refresh_token TEXT,
+    gmail_token_expires_at BIGINT,
+    gmail_history_id BIGINT,
+    gmail_watch_expires_at BIGINT,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE emails (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gmail_message_id VARCHAR(255) NOT NULL UNIQUE,
+    sender_email VARCHAR(255) NOT NULL,
+    subject VARCHAR(500),
+    body_preview TEXT,
+    received_at TIMESTAMPTZ NOT NULL,
+    is_notified BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_events (
+    event_id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_events_expires_at ON webhook_events (expires_at);
+
+CREATE TABLE jobs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    dedup_key VARCHAR(255) UNIQUE,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMPTZ,
+    last_error TEXT,
+    trace_parent VARCHAR(55),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_status_run_at ON jobs (status, run_at);
+
+CREATE TABLE notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    retry_key CHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email_id BIGINT,
+    channel VARCHAR(32) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMPTZ,
+    trace_parent VARCHAR(55),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_outbox_status_next_attempt_at ON notification_outbox (status, next_attempt_at);
+
+-- Postgres has no ON UPDATE CURRENT_TIMESTAMP.
+CREATE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_updated_at BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER emails_updated_at BEFORE UPDATE ON emails
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER jobs_updated_at BEFORE UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER notification_outbox_updated_at BEFORE UPDATE ON notification_outbox
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- migrate:down
+DROP TABLE notification_outbox;
+DROP TABLE jobs;
+DROP TABLE webhook_events;
+DROP TABLE emails;
+DROP TABLE users;
+DROP FUNCTION set_updated_at();
@@ -0,0 +1,11 @@
+-- migrate:up
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram index for ILIKE searches; the expression must match SearchEmails.
+CREATE INDEX idx_emails_content_trgm ON emails
+    USING GIN ((sender_email || ' ' || COALESCE(subject, '') || ' ' || COALESCE(body_preview, '')) gin_trgm_ops);
+
+-- migrate:down
+
+DROP INDEX idx_emails_content_trgm;
@@ -0,0 +1,30 @@
+-- migrate:up
+
+-- subject_user_id has no foreign key, so the trail outlives erased users.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    subject_user_id VARCHAR(36),
+    action VARCHAR(64) NOT NULL,
+    result VARCHAR(16) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(512),
+    detail TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_subject_user_id ON audit_events (subject_user_id, id);
+
+CREATE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
+
+-- migrate:down
+
+DROP TABLE audit_events;
+DROP FUNCTION reject_audit_event_change();
@@ -0,0 +1,20 @@
+-- migrate:up
+
+-- Channels a user receives notifications on in addition to LINE. target is
+-- the channel specific destination, e.g. a webhook.
+CREATE TABLE notification_channels (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(32) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, channel)
+);
+
+CREATE TRIGGER notification_channels_updated_at BEFORE UPDATE ON notification_channels
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- migrate:down
+
+DROP TABLE notification_channels;
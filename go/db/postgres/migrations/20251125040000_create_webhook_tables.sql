@@ -0,0 +1,47 @@
+-- migrate:up
+
+-- Outbound webhooks: an HTTPS endpoint per user that receives signed
+-- events. secret signs the payloads.
+CREATE TABLE webhook_endpoints (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER webhook_endpoints_updated_at BEFORE UPDATE ON webhook_endpoints
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Deliveries are the queue and the delivery log. A redelivery is a new row
+-- with the payload and event_id of the original.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMPTZ,
+    redelivery_of BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_user_id ON webhook_deliveries (user_id, id);
+
+CREATE TRIGGER webhook_deliveries_updated_at BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- migrate:down
+
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_endpoints;
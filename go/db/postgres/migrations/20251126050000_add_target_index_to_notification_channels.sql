@@ -0,0 +1,8 @@
+-- migrate:up
+
+-- Inbound messages of chat channels are mapped to their user by target.
+CREATE INDEX idx_notification_channels_channel_target ON notification_channels (channel, target);
+
+-- migrate:down
+
+DROP INDEX idx_notification_channels_channel_target;
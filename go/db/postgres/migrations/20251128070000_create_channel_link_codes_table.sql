@@ -0,0 +1,17 @@
+-- migrate:up
+
+-- One-time codes that link a chat channel to a user. They are stored so a
+-- code stays valid across restarts and on every instance.
+CREATE TABLE channel_link_codes (
+    code VARCHAR(16) PRIMARY KEY,
+    channel VARCHAR(32) NOT NULL,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_channel_link_codes_expires_at ON channel_link_codes (expires_at);
+
+-- migrate:down
+
+DROP TABLE channel_link_codes;
@@ -0,0 +1,19 @@
+-- name: CreateChannelLinkCode :exec
+INSERT INTO channel_link_codes (code, channel, user_id, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetChannelLinkCode :one
+SELECT * FROM channel_link_codes
+WHERE code = $1 AND channel = $2;
+
+-- name: DeleteChannelLinkCode :execrows
+DELETE FROM channel_link_codes
+WHERE code = $1;
+
+-- name: DeleteExpiredChannelLinkCodes :exec
+DELETE FROM channel_link_codes
+WHERE expires_at < $1;
+
+-- name: DeleteChannelLinkCodesByUserID :exec
+DELETE FROM channel_link_codes
+WHERE user_id = $1;
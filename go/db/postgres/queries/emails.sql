@@ -0,0 +1,99 @@
+-- name: CreateEmail :one
+INSERT INTO emails (
+    user_id,
+    gmail_message_id,
+    sender_email,
+    subject,
+    body_preview,
+    received_at,
+    is_notified
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id;
+
+-- name: GetEmailByGmailMessageID :one
+SELECT * FROM emails
+WHERE gmail_message_id = $1
+LIMIT 1;
+
+-- name: GetEmailsByUserID :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(cursor_id)::bigint = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetUnnotifiedEmailsByUserID :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id) AND is_notified = false
+  AND (sqlc.arg(cursor_id)::bigint = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateEmailNotified :exec
+UPDATE emails
+SET is_notified = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: MarkEmailAsNotified :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE gmail_message_id = $1;
+
+-- name: DeleteEmailsByUserID :exec
+DELETE FROM emails
+WHERE user_id = $1;
+
+-- name: GetRecentEmails :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id) AND received_at >= sqlc.arg(since)
+  AND (sqlc.arg(cursor_id)::bigint = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: MarkEmailAsNotifiedByID :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteEmailsOlderThan :execrows
+DELETE FROM emails
+WHERE id IN (
+    SELECT e.id FROM emails e
+    WHERE e.user_id = $1 AND e.received_at < $2
+    ORDER BY e.received_at
+    LIMIT $3
+);
+
+-- name: ClearNotifiedEmailContent :execrows
+UPDATE emails
+SET sender_email = '',
+    subject = NULL,
+    body_preview = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT e.id FROM emails e
+    WHERE e.is_notified = true
+      AND (e.sender_email <> '' OR e.subject IS NOT NULL OR e.body_preview IS NOT NULL)
+    LIMIT $1
+);
+
+-- name: SearchEmails :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id)
+  AND (sender_email || ' ' || COALESCE(subject, '') || ' ' || COALESCE(body_preview, '')) ILIKE sqlc.arg(pattern)::text
+  AND (sqlc.arg(cursor_id)::bigint = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
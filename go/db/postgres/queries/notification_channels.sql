@@ -0,0 +1,23 @@
+-- name: ListNotificationChannelsByUserID :many
+SELECT * FROM notification_channels
+WHERE user_id = $1
+ORDER BY channel;
+
+-- name: UpsertNotificationChannel :exec
+INSERT INTO notification_channels (user_id, channel, target)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, channel) DO UPDATE SET target = EXCLUDED.target;
+
+-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels
+WHERE user_id = $1 AND channel = $2;
+
+-- name: DeleteNotificationChannelsByUserID :exec
+DELETE FROM notification_channels
+WHERE user_id = $1;
+
+-- name: GetNotificationChannelByTarget :one
+SELECT * FROM notification_channels
+WHERE channel = $1 AND target = $2
+ORDER BY id
+LIMIT 1;
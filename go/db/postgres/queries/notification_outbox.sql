@@ -0,0 +1,79 @@
+-- name: CreateOutboxEntry :execresult
+INSERT INTO notification_outbox (
+    idempotency_key,
+    retry_key,
+    user_id,
+    email_id,
+    channel,
+    recipient,
+    message,
+    trace_parent
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+-- name: GetDueOutboxEntriesForUpdate :many
+SELECT * FROM notification_outbox
+WHERE status = 'pending' AND next_attempt_at <= $1
+ORDER BY next_attempt_at, id
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: LeaseOutboxEntry :exec
+UPDATE notification_outbox
+SET attempts = attempts + 1,
+    next_attempt_at = $1
+WHERE id = $2;
+
+-- name: MarkOutboxEntrySent :exec
+UPDATE notification_outbox
+SET status = 'sent',
+    sent_at = $1,
+    last_error = NULL
+WHERE id = $2;
+
+-- name: RescheduleOutboxEntry :exec
+UPDATE notification_outbox
+SET next_attempt_at = $1,
+    last_error = $2
+WHERE id = $3;
+
+-- name: MarkOutboxEntryFailed :exec
+UPDATE notification_outbox
+SET status = 'failed',
+    last_error = $1
+WHERE id = $2;
+
+-- name: CountOutboxEntriesByStatus :many
+SELECT status, COUNT(*) AS count FROM notification_outbox
+GROUP BY status;
+
+-- name: ListRecentOutboxEntries :many
+SELECT * FROM notification_outbox
+WHERE sqlc.arg(status)::text = '' OR status = sqlc.arg(status)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteOutboxEntriesByUserID :exec
+DELETE FROM notification_outbox
+WHERE user_id = $1;
+
+-- name: DeleteFinishedOutboxEntries :execrows
+DELETE FROM notification_outbox
+WHERE id IN (
+    SELECT o.id FROM notification_outbox o
+    WHERE o.user_id = $1 AND o.status IN ('sent', 'failed') AND o.created_at < $2
+    ORDER BY o.created_at
+    LIMIT $3
+);
+
+-- name: ClearSentOutboxMessages :execrows
+UPDATE notification_outbox
+SET message = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT o.id FROM notification_outbox o
+    WHERE o.status = 'sent' AND o.message <> ''
+    LIMIT $1
+);
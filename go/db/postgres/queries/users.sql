@@ -0,0 +1,107 @@
+-- name: CreateUser :execresult
+INSERT INTO users (
+    id,
+    line_user_id,
+    mail_access_token,
+    mail_refresh_token,
+    mail_token_expires_at,
+    is_active
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: GetUserByLineUserID :one
+SELECT * FROM users
+WHERE line_user_id = $1 AND is_active = true
+LIMIT 1;
+
+-- name: UpdateUserMailTokens :exec
+UPDATE users
+SET mail_provider = $1,
+    mail_access_token = $2,
+    mail_refresh_token = $3,
+    mail_token_expires_at = $4,
+    mail_watch_id = NULL,
+    mail_sync_cursor = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = $5;
+
+-- name: UpdateUserRefreshedMailTokens :exec
+UPDATE users
+SET mail_access_token = $1,
+    mail_refresh_token = $2,
+    mail_token_expires_at = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $4 AND mail_provider = $5;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1 AND is_active = true
+LIMIT 1;
+
+-- name: GetAllActiveUsers :many
+SELECT * FROM users
+WHERE is_active = true;
+
+-- name: UpdateUserMailWatch :exec
+UPDATE users
+SET gmail_history_id = $1,
+    mail_watch_expires_at = $2,
+    mail_watch_id = $3,
+    mail_sync_cursor = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = $5;
+
+-- name: UpdateUserMailSyncCursor :exec
+UPDATE users
+SET mail_sync_cursor = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: GetUserByMailWatchID :one
+SELECT * FROM users
+WHERE mail_watch_id = $1 AND is_active = true
+LIMIT 1;
+
+-- name: CountUsersWithWatchExpiringBetween :one
+SELECT COUNT(*) FROM users
+WHERE is_active = true
+  AND mail_watch_expires_at >= sqlc.arg(from_unix)
+  AND mail_watch_expires_at < sqlc.arg(to_unix);
+
+-- name: GetUsersWithWatchExpiringBefore :many
+SELECT * FROM users
+WHERE is_active = true
+  AND mail_access_token IS NOT NULL AND mail_access_token <> ''
+  AND (mail_watch_expires_at IS NULL OR mail_watch_expires_at < sqlc.arg(before_unix))
+ORDER BY id;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE sqlc.arg(query)::text = ''
+   OR id = sqlc.arg(query)
+   OR line_user_id LIKE sqlc.arg(pattern)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: DeactivateUser :execrows
+UPDATE users
+SET is_active = false,
+    mail_access_token = NULL,
+    mail_refresh_token = NULL,
+    mail_token_expires_at = NULL,
+    mail_watch_expires_at = NULL,
+    mail_watch_id = NULL,
+    mail_sync_cursor = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UpdateUserEmailRetention :exec
+UPDATE users
+SET email_retention_days = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = $2;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
@@ -0,0 +1,14 @@
+-- name: CreateWebhookEvent :execresult
+INSERT INTO webhook_events (
+    event_id,
+    expires_at
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (event_id) DO UPDATE
+SET expires_at = excluded.expires_at
+WHERE webhook_events.expires_at < CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredWebhookEvents :exec
+DELETE FROM webhook_events
+WHERE expires_at < $1;
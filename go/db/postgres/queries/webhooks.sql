@@ -0,0 +1,107 @@
+-- name: GetWebhookEndpointByUserID :one
+SELECT * FROM webhook_endpoints
+WHERE user_id = $1;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: UpsertWebhookEndpoint :exec
+INSERT INTO webhook_endpoints (
+    user_id,
+    url,
+    secret,
+    created_by
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    url = EXCLUDED.url,
+    secret = EXCLUDED.secret,
+    created_by = EXCLUDED.created_by;
+
+-- name: DeleteWebhookEndpointByUserID :execrows
+DELETE FROM webhook_endpoints
+WHERE user_id = $1;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    endpoint_id,
+    user_id,
+    event_id,
+    event_type,
+    payload,
+    redelivery_of
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING id;
+
+-- name: GetDueWebhookDeliveriesForUpdate :many
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= $1
+ORDER BY next_attempt_at, id
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: LeaseWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = $1
+WHERE id = $2;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    response_status = $1,
+    delivered_at = $2,
+    last_error = NULL
+WHERE id = $3;
+
+-- name: RescheduleWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET next_attempt_at = $1,
+    response_status = $2,
+    last_error = $3
+WHERE id = $4;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = 'failed',
+    response_status = $1,
+    last_error = $2
+WHERE id = $3;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListWebhookDeliveriesByUserID :many
+SELECT * FROM webhook_deliveries
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(before_id)::bigint = 0 OR id < sqlc.arg(before_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteWebhookDeliveriesByUserID :exec
+DELETE FROM webhook_deliveries
+WHERE user_id = $1;
+
+-- name: DeleteFinishedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.user_id = $1 AND d.status IN ('delivered', 'failed') AND d.created_at < $2
+    ORDER BY d.created_at
+    LIMIT $3
+);
+
+-- name: ClearDeliveredWebhookPayloads :execrows
+UPDATE webhook_deliveries
+SET payload = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.status = 'delivered' AND d.payload <> ''
+    LIMIT $1
+);
@@ -0,0 +1,25 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    actor,
+    subject_user_id,
+    action,
+    result,
+    ip_address,
+    user_agent,
+    detail
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListAuditEventsBySubject :many
+SELECT * FROM audit_events
+WHERE subject_user_id = sqlc.arg(subject_user_id)
+  AND (sqlc.arg(before_id) = 0 OR id < sqlc.arg(before_id))
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: ClearAuditEventClientInfo :exec
+UPDATE audit_events
+SET ip_address = NULL,
+    user_agent = NULL
+WHERE subject_user_id = ?;
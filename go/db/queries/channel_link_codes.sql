@@ -0,0 +1,19 @@
+-- name: CreateChannelLinkCode :exec
+INSERT INTO channel_link_codes (code, channel, user_id, expires_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetChannelLinkCode :one
+SELECT * FROM channel_link_codes
+WHERE code = ? AND channel = ?;
+
+-- name: DeleteChannelLinkCode :execrows
+DELETE FROM channel_link_codes
+WHERE code = ?;
+
+-- name: DeleteExpiredChannelLinkCodes :exec
+DELETE FROM channel_link_codes
+WHERE expires_at < ?;
+
+-- name: DeleteChannelLinkCodesByUserID :exec
+DELETE FROM channel_link_codes
+WHERE user_id = ?;
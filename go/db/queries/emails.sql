@@ -18,13 +18,21 @@ LIMIT 1;
 
 -- name: GetEmailsByUserID :many
 SELECT * FROM emails
-WHERE user_id = ?
-ORDER BY received_at DESC;
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(cursor_id) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT ?;
 
 -- name: GetUnnotifiedEmailsByUserID :many
 SELECT * FROM emails
-WHERE user_id = ? AND is_notified = false
-ORDER BY received_at DESC;
+WHERE user_id = sqlc.arg(user_id) AND is_notified = false
+  AND (sqlc.arg(cursor_id) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT ?;
 
 -- name: UpdateEmailNotified :exec
 UPDATE emails
@@ -44,5 +52,41 @@ WHERE user_id = ?;
 
 -- name: GetRecentEmails :many
 SELECT * FROM emails
-WHERE user_id = ? AND received_at >= ?
-ORDER BY received_at DESC;
+WHERE user_id = sqlc.arg(user_id) AND received_at >= sqlc.arg(since)
+  AND (sqlc.arg(cursor_id) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT ?;
+
+-- name: MarkEmailAsNotifiedByID :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteEmailsOlderThan :execrows
+DELETE FROM emails
+WHERE user_id = ? AND received_at < ?
+ORDER BY received_at
+LIMIT ?;
+
+-- name: ClearNotifiedEmailContent :execrows
+UPDATE emails
+SET sender_email = '',
+    subject = NULL,
+    body_preview = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE is_notified = true
+  AND (sender_email <> '' OR subject IS NOT NULL OR body_preview IS NOT NULL)
+LIMIT ?;
+
+-- name: SearchEmails :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id)
+  AND MATCH (sender_email, subject, body_preview) AGAINST (sqlc.arg(query) IN BOOLEAN MODE)
+  AND (sqlc.arg(cursor_id) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT ?;
@@ -0,0 +1,23 @@
+-- name: ListNotificationChannelsByUserID :many
+SELECT * FROM notification_channels
+WHERE user_id = ?
+ORDER BY channel;
+
+-- name: UpsertNotificationChannel :exec
+INSERT INTO notification_channels (user_id, channel, target)
+VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE target = VALUES(target);
+
+-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels
+WHERE user_id = ? AND channel = ?;
+
+-- name: DeleteNotificationChannelsByUserID :exec
+DELETE FROM notification_channels
+WHERE user_id = ?;
+
+-- name: GetNotificationChannelByTarget :one
+SELECT * FROM notification_channels
+WHERE channel = ? AND target = ?
+ORDER BY id
+LIMIT 1;
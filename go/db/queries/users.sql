@@ -2,9 +2,9 @@
 INSERT INTO users (
     id,
     line_user_id,
-    gmail_access_token,
-    gmail_refresh_token,
-    gmail_token_expires_at,
+    mail_access_token,
+    mail_refresh_token,
+    mail_token_expires_at,
     is_active
 ) VALUES (
     ?, ?, ?, ?, ?, ?
@@ -15,14 +15,25 @@ SELECT * FROM users
 WHERE line_user_id = ? AND is_active = true
 LIMIT 1;
 
--- name: UpdateUserGmailTokens :exec
+-- name: UpdateUserMailTokens :exec
 UPDATE users
-SET gmail_access_token = ?,
-    gmail_refresh_token = ?,
-    gmail_token_expires_at = ?,
+SET mail_provider = ?,
+    mail_access_token = ?,
+    mail_refresh_token = ?,
+    mail_token_expires_at = ?,
+    mail_watch_id = NULL,
+    mail_sync_cursor = NULL,
     updated_at = CURRENT_TIMESTAMP
 WHERE line_user_id = ?;
 
+-- name: UpdateUserRefreshedMailTokens :exec
+UPDATE users
+SET mail_access_token = ?,
+    mail_refresh_token = ?,
+    mail_token_expires_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND mail_provider = ?;
+
 -- name: GetUserByID :one
 SELECT * FROM users
 WHERE id = ? AND is_active = true
@@ -31,3 +42,66 @@ LIMIT 1;
 -- name: GetAllActiveUsers :many
 SELECT * FROM users
 WHERE is_active = true;
+
+-- name: UpdateUserMailWatch :exec
+UPDATE users
+SET gmail_history_id = ?,
+    mail_watch_expires_at = ?,
+    mail_watch_id = ?,
+    mail_sync_cursor = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = ?;
+
+-- name: UpdateUserMailSyncCursor :exec
+UPDATE users
+SET mail_sync_cursor = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetUserByMailWatchID :one
+SELECT * FROM users
+WHERE mail_watch_id = ? AND is_active = true
+LIMIT 1;
+
+-- name: CountUsersWithWatchExpiringBetween :one
+SELECT COUNT(*) FROM users
+WHERE is_active = true
+  AND mail_watch_expires_at >= sqlc.arg(from_unix)
+  AND mail_watch_expires_at < sqlc.arg(to_unix);
+
+-- name: GetUsersWithWatchExpiringBefore :many
+SELECT * FROM users
+WHERE is_active = true
+  AND mail_access_token IS NOT NULL AND mail_access_token <> ''
+  AND (mail_watch_expires_at IS NULL OR mail_watch_expires_at < sqlc.arg(before_unix))
+ORDER BY id;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE sqlc.arg(query) = ''
+   OR id = sqlc.arg(query)
+   OR line_user_id LIKE sqlc.arg(pattern)
+ORDER BY created_at DESC, id
+LIMIT ? OFFSET ?;
+
+-- name: DeactivateUser :execrows
+UPDATE users
+SET is_active = false,
+    mail_access_token = NULL,
+    mail_refresh_token = NULL,
+    mail_token_expires_at = NULL,
+    mail_watch_expires_at = NULL,
+    mail_watch_id = NULL,
+    mail_sync_cursor = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateUserEmailRetention :exec
+UPDATE users
+SET email_retention_days = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = ?;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = ?;
@@ -0,0 +1,13 @@
+-- name: CreateWebhookEvent :execresult
+INSERT INTO webhook_events (
+    event_id,
+    expires_at
+) VALUES (
+    ?, ?
+)
+ON DUPLICATE KEY UPDATE
+    expires_at = IF(expires_at < CURRENT_TIMESTAMP, VALUES(expires_at), expires_at);
+
+-- name: DeleteExpiredWebhookEvents :exec
+DELETE FROM webhook_events
+WHERE expires_at < ?;
@@ -0,0 +1,100 @@
+-- name: GetWebhookEndpointByUserID :one
+SELECT * FROM webhook_endpoints
+WHERE user_id = ?;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = ?;
+
+-- name: UpsertWebhookEndpoint :exec
+INSERT INTO webhook_endpoints (
+    user_id,
+    url,
+    secret,
+    created_by
+) VALUES (
+    ?, ?, ?, ?
+)
+ON DUPLICATE KEY UPDATE
+    url = VALUES(url),
+    secret = VALUES(secret),
+    created_by = VALUES(created_by);
+
+-- name: DeleteWebhookEndpointByUserID :execrows
+DELETE FROM webhook_endpoints
+WHERE user_id = ?;
+
+-- name: CreateWebhookDelivery :execresult
+INSERT INTO webhook_deliveries (
+    endpoint_id,
+    user_id,
+    event_id,
+    event_type,
+    payload,
+    redelivery_of
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetDueWebhookDeliveriesForUpdate :many
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at, id
+LIMIT ?
+FOR UPDATE SKIP LOCKED;
+
+-- name: LeaseWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = ?
+WHERE id = ?;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    response_status = ?,
+    delivered_at = ?,
+    last_error = NULL
+WHERE id = ?;
+
+-- name: RescheduleWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET next_attempt_at = ?,
+    response_status = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = 'failed',
+    response_status = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = ? AND user_id = ?;
+
+-- name: ListWebhookDeliveriesByUserID :many
+SELECT * FROM webhook_deliveries
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(before_id) = 0 OR id < sqlc.arg(before_id))
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: DeleteWebhookDeliveriesByUserID :exec
+DELETE FROM webhook_deliveries
+WHERE user_id = ?;
+
+-- name: DeleteFinishedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE user_id = ? AND status IN ('delivered', 'failed') AND created_at < ?
+ORDER BY created_at
+LIMIT ?;
+
+-- name: ClearDeliveredWebhookPayloads :execrows
+UPDATE webhook_deliveries
+SET payload = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'delivered' AND payload <> ''
+LIMIT ?;
@@ -0,0 +1,106 @@
+-- migrate:up
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    line_user_id TEXT NOT NULL UNIQUE,
+    gmail_access_token TEXT,
+    gmail_refresh_token TEXT,
+    gmail_token_expires_at INTEGER,
+    gmail_history_id INTEGER,
+    gmail_watch_expires_at INTEGER,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE emails (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gmail_message_id TEXT NOT NULL UNIQUE,
+    sender_email TEXT NOT NULL,
+    subject TEXT,
+    body_preview TEXT,
+    received_at TIMESTAMP NOT NULL,
+    is_notified BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_events (
+    event_id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_events_expires_at ON webhook_events (expires_at);
+
+CREATE TABLE jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    dedup_key TEXT UNIQUE,
+    payload BLOB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP,
+    last_error TEXT,
+    trace_parent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_status_run_at ON jobs (status, run_at);
+
+CREATE TABLE notification_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    idempotency_key TEXT NOT NULL UNIQUE,
+    retry_key TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email_id INTEGER,
+    channel TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    message TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    trace_parent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_outbox_status_next_attempt_at ON notification_outbox (status, next_attempt_at);
+
+-- SQLite has no ON UPDATE CURRENT_TIMESTAMP. The triggers only fire when a
+-- statement left updated_at unchanged, so they do not recurse.
+CREATE TRIGGER users_updated_at AFTER UPDATE ON users
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER emails_updated_at AFTER UPDATE ON emails
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE emails SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER jobs_updated_at AFTER UPDATE ON jobs
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE jobs SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER notification_outbox_updated_at AFTER UPDATE ON notification_outbox
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE notification_outbox SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- migrate:down
+DROP TABLE notification_outbox;
+DROP TABLE jobs;
+DROP TABLE webhook_events;
+DROP TABLE emails;
+DROP TABLE users;
@@ -0,0 +1,7 @@
+-- migrate:up
+
+ALTER TABLE users ADD COLUMN email_retention_days INTEGER;
+
+-- migrate:down
+
+ALTER TABLE users DROP COLUMN email_retention_days;
@@ -0,0 +1,9 @@
+-- migrate:up
+
+CREATE INDEX idx_emails_user_received ON emails (user_id, received_at, id);
+CREATE INDEX idx_emails_user_notified_received ON emails (user_id, is_notified, received_at, id);
+
+-- migrate:down
+
+DROP INDEX idx_emails_user_notified_received;
+DROP INDEX idx_emails_user_received;
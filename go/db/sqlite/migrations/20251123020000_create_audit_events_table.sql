@@ -0,0 +1,30 @@
+-- migrate:up
+
+-- subject_user_id has no foreign key, so the trail outlives erased users.
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL,
+    subject_user_id TEXT,
+    action TEXT NOT NULL,
+    result TEXT NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    detail TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_subject_user_id ON audit_events (subject_user_id, id);
+
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+-- migrate:down
+
+DROP TABLE audit_events;
@@ -0,0 +1,23 @@
+-- migrate:up
+
+-- Channels a user receives notifications on in addition to LINE. target is
+-- the channel specific destination, e.g. a webhook.
+CREATE TABLE notification_channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    target TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, channel)
+);
+
+CREATE TRIGGER notification_channels_updated_at AFTER UPDATE ON notification_channels
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE notification_channels SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- migrate:down
+
+DROP TABLE notification_channels;
@@ -0,0 +1,53 @@
+-- migrate:up
+
+-- Outbound webhooks: an HTTPS endpoint per user that receives signed
+-- events. secret signs the payloads.
+CREATE TABLE webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER webhook_endpoints_updated_at AFTER UPDATE ON webhook_endpoints
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE webhook_endpoints SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Deliveries are the queue and the delivery log. A redelivery is a new row
+-- with the payload and event_id of the original.
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+    redelivery_of INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_user_id ON webhook_deliveries (user_id, id);
+
+CREATE TRIGGER webhook_deliveries_updated_at AFTER UPDATE ON webhook_deliveries
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE webhook_deliveries SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- migrate:down
+
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_endpoints;
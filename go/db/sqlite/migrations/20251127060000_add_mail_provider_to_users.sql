@@ -0,0 +1,27 @@
+-- migrate:up
+
+-- The token and watch columns serve whichever provider is linked.
+ALTER TABLE users RENAME COLUMN gmail_access_token TO mail_access_token;
+ALTER TABLE users RENAME COLUMN gmail_refresh_token TO mail_refresh_token;
+ALTER TABLE users RENAME COLUMN gmail_token_expires_at TO mail_token_expires_at;
+ALTER TABLE users RENAME COLUMN gmail_watch_expires_at TO mail_watch_expires_at;
+
+ALTER TABLE users ADD COLUMN mail_provider TEXT NOT NULL DEFAULT 'gmail';
+ALTER TABLE users ADD COLUMN mail_watch_id TEXT;
+ALTER TABLE users ADD COLUMN mail_sync_cursor TEXT;
+
+-- Outlook change notifications are mapped to their user by subscription.
+CREATE INDEX idx_users_mail_watch_id ON users (mail_watch_id);
+
+-- migrate:down
+
+DROP INDEX idx_users_mail_watch_id;
+
+ALTER TABLE users DROP COLUMN mail_sync_cursor;
+ALTER TABLE users DROP COLUMN mail_watch_id;
+ALTER TABLE users DROP COLUMN mail_provider;
+
+ALTER TABLE users RENAME COLUMN mail_watch_expires_at TO gmail_watch_expires_at;
+ALTER TABLE users RENAME COLUMN mail_token_expires_at TO gmail_token_expires_at;
+ALTER TABLE users RENAME COLUMN mail_refresh_token TO gmail_refresh_token;
+ALTER TABLE users RENAME COLUMN mail_access_token TO gmail_access_token;
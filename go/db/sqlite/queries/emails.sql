@@ -0,0 +1,98 @@
+-- name: CreateEmail :execresult
+INSERT INTO emails (
+    user_id,
+    gmail_message_id,
+    sender_email,
+    subject,
+    body_preview,
+    received_at,
+    is_notified
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetEmailByGmailMessageID :one
+SELECT * FROM emails
+WHERE gmail_message_id = ?
+LIMIT 1;
+
+-- name: GetEmailsByUserID :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id)
+  AND (CAST(sqlc.arg(cursor_id) AS INTEGER) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetUnnotifiedEmailsByUserID :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id) AND is_notified = false
+  AND (CAST(sqlc.arg(cursor_id) AS INTEGER) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateEmailNotified :exec
+UPDATE emails
+SET is_notified = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: MarkEmailAsNotified :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE gmail_message_id = ?;
+
+-- name: DeleteEmailsByUserID :exec
+DELETE FROM emails
+WHERE user_id = ?;
+
+-- name: GetRecentEmails :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id) AND received_at >= sqlc.arg(since)
+  AND (CAST(sqlc.arg(cursor_id) AS INTEGER) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: MarkEmailAsNotifiedByID :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteEmailsOlderThan :execrows
+DELETE FROM emails
+WHERE id IN (
+    SELECT e.id FROM emails e
+    WHERE e.user_id = ? AND e.received_at < ?
+    ORDER BY e.received_at
+    LIMIT ?
+);
+
+-- name: ClearNotifiedEmailContent :execrows
+UPDATE emails
+SET sender_email = '',
+    subject = NULL,
+    body_preview = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT e.id FROM emails e
+    WHERE e.is_notified = true
+      AND (e.sender_email <> '' OR e.subject IS NOT NULL OR e.body_preview IS NOT NULL)
+    LIMIT ?
+);
+
+-- name: SearchEmails :many
+SELECT * FROM emails
+WHERE user_id = sqlc.arg(user_id)
+  AND instr(lower(sender_email || ' ' || COALESCE(subject, '') || ' ' || COALESCE(body_preview, '')), lower(CAST(sqlc.arg(query) AS TEXT))) > 0
+  AND (CAST(sqlc.arg(cursor_id) AS INTEGER) = 0
+       OR received_at < sqlc.arg(cursor_received_at)
+       OR (received_at = sqlc.arg(cursor_received_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
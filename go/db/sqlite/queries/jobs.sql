@@ -0,0 +1,110 @@
+-- name: CreateJob :execresult
+INSERT OR IGNORE INTO jobs (
+    type,
+    dedup_key,
+    payload,
+    max_attempts,
+    run_at,
+    trace_parent
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetNextPendingJob :one
+SELECT * FROM jobs
+WHERE status = 'pending' AND run_at <= ?
+ORDER BY run_at, id
+LIMIT 1;
+
+-- name: MarkJobRunning :exec
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = ?
+WHERE id = ?;
+
+-- name: MarkJobDone :exec
+UPDATE jobs
+SET status = 'done',
+    locked_at = NULL
+WHERE id = ?;
+
+-- name: RescheduleJob :exec
+UPDATE jobs
+SET status = 'pending',
+    locked_at = NULL,
+    run_at = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: MarkJobDead :exec
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = ?
+WHERE id = ?;
+
+-- name: RequeueStaleJobs :execresult
+UPDATE jobs
+SET status = 'pending',
+    locked_at = NULL,
+    last_error = 'worker lost while running the job'
+WHERE status = 'running' AND locked_at < ? AND attempts < max_attempts;
+
+-- name: DeadLetterStaleJobs :execresult
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = 'worker lost while running the job'
+WHERE status = 'running' AND locked_at < ? AND attempts >= max_attempts;
+
+-- name: CountJobsByStatus :many
+SELECT status, COUNT(*) AS count FROM jobs
+GROUP BY status;
+
+-- name: GetOldestPendingJobRunAt :one
+SELECT run_at FROM jobs
+WHERE status = 'pending'
+ORDER BY run_at
+LIMIT 1;
+
+-- name: ListJobsByStatus :many
+SELECT * FROM jobs
+WHERE status = ?
+ORDER BY updated_at DESC, id DESC
+LIMIT ?;
+
+-- name: ReplayDeadJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    run_at = CURRENT_TIMESTAMP,
+    locked_at = NULL,
+    last_error = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'dead';
+
+-- name: DeleteJobsByUserID :exec
+-- The jobs of a user name their ID, LINE user ID or mail watch in the
+-- dedup key or payload.
+DELETE FROM jobs
+WHERE EXISTS (
+    SELECT 1 FROM users u
+    WHERE u.id = ? AND (
+        jobs.dedup_key LIKE '%' || u.id || '%'
+        OR jobs.dedup_key LIKE '%' || u.line_user_id || '%'
+        OR jobs.dedup_key LIKE '%' || u.mail_watch_id || '%'
+        OR CAST(jobs.payload AS TEXT) LIKE '%' || u.id || '%'
+        OR CAST(jobs.payload AS TEXT) LIKE '%' || u.line_user_id || '%'
+        OR CAST(jobs.payload AS TEXT) LIKE '%' || u.mail_watch_id || '%'
+    )
+);
+
+-- name: DeleteDoneJobsBefore :execrows
+DELETE FROM jobs
+WHERE id IN (
+    SELECT j.id FROM jobs j
+    WHERE j.status = 'done' AND j.updated_at < ?
+    ORDER BY j.updated_at
+    LIMIT ?
+);
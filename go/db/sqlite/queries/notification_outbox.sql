@@ -0,0 +1,77 @@
+-- name: CreateOutboxEntry :execresult
+INSERT OR IGNORE INTO notification_outbox (
+    idempotency_key,
+    retry_key,
+    user_id,
+    email_id,
+    channel,
+    recipient,
+    message,
+    trace_parent
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetDueOutboxEntries :many
+SELECT * FROM notification_outbox
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at, id
+LIMIT ?;
+
+-- name: LeaseOutboxEntry :exec
+UPDATE notification_outbox
+SET attempts = attempts + 1,
+    next_attempt_at = ?
+WHERE id = ?;
+
+-- name: MarkOutboxEntrySent :exec
+UPDATE notification_outbox
+SET status = 'sent',
+    sent_at = ?,
+    last_error = NULL
+WHERE id = ?;
+
+-- name: RescheduleOutboxEntry :exec
+UPDATE notification_outbox
+SET next_attempt_at = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: MarkOutboxEntryFailed :exec
+UPDATE notification_outbox
+SET status = 'failed',
+    last_error = ?
+WHERE id = ?;
+
+-- name: CountOutboxEntriesByStatus :many
+SELECT status, COUNT(*) AS count FROM notification_outbox
+GROUP BY status;
+
+-- name: ListRecentOutboxEntries :many
+SELECT * FROM notification_outbox
+WHERE CAST(sqlc.arg(status) AS TEXT) = '' OR status = sqlc.arg(status)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: DeleteOutboxEntriesByUserID :exec
+DELETE FROM notification_outbox
+WHERE user_id = ?;
+
+-- name: DeleteFinishedOutboxEntries :execrows
+DELETE FROM notification_outbox
+WHERE id IN (
+    SELECT o.id FROM notification_outbox o
+    WHERE o.user_id = ? AND o.status IN ('sent', 'failed') AND o.created_at < ?
+    ORDER BY o.created_at
+    LIMIT ?
+);
+
+-- name: ClearSentOutboxMessages :execrows
+UPDATE notification_outbox
+SET message = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT o.id FROM notification_outbox o
+    WHERE o.status = 'sent' AND o.message <> ''
+    LIMIT ?
+);
@@ -0,0 +1,105 @@
+-- name: GetWebhookEndpointByUserID :one
+SELECT * FROM webhook_endpoints
+WHERE user_id = ?;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = ?;
+
+-- name: UpsertWebhookEndpoint :exec
+INSERT INTO webhook_endpoints (
+    user_id,
+    url,
+    secret,
+    created_by
+) VALUES (
+    ?, ?, ?, ?
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    url = excluded.url,
+    secret = excluded.secret,
+    created_by = excluded.created_by;
+
+-- name: DeleteWebhookEndpointByUserID :execrows
+DELETE FROM webhook_endpoints
+WHERE user_id = ?;
+
+-- name: CreateWebhookDelivery :execresult
+INSERT INTO webhook_deliveries (
+    endpoint_id,
+    user_id,
+    event_id,
+    event_type,
+    payload,
+    redelivery_of
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetDueWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at, id
+LIMIT ?;
+
+-- name: LeaseWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = ?
+WHERE id = ?;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    response_status = ?,
+    delivered_at = ?,
+    last_error = NULL
+WHERE id = ?;
+
+-- name: RescheduleWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET next_attempt_at = ?,
+    response_status = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = 'failed',
+    response_status = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = ? AND user_id = ?;
+
+-- name: ListWebhookDeliveriesByUserID :many
+SELECT * FROM webhook_deliveries
+WHERE user_id = sqlc.arg(user_id)
+  AND (CAST(sqlc.arg(before_id) AS INTEGER) = 0 OR id < sqlc.arg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: DeleteWebhookDeliveriesByUserID :exec
+DELETE FROM webhook_deliveries
+WHERE user_id = ?;
+
+-- name: DeleteFinishedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.user_id = ? AND d.status IN ('delivered', 'failed') AND d.created_at < ?
+    ORDER BY d.created_at
+    LIMIT ?
+);
+
+-- name: ClearDeliveredWebhookPayloads :execrows
+UPDATE webhook_deliveries
+SET payload = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.status = 'delivered' AND d.payload <> ''
+    LIMIT ?
+);
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NoArgs rejects any argument.
+func NoArgs(args []string) (any, error) {
+	if len(args) > 0 {
+		return nil, ErrUsage
+	}
+	return nil, nil
+}
+
+// OptionalInt parses zero or one integer argument within [minValue, maxValue].
+func OptionalInt(defaultValue, minValue, maxValue int) ArgParser {
+	return func(args []string) (any, error) {
+		if len(args) == 0 {
+			return defaultValue, nil
+		}
+		if len(args) > 1 {
+			return nil, ErrUsage
+		}
+
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < minValue || n > maxValue {
+			return nil, ErrUsage
+		}
+		return n, nil
+	}
+}
+
+// Text joins the arguments with single spaces and requires the result to be
+// between minLen and maxLen characters long.
+func Text(minLen, maxLen int) ArgParser {
+	return func(args []string) (any, error) {
+		text := strings.Join(args, " ")
+		if n := utf8.RuneCountInString(text); n < minLen || n > maxLen {
+			return nil, ErrUsage
+		}
+		return text, nil
+	}
+}
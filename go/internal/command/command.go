@@ -0,0 +1,244 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+)
+
+const (
+	msgUnknownCommand = "「%s」というコマンドはありません。"
+	msgDidYouMean     = "もしかして「%s」ですか？"
+	msgHelpHint       = "「%s」で使えるコマンドを確認できます。"
+	msgUsage          = "使い方: %s"
+	titleHelp         = "📖 使えるコマンド"
+)
+
+// ErrUsage is returned by argument parsers when the arguments are invalid.
+var ErrUsage = errors.New("invalid arguments")
+
+type Request struct {
+	UserID     string
+	Name       string
+	RawArgs    []string
+	Args       any
+	ReplyToken lineRepo.ReplyToken
+}
+
+type HandlerFunc func(ctx context.Context, req *Request) error
+
+type Middleware func(next HandlerFunc) HandlerFunc
+
+type ArgParser func(args []string) (any, error)
+
+type Command struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+	ParseArgs   ArgParser
+	Handler     HandlerFunc
+	Middleware  []Middleware
+	// Hidden commands are dispatched but left out of the help output.
+	Hidden bool
+}
+
+type Responder interface {
+	Respond(ctx context.Context, userID string, replyToken lineRepo.ReplyToken, messages ...lineRepo.Message) error
+}
+
+type Router struct {
+	responder Responder
+	helpName  string
+	commands  []*Command
+	index     map[string]*Command
+}
+
+func NewRouter(responder Responder, helpName string, helpAliases ...string) *Router {
+	r := &Router{
+		responder: responder,
+		helpName:  helpName,
+		index:     make(map[string]*Command),
+	}
+
+	r.Register(&Command{
+		Name:        helpName,
+		Aliases:     helpAliases,
+		Description: "コマンドの一覧を表示します",
+		Handler:     r.handleHelp,
+	})
+
+	return r
+}
+
+func (r *Router) Register(cmd *Command) {
+	r.commands = append(r.commands, cmd)
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		key := normalize(name)
+		if _, exists := r.index[key]; exists {
+			panic(fmt.Sprintf("command: duplicate command name %q", name))
+		}
+		r.index[key] = cmd
+	}
+}
+
+func (r *Router) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.index[normalize(name)]
+	return cmd, ok
+}
+
+// Dispatch runs the command named by the first word of text. Unknown commands
+// are answered with a suggestion instead of being ignored.
+func (r *Router) Dispatch(ctx context.Context, userID, text string, replyToken lineRepo.ReplyToken) error {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	cmd, ok := r.Lookup(fields[0])
+	if !ok {
+		return r.responder.Respond(ctx, userID, replyToken, r.unknownMessage(fields[0]))
+	}
+
+	req := &Request{
+		UserID:     userID,
+		Name:       cmd.Name,
+		RawArgs:    fields[1:],
+		ReplyToken: replyToken,
+	}
+
+	if cmd.ParseArgs != nil {
+		args, err := cmd.ParseArgs(req.RawArgs)
+		if err != nil {
+			return r.responder.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgUsage, cmd.usage())))
+		}
+		req.Args = args
+	}
+
+	handler := cmd.Handler
+	for i := len(cmd.Middleware) - 1; i >= 0; i-- {
+		handler = cmd.Middleware[i](handler)
+	}
+
+	return handler(ctx, req)
+}
+
+func (r *Router) Help() string {
+	var b strings.Builder
+	b.WriteString(titleHelp)
+	b.WriteString("\n")
+
+	for _, cmd := range r.commands {
+		if cmd.Hidden {
+			continue
+		}
+		b.WriteString("\n• ")
+		b.WriteString(cmd.usage())
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(cmd.Aliases, ", "))
+		}
+		if cmd.Description != "" {
+			b.WriteString("\n  ")
+			b.WriteString(cmd.Description)
+		}
+	}
+
+	return b.String()
+}
+
+// Suggest returns the command name closest to input, or an empty string when
+// nothing is close enough to be a plausible typo.
+func (r *Router) Suggest(input string) string {
+	input = normalize(input)
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+
+	for key, cmd := range r.index {
+		if cmd.Hidden {
+			continue
+		}
+		d := levenshtein(input, key)
+		if d <= maxTypoDistance(key) {
+			candidates = append(candidates, candidate{name: cmd.Name, distance: d})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	return candidates[0].name
+}
+
+func (r *Router) handleHelp(ctx context.Context, req *Request) error {
+	return r.responder.Respond(ctx, req.UserID, req.ReplyToken, lineRepo.NewTextMessage(r.Help()))
+}
+
+func (r *Router) unknownMessage(input string) lineRepo.Message {
+	text := fmt.Sprintf(msgUnknownCommand, input)
+
+	if suggestion := r.Suggest(input); suggestion != "" {
+		return lineRepo.NewTextMessage(text+"\n"+fmt.Sprintf(msgDidYouMean, suggestion)).
+			WithQuickReply(lineRepo.MessageAction(suggestion, suggestion), lineRepo.MessageAction(r.helpName, r.helpName))
+	}
+
+	return lineRepo.NewTextMessage(text + "\n" + fmt.Sprintf(msgHelpHint, r.helpName)).
+		WithQuickReply(lineRepo.MessageAction(r.helpName, r.helpName))
+}
+
+func (c *Command) usage() string {
+	if c.Usage != "" {
+		return c.Usage
+	}
+	return c.Name
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func maxTypoDistance(name string) int {
+	n := len([]rune(name))
+	if n <= 3 {
+		return 1
+	}
+	return n / 3
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,688 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source describes where the effective value of a setting came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+const configFileEnv = "FLUX_CONFIG"
+
+// Database drivers.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+type Config struct {
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Line      LineConfig
+	Gmail     GmailConfig
+	Mail      MailConfig
+	Queue     QueueConfig
+	Tracing   TracingConfig
+	Admin     AdminConfig
+	Retention RetentionConfig
+	Export    ExportConfig
+	Slack     SlackConfig
+	Discord   DiscordConfig
+	Webhooks  WebhooksConfig
+	Telegram  TelegramConfig
+	Outlook   OutlookConfig
+	Watch     WatchConfig
+
+	// PrintConfig asks the caller to print the effective config and exit.
+	PrintConfig bool
+
+	settings []*setting
+}
+
+type ServerConfig struct {
+	Port            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	// TLSCertFile and TLSKeyFile switch the server to HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+	// PublicURL is the externally reachable base URL, used for links
+	// sent to users.
+	PublicURL string
+}
+
+type DatabaseConfig struct {
+	// Driver selects the storage backend: DriverMySQL, DriverPostgres or
+	// DriverSQLite.
+	Driver string
+	// Path is the SQLite database file; ":memory:" keeps everything in memory.
+	Path string
+	Host string
+	// Port defaults to the standard port of the driver.
+	Port     string
+	User     string
+	Password string
+	Name     string
+	// SSLMode is the Postgres sslmode, e.g. disable, require or verify-full.
+	SSLMode string
+	// MigrateOnStart applies pending migrations when the server starts.
+	MigrateOnStart bool
+}
+
+type LineConfig struct {
+	ChannelToken  string
+	ChannelSecret string
+}
+
+type GmailConfig struct {
+	// CredentialsPath enables Gmail; it requires PubSubTopic.
+	CredentialsPath string
+	PubSubTopic     string
+}
+
+// Enabled reports whether users can link Gmail mailboxes.
+func (c GmailConfig) Enabled() bool {
+	return c.CredentialsPath != ""
+}
+
+type MailConfig struct {
+	// MaxUnread is the page size of unread lists and "もっと見る".
+	MaxUnread int
+	// MaxPush is the number of unread messages checked per push notification.
+	MaxPush      int
+	ListDefault  int
+	ListMaxLimit int
+}
+
+type QueueConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	StaleAfter   time.Duration
+	JobTimeout   time.Duration
+	// DoneRetention is how long finished jobs are kept; 0 keeps them forever.
+	DoneRetention time.Duration
+}
+
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	ServiceName string
+	SampleRatio float64
+}
+
+type RetentionConfig struct {
+	// Days deletes stored emails received more than this many days ago;
+	// 0 keeps them forever. Users may choose a shorter period.
+	Days int
+	// StripAfterNotify drops the sender, subject and preview of an email
+	// once its notification was sent.
+	StripAfterNotify bool
+	// HashMessageIDs stores only a SHA-256 hash of the Gmail message ID and
+	// no content, which is enough for deduplication.
+	HashMessageIDs bool
+	PruneInterval  time.Duration
+	BatchSize      int
+}
+
+type ExportConfig struct {
+	// SigningKey signs the download links of data exports.
+	SigningKey string
+	// URLTTL is how long a download link stays valid.
+	URLTTL time.Duration
+}
+
+// Enabled reports whether download links can be issued.
+func (c ExportConfig) Enabled() bool {
+	return c.SigningKey != ""
+}
+
+type SlackConfig struct {
+	// Enabled lets users add Slack incoming webhooks as notification
+	// channels.
+	Enabled bool
+	// BaseURL is the host of incoming webhooks; registered webhooks must
+	// be under it.
+	BaseURL string
+}
+
+type DiscordConfig struct {
+	// Enabled lets users add Discord webhooks as notification channels.
+	Enabled bool
+	// BaseURL is the host of webhooks; registered webhooks must be under
+	// it.
+	BaseURL string
+}
+
+type TelegramConfig struct {
+	// Enabled serves /webhook/telegram and lets users link Telegram chats,
+	// which then receive notifications and take commands.
+	Enabled  bool
+	BotToken string
+	// WebhookSecret is the secret_token registered with setWebhook; updates
+	// without it are rejected.
+	WebhookSecret string
+	// BotUsername, without "@", makes link codes open the bot directly.
+	BotUsername string
+	// BaseURL is the Bot API server.
+	BaseURL string
+}
+
+type OutlookConfig struct {
+	// Enabled lets users link Outlook and Microsoft 365 mailboxes through
+	// Microsoft Graph. It serves /oauth/outlook/callback and
+	// /webhook/outlook under server.public_url.
+	Enabled      bool
+	ClientID     string
+	ClientSecret string
+	// TenantID is the Entra ID tenant, or "common" for any account.
+	TenantID string
+	// ClientState is sent with every change notification; notifications
+	// without it are dropped.
+	ClientState string
+	// BaseURL is the Graph API, including the version.
+	BaseURL string
+}
+
+type WatchConfig struct {
+	// RenewInterval is the interval between checks for expiring watches.
+	RenewInterval time.Duration
+	// RenewBefore renews a Gmail watch or Outlook subscription once it
+	// expires within this period.
+	RenewBefore time.Duration
+}
+
+type WebhooksConfig struct {
+	// Enabled lets users and admins register endpoints that receive signed
+	// events.
+	Enabled bool
+	// AllowPrivateNetworks lets endpoints resolve to loopback and private
+	// addresses and use plain HTTP. It is meant for local development.
+	AllowPrivateNetworks bool
+}
+
+type AdminConfig struct {
+	// Token enables the admin API for bearer token clients.
+	Token string
+	// ClientCAFile enables the admin API for clients with a certificate
+	// signed by one of these CAs. It requires TLS.
+	ClientCAFile string
+}
+
+// Enabled reports whether any admin authentication method is configured.
+func (c AdminConfig) Enabled() bool {
+	return c.Token != "" || c.ClientCAFile != ""
+}
+
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:         DriverMySQL,
+			Path:           "flux.db",
+			SSLMode:        "prefer",
+			MigrateOnStart: true,
+		},
+		Mail: MailConfig{
+			MaxUnread:    10,
+			MaxPush:      5,
+			ListDefault:  10,
+			ListMaxLimit: 30,
+		},
+		Queue: QueueConfig{
+			Workers:       4,
+			PollInterval:  time.Second,
+			MaxAttempts:   5,
+			StaleAfter:    5 * time.Minute,
+			JobTimeout:    2 * time.Minute,
+			DoneRetention: 7 * 24 * time.Hour,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "flux",
+			SampleRatio: 1,
+		},
+		Retention: RetentionConfig{
+			PruneInterval: time.Hour,
+			BatchSize:     500,
+		},
+		Watch: WatchConfig{
+			RenewInterval: time.Hour,
+			RenewBefore:   24 * time.Hour,
+		},
+		Export: ExportConfig{
+			URLTTL: 15 * time.Minute,
+		},
+		Slack: SlackConfig{
+			BaseURL: "https://hooks.slack.com",
+		},
+		Discord: DiscordConfig{
+			BaseURL: "https://discord.com",
+		},
+		Telegram: TelegramConfig{
+			BaseURL: "https://api.telegram.org",
+		},
+		Outlook: OutlookConfig{
+			TenantID: "common",
+			BaseURL:  "https://graph.microsoft.com/v1.0",
+		},
+	}
+}
+
+// Requirement is a set of integrations a command uses besides the database.
+type Requirement uint8
+
+const (
+	// RequireLine needs the LINE channel access token.
+	RequireLine Requirement = 1 << iota
+	// RequireMail needs at least one configured mail provider.
+	RequireMail
+	// RequireWebhooks needs the secrets that verify incoming webhooks.
+	RequireWebhooks
+
+	// RequireServer is everything the HTTP server uses.
+	RequireServer = RequireLine | RequireMail | RequireWebhooks
+)
+
+// Load builds the configuration from defaults, an optional YAML file, the
+// environment and command line flags, in increasing order of precedence.
+// The file is named by -config or FLUX_CONFIG. It returns the arguments
+// left after the flags. The caller validates the configuration once it
+// knows what the command requires.
+func Load(args []string) (*Config, []string, error) {
+	cfg := defaults()
+	cfg.settings = cfg.bind()
+
+	fs := flag.NewFlagSet("flux", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(configFileEnv), "path to a YAML config file")
+	fs.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration and exit")
+	for _, s := range cfg.settings {
+		fs.Var(&flagValue{s: s}, s.key, s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range cfg.settings {
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			if err := s.set(v, SourceEnv); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	// Flags were parsed first to find the config file, so their values are
+	// applied again on top of the file and environment.
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if fv, ok := f.Value.(*flagValue); ok && flagErr == nil {
+			flagErr = fv.s.set(fv.raw, SourceFlag)
+		}
+	})
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+
+	if cfg.Database.Port == "" {
+		switch cfg.Database.Driver {
+		case DriverMySQL:
+			cfg.Database.Port = "3306"
+		case DriverPostgres:
+			cfg.Database.Port = "5432"
+		}
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	values := make(map[string]string)
+	flatten("", doc, values)
+
+	byKey := make(map[string]*setting, len(c.settings))
+	for _, s := range c.settings {
+		byKey[s.key] = s
+	}
+
+	for key, v := range values {
+		s, ok := byKey[key]
+		if !ok {
+			return fmt.Errorf("unknown config key %q in %s", key, path)
+		}
+		if err := s.set(v, SourceFile); err != nil {
+			return fmt.Errorf("invalid %s in %s: %w", key, path, err)
+		}
+	}
+
+	return nil
+}
+
+func flatten(prefix string, doc map[string]any, out map[string]string) {
+	for k, v := range doc {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok {
+			flatten(key, m, out)
+			continue
+		}
+		out[key] = fmt.Sprint(v)
+	}
+}
+
+// Validate reports every missing or out of range value at once. The
+// database is always checked; the settings of integrations only when
+// required includes them.
+func (c *Config) Validate(required Requirement) error {
+	var errs []error
+
+	for _, s := range c.settings {
+		if required&s.require != 0 && s.value.String() == "" {
+			errs = append(errs, fmt.Errorf("%s is required (set %s or -%s)", s.key, s.env, s.key))
+		}
+	}
+
+	// Every numeric setting is a count, limit or timeout. Settings that
+	// allow zero use it to turn a feature off.
+	for _, s := range c.settings {
+		switch v := s.value.(type) {
+		case *intValue:
+			if *v < 0 || (*v == 0 && !s.allowZero) {
+				errs = append(errs, fmt.Errorf("%s must be positive, got %d", s.key, *v))
+			}
+		case *durationValue:
+			if *v < 0 || (*v == 0 && !s.allowZero) {
+				errs = append(errs, fmt.Errorf("%s must be positive, got %s", s.key, v))
+			}
+		}
+	}
+
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres:
+		for _, s := range c.settings {
+			switch s.key {
+			case "db.host", "db.user", "db.name":
+				if s.value.String() == "" {
+					errs = append(errs, fmt.Errorf("%s is required for the %s driver (set %s or -%s)", s.key, c.Database.Driver, s.env, s.key))
+				}
+			}
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			errs = append(errs, errors.New("db.path is required for the sqlite driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("db.driver must be mysql, postgres or sqlite, got %q", c.Database.Driver))
+	}
+
+	if c.Gmail.Enabled() && c.Gmail.PubSubTopic == "" {
+		errs = append(errs, errors.New("gmail.credentials_path requires gmail.pubsub_topic"))
+	}
+	if required&RequireMail != 0 && !c.Gmail.Enabled() && !c.Outlook.Enabled {
+		errs = append(errs, errors.New("a mail provider is required (set gmail.credentials_path or outlook.enabled)"))
+	}
+
+	if c.Mail.ListDefault > c.Mail.ListMaxLimit {
+		errs = append(errs, fmt.Errorf("mail.list_default (%d) exceeds mail.list_max_limit (%d)", c.Mail.ListDefault, c.Mail.ListMaxLimit))
+	}
+	// Gmail rejects list requests above 500 results.
+	if c.Mail.ListMaxLimit > 500 || c.Mail.MaxUnread > 500 {
+		errs = append(errs, errors.New("mail limits must not exceed 500"))
+	}
+
+	// A watch renewed within renew_before of its expiry must still be
+	// valid at the next check; Outlook subscriptions last 70 hours.
+	if c.Watch.RenewBefore <= c.Watch.RenewInterval {
+		errs = append(errs, fmt.Errorf("watch.renew_before (%s) must exceed watch.renew_interval (%s)", c.Watch.RenewBefore, c.Watch.RenewInterval))
+	}
+	if c.Watch.RenewBefore >= 70*time.Hour {
+		errs = append(errs, fmt.Errorf("watch.renew_before must be below the 70h Outlook subscription lifetime, got %s", c.Watch.RenewBefore))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, otlp or stdout, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("server.tls_cert_file and server.tls_key_file must be set together"))
+	}
+	if c.Admin.ClientCAFile != "" && c.Server.TLSCertFile == "" {
+		errs = append(errs, errors.New("admin.client_ca_file requires server.tls_cert_file and server.tls_key_file"))
+	}
+	// Bearer tokens are compared in full, but a short one is still guessable.
+	if c.Admin.Token != "" && len(c.Admin.Token) < 32 {
+		errs = append(errs, errors.New("admin.token must be at least 32 characters"))
+	}
+
+	if c.Export.Enabled() && c.Server.PublicURL == "" {
+		errs = append(errs, errors.New("export.signing_key requires server.public_url"))
+	}
+	if c.Export.SigningKey != "" && len(c.Export.SigningKey) < 32 {
+		errs = append(errs, errors.New("export.signing_key must be at least 32 characters"))
+	}
+
+	if c.Slack.Enabled {
+		if u, err := url.Parse(c.Slack.BaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("slack.base_url must be an http or https URL, got %q", c.Slack.BaseURL))
+		}
+	}
+	if c.Discord.Enabled {
+		if u, err := url.Parse(c.Discord.BaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("discord.base_url must be an http or https URL, got %q", c.Discord.BaseURL))
+		}
+	}
+	if c.Telegram.Enabled {
+		if c.Telegram.BotToken == "" {
+			errs = append(errs, errors.New("telegram.bot_token is required when telegram is enabled"))
+		}
+		if !validTelegramSecret(c.Telegram.WebhookSecret) {
+			errs = append(errs, errors.New("telegram.webhook_secret must be 1-256 characters of A-Z, a-z, 0-9, _ and -"))
+		}
+		if u, err := url.Parse(c.Telegram.BaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("telegram.base_url must be an http or https URL, got %q", c.Telegram.BaseURL))
+		}
+	}
+
+	if c.Outlook.Enabled {
+		if c.Outlook.ClientID == "" || c.Outlook.ClientSecret == "" {
+			errs = append(errs, errors.New("outlook.client_id and outlook.client_secret are required when outlook is enabled"))
+		}
+		if c.Server.PublicURL == "" {
+			errs = append(errs, errors.New("outlook.enabled requires server.public_url"))
+		}
+		// Graph accepts up to 128 characters.
+		if len(c.Outlook.ClientState) < 32 || len(c.Outlook.ClientState) > 128 {
+			errs = append(errs, errors.New("outlook.client_state must be 32-128 characters"))
+		}
+		if u, err := url.Parse(c.Outlook.BaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("outlook.base_url must be an http or https URL, got %q", c.Outlook.BaseURL))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validTelegramSecret checks the characters Telegram allows in a webhook
+// secret token.
+func validTelegramSecret(s string) bool {
+	if len(s) == 0 || len(s) > 256 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes the effective configuration with secrets redacted.
+func (c *Config) Print(w io.Writer) {
+	width := 0
+	for _, s := range c.settings {
+		width = max(width, len(s.key))
+	}
+
+	for _, s := range c.settings {
+		v := s.value.String()
+		if s.secret && v != "" {
+			v = "********"
+		}
+		fmt.Fprintf(w, "%-*s = %-30s (%s)\n", width, s.key, strconv.Quote(v), s.source)
+	}
+}
+
+// setting binds one config field to its YAML key, flag and environment variable.
+type setting struct {
+	key       string
+	env       string
+	usage     string
+	require   Requirement
+	secret    bool
+	allowZero bool
+	value     flag.Value
+	source    Source
+}
+
+func (s *setting) set(v string, source Source) error {
+	if err := s.value.Set(strings.TrimSpace(v)); err != nil {
+		return err
+	}
+	s.source = source
+	return nil
+}
+
+func (c *Config) bind() []*setting {
+	settings := []*setting{
+		{key: "server.port", env: "PORT", usage: "HTTP listen port", value: (*stringValue)(&c.Server.Port)},
+		{key: "server.read_timeout", env: "SERVER_READ_TIMEOUT", usage: "HTTP read timeout", value: (*durationValue)(&c.Server.ReadTimeout)},
+		{key: "server.write_timeout", env: "SERVER_WRITE_TIMEOUT", usage: "HTTP write timeout", value: (*durationValue)(&c.Server.WriteTimeout)},
+		{key: "server.idle_timeout", env: "SERVER_IDLE_TIMEOUT", usage: "HTTP idle timeout", value: (*durationValue)(&c.Server.IdleTimeout)},
+		{key: "server.shutdown_timeout", env: "SERVER_SHUTDOWN_TIMEOUT", usage: "graceful shutdown timeout", value: (*durationValue)(&c.Server.ShutdownTimeout)},
+		{key: "server.tls_cert_file", env: "SERVER_TLS_CERT_FILE", usage: "TLS certificate file; enables HTTPS", value: (*stringValue)(&c.Server.TLSCertFile)},
+		{key: "server.tls_key_file", env: "SERVER_TLS_KEY_FILE", usage: "TLS private key file", value: (*stringValue)(&c.Server.TLSKeyFile)},
+		{key: "server.public_url", env: "SERVER_PUBLIC_URL", usage: "externally reachable base URL, e.g. https://flux.example.com", value: (*stringValue)(&c.Server.PublicURL)},
+
+		{key: "db.driver", env: "DB_DRIVER", usage: "storage backend: mysql, postgres or sqlite", value: (*stringValue)(&c.Database.Driver)},
+		{key: "db.path", env: "DB_PATH", usage: "SQLite database file", value: (*stringValue)(&c.Database.Path)},
+		{key: "db.host", env: "DB_HOST", usage: "database host", value: (*stringValue)(&c.Database.Host)},
+		{key: "db.port", env: "DB_PORT", usage: "database port (default 3306 for mysql, 5432 for postgres)", value: (*stringValue)(&c.Database.Port)},
+		{key: "db.user", env: "DB_USER", usage: "database user", value: (*stringValue)(&c.Database.User)},
+		{key: "db.password", env: "DB_PASSWORD", usage: "database password", secret: true, value: (*stringValue)(&c.Database.Password)},
+		{key: "db.name", env: "DB_NAME", usage: "database name", value: (*stringValue)(&c.Database.Name)},
+		{key: "db.sslmode", env: "DB_SSLMODE", usage: "Postgres sslmode", value: (*stringValue)(&c.Database.SSLMode)},
+		{key: "db.migrate_on_start", env: "DB_MIGRATE_ON_START", usage: "apply pending migrations when the server starts", value: (*boolValue)(&c.Database.MigrateOnStart)},
+
+		{key: "line.channel_token", env: "LINE_CHANNEL_TOKEN", usage: "LINE channel access token", require: RequireLine, secret: true, value: (*stringValue)(&c.Line.ChannelToken)},
+		{key: "line.channel_secret", env: "LINE_CHANNEL_SECRET", usage: "LINE channel secret", require: RequireWebhooks, secret: true, value: (*stringValue)(&c.Line.ChannelSecret)},
+
+		{key: "gmail.credentials_path", env: "GMAIL_CREDENTIALS_PATH", usage: "Google OAuth client credentials file; enables Gmail", value: (*stringValue)(&c.Gmail.CredentialsPath)},
+		{key: "gmail.pubsub_topic", env: "PUBSUB_TOPIC", usage: "Pub/Sub topic for Gmail watch notifications", value: (*stringValue)(&c.Gmail.PubSubTopic)},
+
+		{key: "mail.max_unread", env: "MAIL_MAX_UNREAD", usage: "messages per unread list page", value: (*intValue)(&c.Mail.MaxUnread)},
+		{key: "mail.max_push", env: "MAIL_MAX_PUSH", usage: "unread messages checked per push notification", value: (*intValue)(&c.Mail.MaxPush)},
+		{key: "mail.list_default", env: "MAIL_LIST_DEFAULT", usage: "default count of the mail list command", value: (*intValue)(&c.Mail.ListDefault)},
+		{key: "mail.list_max_limit", env: "MAIL_LIST_MAX_LIMIT", usage: "maximum count of the mail list command", value: (*intValue)(&c.Mail.ListMaxLimit)},
+
+		{key: "queue.workers", env: "QUEUE_WORKERS", usage: "number of job workers", value: (*intValue)(&c.Queue.Workers)},
+		{key: "queue.poll_interval", env: "QUEUE_POLL_INTERVAL", usage: "job polling interval", value: (*durationValue)(&c.Queue.PollInterval)},
+		{key: "queue.max_attempts", env: "QUEUE_MAX_ATTEMPTS", usage: "attempts before a job is dead-lettered", value: (*intValue)(&c.Queue.MaxAttempts)},
+		{key: "queue.stale_after", env: "QUEUE_STALE_AFTER", usage: "time after which a running job is requeued", value: (*durationValue)(&c.Queue.StaleAfter)},
+		{key: "queue.job_timeout", env: "QUEUE_JOB_TIMEOUT", usage: "timeout of a single job run", value: (*durationValue)(&c.Queue.JobTimeout)},
+		{key: "queue.done_retention", env: "QUEUE_DONE_RETENTION", usage: "time finished jobs are kept (0 keeps them)", allowZero: true, value: (*durationValue)(&c.Queue.DoneRetention)},
+
+		{key: "tracing.exporter", env: "TRACING_EXPORTER", usage: "trace exporter: none, otlp or stdout", value: (*stringValue)(&c.Tracing.Exporter)},
+		{key: "tracing.endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", usage: "OTLP/HTTP endpoint URL", value: (*stringValue)(&c.Tracing.Endpoint)},
+		{key: "tracing.service_name", env: "OTEL_SERVICE_NAME", usage: "service name reported in traces", value: (*stringValue)(&c.Tracing.ServiceName)},
+		{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", usage: "fraction of traces to record", value: (*floatValue)(&c.Tracing.SampleRatio)},
+
+		{key: "retention.days", env: "RETENTION_DAYS", usage: "days to keep stored emails, 0 keeps them forever", allowZero: true, value: (*intValue)(&c.Retention.Days)},
+		{key: "retention.strip_after_notify", env: "RETENTION_STRIP_AFTER_NOTIFY", usage: "drop email content once the notification was sent", value: (*boolValue)(&c.Retention.StripAfterNotify)},
+		{key: "retention.hash_message_ids", env: "RETENTION_HASH_MESSAGE_IDS", usage: "store only a hash of Gmail message IDs and no content", value: (*boolValue)(&c.Retention.HashMessageIDs)},
+		{key: "retention.prune_interval", env: "RETENTION_PRUNE_INTERVAL", usage: "interval between email pruning runs", value: (*durationValue)(&c.Retention.PruneInterval)},
+		{key: "retention.batch_size", env: "RETENTION_BATCH_SIZE", usage: "emails deleted per pruning statement", value: (*intValue)(&c.Retention.BatchSize)},
+
+		{key: "watch.renew_interval", env: "WATCH_RENEW_INTERVAL", usage: "interval between checks for expiring mail watches", value: (*durationValue)(&c.Watch.RenewInterval)},
+		{key: "watch.renew_before", env: "WATCH_RENEW_BEFORE", usage: "time before expiry at which a mail watch is renewed", value: (*durationValue)(&c.Watch.RenewBefore)},
+
+		{key: "export.signing_key", env: "EXPORT_SIGNING_KEY", usage: "key signing data export links; enables data exports", secret: true, value: (*stringValue)(&c.Export.SigningKey)},
+		{key: "export.url_ttl", env: "EXPORT_URL_TTL", usage: "validity of data export links", value: (*durationValue)(&c.Export.URLTTL)},
+
+		{key: "slack.enabled", env: "SLACK_ENABLED", usage: "let users receive notifications on Slack incoming webhooks", value: (*boolValue)(&c.Slack.Enabled)},
+		{key: "slack.base_url", env: "SLACK_BASE_URL", usage: "base URL of Slack incoming webhooks", value: (*stringValue)(&c.Slack.BaseURL)},
+
+		{key: "discord.enabled", env: "DISCORD_ENABLED", usage: "let users receive notifications on Discord webhooks", value: (*boolValue)(&c.Discord.Enabled)},
+		{key: "discord.base_url", env: "DISCORD_BASE_URL", usage: "base URL of Discord webhooks", value: (*stringValue)(&c.Discord.BaseURL)},
+
+		{key: "telegram.enabled", env: "TELEGRAM_ENABLED", usage: "let users link Telegram chats for notifications and commands", value: (*boolValue)(&c.Telegram.Enabled)},
+		{key: "telegram.bot_token", env: "TELEGRAM_BOT_TOKEN", usage: "Telegram bot token", secret: true, value: (*stringValue)(&c.Telegram.BotToken)},
+		{key: "telegram.webhook_secret", env: "TELEGRAM_WEBHOOK_SECRET", usage: "secret_token of the Telegram webhook", secret: true, value: (*stringValue)(&c.Telegram.WebhookSecret)},
+		{key: "telegram.bot_username", env: "TELEGRAM_BOT_USERNAME", usage: "Telegram bot username for link URLs", value: (*stringValue)(&c.Telegram.BotUsername)},
+		{key: "telegram.base_url", env: "TELEGRAM_BASE_URL", usage: "base URL of the Telegram Bot API", value: (*stringValue)(&c.Telegram.BaseURL)},
+
+		{key: "outlook.enabled", env: "OUTLOOK_ENABLED", usage: "let users link Outlook mailboxes through Microsoft Graph", value: (*boolValue)(&c.Outlook.Enabled)},
+		{key: "outlook.client_id", env: "OUTLOOK_CLIENT_ID", usage: "application (client) ID of the Entra ID app", value: (*stringValue)(&c.Outlook.ClientID)},
+		{key: "outlook.client_secret", env: "OUTLOOK_CLIENT_SECRET", usage: "client secret of the Entra ID app", secret: true, value: (*stringValue)(&c.Outlook.ClientSecret)},
+		{key: "outlook.tenant_id", env: "OUTLOOK_TENANT_ID", usage: "Entra ID tenant, or common for any account", value: (*stringValue)(&c.Outlook.TenantID)},
+		{key: "outlook.client_state", env: "OUTLOOK_CLIENT_STATE", usage: "secret sent with Graph change notifications", secret: true, value: (*stringValue)(&c.Outlook.ClientState)},
+		{key: "outlook.base_url", env: "OUTLOOK_BASE_URL", usage: "base URL of the Microsoft Graph API", value: (*stringValue)(&c.Outlook.BaseURL)},
+
+		{key: "webhooks.enabled", env: "WEBHOOKS_ENABLED", usage: "let users register endpoints for signed event webhooks", value: (*boolValue)(&c.Webhooks.Enabled)},
+		{key: "webhooks.allow_private_networks", env: "WEBHOOKS_ALLOW_PRIVATE_NETWORKS", usage: "allow webhook endpoints on private networks and plain HTTP", value: (*boolValue)(&c.Webhooks.AllowPrivateNetworks)},
+
+		{key: "admin.token", env: "ADMIN_TOKEN", usage: "bearer token of the admin API", secret: true, value: (*stringValue)(&c.Admin.Token)},
+		{key: "admin.client_ca_file", env: "ADMIN_CLIENT_CA_FILE", usage: "CA bundle for admin client certificates", value: (*stringValue)(&c.Admin.ClientCAFile)},
+	}
+	for _, s := range settings {
+		s.source = SourceDefault
+	}
+	return settings
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+func (v *stringValue) String() string { return string(*v) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(n)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type floatValue float64
+
+func (v *floatValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*v = floatValue(f)
+	return nil
+}
+
+func (v *floatValue) String() string { return strconv.FormatFloat(float64(*v), 'g', -1, 64) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+// flagValue holds a flag until the file and environment have been applied,
+// so that flags take precedence regardless of parse order.
+type flagValue struct {
+	s   *setting
+	raw string
+}
+
+func (v *flagValue) Set(s string) error {
+	// Validate early so flag errors are reported with the usage message.
+	if err := v.s.value.Set(s); err != nil {
+		return err
+	}
+	v.raw = s
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given as a bare -flag.
+func (v *flagValue) IsBoolFlag() bool {
+	_, ok := v.s.value.(*boolValue)
+	return ok
+}
+
+func (v *flagValue) String() string {
+	if v == nil || v.s == nil {
+		return ""
+	}
+	return v.s.value.String()
+}
@@ -3,87 +3,289 @@ package di
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/huavcjj/flux/internal/config"
+	auditdomain "github.com/huavcjj/flux/internal/domain/audit"
 	emaildomain "github.com/huavcjj/flux/internal/domain/email"
-	gmaildomain "github.com/huavcjj/flux/internal/domain/gmail"
+	eventdomain "github.com/huavcjj/flux/internal/domain/event"
+	hookdomain "github.com/huavcjj/flux/internal/domain/hook"
+	jobdomain "github.com/huavcjj/flux/internal/domain/job"
 	linedomain "github.com/huavcjj/flux/internal/domain/line"
+	maildomain "github.com/huavcjj/flux/internal/domain/mail"
+	notifierdomain "github.com/huavcjj/flux/internal/domain/notifier"
+	outboxdomain "github.com/huavcjj/flux/internal/domain/outbox"
+	telegramdomain "github.com/huavcjj/flux/internal/domain/telegram"
 	userdomain "github.com/huavcjj/flux/internal/domain/user"
+	auditrepo "github.com/huavcjj/flux/internal/infrastructure/repository/audit"
+	discordrepo "github.com/huavcjj/flux/internal/infrastructure/repository/discord"
 	emailrepo "github.com/huavcjj/flux/internal/infrastructure/repository/email"
+	eventrepo "github.com/huavcjj/flux/internal/infrastructure/repository/event"
 	gmailrepo "github.com/huavcjj/flux/internal/infrastructure/repository/gmail"
+	hookrepo "github.com/huavcjj/flux/internal/infrastructure/repository/hook"
+	jobrepo "github.com/huavcjj/flux/internal/infrastructure/repository/job"
 	linerepo "github.com/huavcjj/flux/internal/infrastructure/repository/line"
+	notifierrepo "github.com/huavcjj/flux/internal/infrastructure/repository/notifier"
+	outboxrepo "github.com/huavcjj/flux/internal/infrastructure/repository/outbox"
+	outlookrepo "github.com/huavcjj/flux/internal/infrastructure/repository/outlook"
+	slackrepo "github.com/huavcjj/flux/internal/infrastructure/repository/slack"
+	telegramrepo "github.com/huavcjj/flux/internal/infrastructure/repository/telegram"
 	userrepo "github.com/huavcjj/flux/internal/infrastructure/repository/user"
+	"github.com/huavcjj/flux/internal/service/admin"
+	"github.com/huavcjj/flux/internal/service/audit"
+	"github.com/huavcjj/flux/internal/service/hook"
 	"github.com/huavcjj/flux/internal/service/notification"
+	"github.com/huavcjj/flux/internal/service/outbox"
+	"github.com/huavcjj/flux/internal/service/queue"
+	"github.com/huavcjj/flux/internal/service/retention"
+	"github.com/huavcjj/flux/internal/service/richmenu"
+	"github.com/huavcjj/flux/internal/service/watch"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite"
 )
 
 type Container struct {
-	DB                  *sql.DB
-	GmailRepo           gmaildomain.GmailRepo
-	LineRepo            linedomain.LineRepo
-	UserRepo            userdomain.UserRepo
-	EmailRepo           emaildomain.EmailRepo
+	DB *sql.DB
+	// LineRepo is nil without a LINE channel token.
+	LineRepo    linedomain.LineRepo
+	UserRepo    userdomain.UserRepo
+	EmailRepo   emaildomain.EmailRepo
+	EventRepo   eventdomain.EventRepo
+	JobRepo     jobdomain.JobRepo
+	OutboxRepo  outboxdomain.OutboxRepo
+	AuditRepo   auditdomain.AuditRepo
+	ChannelRepo notifierdomain.ChannelRepo
+	HookRepo    hookdomain.HookRepo
+	// MailProviders holds the configured mail providers, by name.
+	MailProviders map[string]maildomain.MailProvider
+	// TelegramRepo is nil unless Telegram is enabled.
+	TelegramRepo        telegramdomain.TelegramRepo
+	AuditService        *audit.Service
 	NotificationService *notification.Service
+	RichMenuService     *richmenu.Service
+	QueueService        *queue.Service
+	OutboxDispatcher    *outbox.Dispatcher
+	HookService         *hook.Service
+	HookDispatcher      *hook.Dispatcher
+	EmailPruner         *retention.Pruner
+	WatchRenewer        *watch.Renewer
+	AdminService        *admin.Service
 }
 
-type Config struct {
-	LineChannelToken     string
-	GmailCredentialsPath string
-	DBHost               string
-	DBPort               string
-	DBUser               string
-	DBPassword           string
-	DBName               string
-}
-
-func NewContainer(ctx context.Context, cfg Config) (*Container, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
-
-	db, err := sql.Open("mysql", dsn)
+func NewContainer(ctx context.Context, cfg *config.Config) (*Container, error) {
+	db, err := OpenDB(cfg.Database)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
-
-	if err := db.Ping(); err != nil {
-		slog.Warn("database ping failed", "error", err)
-	} else {
-		slog.Info("database connected")
+	mailProviders := map[string]maildomain.MailProvider{}
+	if cfg.Gmail.Enabled() {
+		gmailRepo, err := gmailrepo.NewGmailRepo(ctx, cfg.Gmail.CredentialsPath, cfg.Gmail.PubSubTopic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Gmail repository: %w", err)
+		}
+		mailProviders[maildomain.ProviderGmail] = gmailRepo
+	}
+	if cfg.Outlook.Enabled {
+		publicURL := strings.TrimSuffix(cfg.Server.PublicURL, "/")
+		mailProviders[maildomain.ProviderOutlook] = outlookrepo.NewOutlookRepo(ctx, outlookrepo.Config{
+			ClientID:        cfg.Outlook.ClientID,
+			ClientSecret:    cfg.Outlook.ClientSecret,
+			TenantID:        cfg.Outlook.TenantID,
+			RedirectURL:     publicURL + "/oauth/outlook/callback",
+			NotificationURL: publicURL + "/webhook/outlook",
+			ClientState:     cfg.Outlook.ClientState,
+			BaseURL:         cfg.Outlook.BaseURL,
+		})
 	}
 
-	gmailRepo, err := gmailrepo.NewGmailRepo(ctx, cfg.GmailCredentialsPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Gmail repository: %w", err)
+	// Commands that do not talk to LINE run without a channel token.
+	var lineRepo linedomain.LineRepo
+	if cfg.Line.ChannelToken != "" {
+		lineRepo, err = linerepo.NewLineRepo(cfg.Line.ChannelToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize LINE repository: %w", err)
+		}
 	}
 
-	lineRepo, err := linerepo.NewLineRepo(cfg.LineChannelToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize LINE repository: %w", err)
+	r := newRepos(cfg.Database.Driver, db)
+	userRepo := r.user
+	emailRepo := r.email
+	eventRepo := r.event
+	jobRepo := r.job
+	outboxRepo := r.outbox
+	auditRepo := r.audit
+	channelRepo := r.channel
+	hookRepo := r.hook
+
+	auditService := audit.NewService(auditRepo)
+
+	notifiers := map[string]notifierdomain.Notifier{}
+	if lineRepo != nil {
+		notifiers[outboxdomain.ChannelLine] = linerepo.NewNotifier(lineRepo)
+	}
+	if cfg.Slack.Enabled {
+		notifiers[outboxdomain.ChannelSlack] = slackrepo.NewSlackRepo(cfg.Slack.BaseURL)
+	}
+	if cfg.Discord.Enabled {
+		notifiers[outboxdomain.ChannelDiscord] = discordrepo.NewDiscordRepo(cfg.Discord.BaseURL)
+	}
+	var telegramRepo telegramdomain.TelegramRepo
+	if cfg.Telegram.Enabled {
+		telegramRepo = telegramrepo.NewTelegramRepo(cfg.Telegram.BaseURL, cfg.Telegram.BotToken)
+		notifiers[outboxdomain.ChannelTelegram] = telegramRepo
 	}
 
-	userRepo := userrepo.NewUserRepo(db)
-	emailRepo := emailrepo.NewEmailRepo(db)
+	hookSender := hookrepo.NewSender(cfg.Webhooks.AllowPrivateNetworks)
+	hookService := hook.NewService(hookRepo, hookSender, cfg.Webhooks.Enabled)
+	hookDispatcher := hook.NewDispatcher(hookRepo, hookSender)
 
 	notificationService := notification.NewService(
-		gmailRepo,
+		mailProviders,
 		lineRepo,
 		userRepo,
 		emailRepo,
+		outboxRepo,
+		channelRepo,
+		notifiers,
+		auditService,
+		hookService,
+		notification.Config{
+			MaxUnreadEmails:     int64(cfg.Mail.MaxUnread),
+			MaxPushEmails:       int64(cfg.Mail.MaxPush),
+			HashMessageIDs:      cfg.Retention.HashMessageIDs,
+			EmailRetentionDays:  cfg.Retention.Days,
+			PublicURL:           cfg.Server.PublicURL,
+			ExportSigningKey:    []byte(cfg.Export.SigningKey),
+			ExportURLTTL:        cfg.Export.URLTTL,
+			TelegramBotUsername: cfg.Telegram.BotUsername,
+		},
 	)
 
+	richMenuService := richmenu.NewService(lineRepo, userRepo)
+
+	outboxDispatcher := outbox.NewDispatcher(outboxRepo, notifiers)
+
+	queueService := queue.NewService(jobRepo, queue.Config{
+		Workers:       cfg.Queue.Workers,
+		PollInterval:  cfg.Queue.PollInterval,
+		MaxAttempts:   cfg.Queue.MaxAttempts,
+		StaleAfter:    cfg.Queue.StaleAfter,
+		JobTimeout:    cfg.Queue.JobTimeout,
+		DoneRetention: cfg.Queue.DoneRetention,
+	})
+	queueService.Register(jobdomain.TypeGmailPush, func(ctx context.Context, job *jobdomain.Job) error {
+		var payload jobdomain.GmailPushPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode Gmail push payload: %w", err)
+		}
+		userIDs, err := notificationService.LinkedGmailUsers(ctx)
+		if err != nil {
+			return err
+		}
+		// One job per mailbox, so a failing mailbox is retried on its own.
+		var errs []error
+		for _, userID := range userIDs {
+			dedupKey := fmt.Sprintf("gmail-sync:%s:%d", userID, payload.HistoryID)
+			syncPayload := jobdomain.GmailSyncPayload{LineUserID: userID, HistoryID: payload.HistoryID}
+			if err := queueService.Enqueue(ctx, jobdomain.TypeGmailSync, dedupKey, syncPayload); err != nil {
+				errs = append(errs, fmt.Errorf("failed to enqueue Gmail sync of %s: %w", userID, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+	queueService.Register(jobdomain.TypeGmailSync, func(ctx context.Context, job *jobdomain.Job) error {
+		var payload jobdomain.GmailSyncPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode Gmail sync payload: %w", err)
+		}
+		if err := notificationService.ProcessGmailPush(ctx, payload.LineUserID, payload.HistoryID); err != nil {
+			return err
+		}
+		outboxDispatcher.Notify()
+		hookDispatcher.Notify()
+		return nil
+	})
+	queueService.Register(jobdomain.TypeOutlookPush, func(ctx context.Context, job *jobdomain.Job) error {
+		var payload jobdomain.OutlookPushPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode Outlook push payload: %w", err)
+		}
+		if err := notificationService.ProcessMailboxChange(ctx, payload.SubscriptionID); err != nil {
+			return err
+		}
+		outboxDispatcher.Notify()
+		hookDispatcher.Notify()
+		return nil
+	})
+	queueService.Register(jobdomain.TypeWatchRenew, func(ctx context.Context, job *jobdomain.Job) error {
+		var payload jobdomain.WatchRenewPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode watch renewal payload: %w", err)
+		}
+		return notificationService.RenewWatchByID(ctx, payload.WatchID)
+	})
+	queueService.Register(jobdomain.TypeGmailResync, func(ctx context.Context, job *jobdomain.Job) error {
+		var payload jobdomain.GmailResyncPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode resync payload: %w", err)
+		}
+		if err := notificationService.ResyncUser(ctx, payload.LineUserID); err != nil {
+			return err
+		}
+		outboxDispatcher.Notify()
+		hookDispatcher.Notify()
+		return nil
+	})
+
+	emailPruner := retention.NewPruner(emailRepo, outboxRepo, hookRepo, userRepo, retention.Config{
+		Days:             cfg.Retention.Days,
+		StripAfterNotify: cfg.Retention.StripAfterNotify,
+		HashMessageIDs:   cfg.Retention.HashMessageIDs,
+		Interval:         cfg.Retention.PruneInterval,
+		BatchSize:        cfg.Retention.BatchSize,
+	})
+
+	watchRenewer := watch.NewRenewer(userRepo, notificationService, watch.Config{
+		Interval:    cfg.Watch.RenewInterval,
+		RenewBefore: cfg.Watch.RenewBefore,
+	})
+
+	adminService := admin.NewService(userRepo, jobRepo, outboxRepo, notificationService, queueService)
+
 	return &Container{
 		DB:                  db,
-		GmailRepo:           gmailRepo,
+		MailProviders:       mailProviders,
 		LineRepo:            lineRepo,
 		UserRepo:            userRepo,
 		EmailRepo:           emailRepo,
+		EventRepo:           eventRepo,
+		JobRepo:             jobRepo,
+		OutboxRepo:          outboxRepo,
+		AuditRepo:           auditRepo,
+		ChannelRepo:         channelRepo,
+		HookRepo:            hookRepo,
+		TelegramRepo:        telegramRepo,
+		AuditService:        auditService,
 		NotificationService: notificationService,
+		RichMenuService:     richMenuService,
+		QueueService:        queueService,
+		OutboxDispatcher:    outboxDispatcher,
+		HookService:         hookService,
+		HookDispatcher:      hookDispatcher,
+		EmailPruner:         emailPruner,
+		WatchRenewer:        watchRenewer,
+		AdminService:        adminService,
 	}, nil
 }
 
@@ -93,3 +295,120 @@ func (c *Container) Close() error {
 	}
 	return nil
 }
+
+// OpenDB opens the traced connection pool of the configured driver. A failed
+// ping is only logged, so the server can start before the database is ready.
+func OpenDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		// _time_format=sqlite stores times in a sortable text format, so
+		// they compare correctly with CURRENT_TIMESTAMP.
+		dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite", cfg.Path)
+		db, err = otelsql.Open("sqlite", dsn,
+			otelsql.WithAttributes(semconv.DBSystemSqlite),
+			otelsql.WithSpanOptions(otelsql.SpanOptions{OmitRows: true, OmitConnResetSession: true}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+
+		// One connection serializes writers, which the SQLite job and
+		// outbox repositories rely on, and keeps ":memory:" databases alive.
+		db.SetMaxOpenConns(1)
+
+	case config.DriverPostgres:
+		dsn := (&url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.User, cfg.Password),
+			Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+			Path:     cfg.Name,
+			RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+		}).String()
+		db, err = otelsql.Open("pgx", dsn,
+			otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+			otelsql.WithSpanOptions(otelsql.SpanOptions{OmitRows: true, OmitConnResetSession: true}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+
+		db.SetMaxOpenConns(25)
+		db.SetMaxIdleConns(5)
+		db.SetConnMaxLifetime(5 * time.Minute)
+
+	default:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+		db, err = otelsql.Open("mysql", dsn,
+			otelsql.WithAttributes(semconv.DBSystemMySQL),
+			otelsql.WithSpanOptions(otelsql.SpanOptions{OmitRows: true, OmitConnResetSession: true}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+
+		db.SetMaxOpenConns(25)
+		db.SetMaxIdleConns(5)
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
+
+	if err := db.Ping(); err != nil {
+		slog.Warn("database ping failed", "error", err)
+	} else {
+		slog.Info("database connected", "driver", cfg.Driver)
+	}
+
+	return db, nil
+}
+
+type repos struct {
+	user    userdomain.UserRepo
+	email   emaildomain.EmailRepo
+	event   eventdomain.EventRepo
+	job     jobdomain.JobRepo
+	outbox  outboxdomain.OutboxRepo
+	audit   auditdomain.AuditRepo
+	channel notifierdomain.ChannelRepo
+	hook    hookdomain.HookRepo
+}
+
+func newRepos(driver string, db *sql.DB) repos {
+	switch driver {
+	case config.DriverPostgres:
+		return repos{
+			user:    userrepo.NewPostgresUserRepo(db),
+			email:   emailrepo.NewPostgresEmailRepo(db),
+			event:   eventrepo.NewPostgresEventRepo(db),
+			job:     jobrepo.NewPostgresJobRepo(db),
+			outbox:  outboxrepo.NewPostgresOutboxRepo(db),
+			audit:   auditrepo.NewPostgresAuditRepo(db),
+			channel: notifierrepo.NewPostgresChannelRepo(db),
+			hook:    hookrepo.NewPostgresHookRepo(db),
+		}
+	case config.DriverSQLite:
+		return repos{
+			user:    userrepo.NewSQLiteUserRepo(db),
+			email:   emailrepo.NewSQLiteEmailRepo(db),
+			event:   eventrepo.NewSQLiteEventRepo(db),
+			job:     jobrepo.NewSQLiteJobRepo(db),
+			outbox:  outboxrepo.NewSQLiteOutboxRepo(db),
+			audit:   auditrepo.NewSQLiteAuditRepo(db),
+			channel: notifierrepo.NewSQLiteChannelRepo(db),
+			hook:    hookrepo.NewSQLiteHookRepo(db),
+		}
+	default:
+		return repos{
+			user:    userrepo.NewUserRepo(db),
+			email:   emailrepo.NewEmailRepo(db),
+			event:   eventrepo.NewEventRepo(db),
+			job:     jobrepo.NewJobRepo(db),
+			outbox:  outboxrepo.NewOutboxRepo(db),
+			audit:   auditrepo.NewAuditRepo(db),
+			channel: notifierrepo.NewChannelRepo(db),
+			hook:    hookrepo.NewHookRepo(db),
+		}
+	}
+}
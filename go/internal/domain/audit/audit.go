@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ActorUser   = "user"
+	ActorSystem = "system"
+	// ActorAdminPrefix is followed by the credential of the admin API
+	// caller, e.g. "admin:token".
+	ActorAdminPrefix = "admin:"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultDenied  = "denied"
+)
+
+const (
+	ActionGmailLink        = "gmail.link"
+	ActionGmailUnlink      = "gmail.unlink"
+	ActionGmailTokenRevoke = "gmail.token_revoke"
+	ActionOutlookLink      = "outlook.link"
+	ActionOutlookUnlink    = "outlook.unlink"
+	ActionMailTokenRefresh = "mail.token_refresh"
+	ActionExportLink       = "data.export_link"
+	ActionExport           = "data.export"
+	ActionErase            = "data.erase"
+	ActionChannelAdd       = "channel.add"
+	ActionChannelRemove    = "channel.remove"
+	ActionWebhookAdd       = "webhook.add"
+	ActionWebhookRemove    = "webhook.remove"
+	// ActionAdminPrefix is followed by the admin API action, e.g.
+	// "admin.users.resync".
+	ActionAdminPrefix = "admin."
+)
+
+type Event struct {
+	ID    uint64
+	Actor string
+	// SubjectUserID is the users.id the event concerns, if any.
+	SubjectUserID *string
+	Action        string
+	Result        string
+	IPAddress     *string
+	UserAgent     *string
+	Detail        *string
+	CreatedAt     time.Time
+}
+
+// AuditLogger records events. Failures are handled by the logger, so a
+// broken audit trail never fails the audited operation.
+type AuditLogger interface {
+	Log(ctx context.Context, event Event)
+}
+
+// AuditRepo is append-only.
+type AuditRepo interface {
+	CreateEvent(ctx context.Context, event *Event) error
+	// ListEventsBySubject returns the newest events of a user, older than
+	// beforeID unless it is zero.
+	ListEventsBySubject(ctx context.Context, subjectUserID string, beforeID uint64, limit int) ([]Event, error)
+}
+
+// Client is the remote end of the request that caused an event.
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientKey{}).(Client)
+	return client, ok
+}
@@ -2,7 +2,13 @@ package email
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/huavcjj/flux/internal/domain/hook"
+	"github.com/huavcjj/flux/internal/domain/outbox"
 )
 
 type Email struct {
@@ -18,12 +24,72 @@ type Email struct {
 	UpdatedAt      time.Time
 }
 
+// Cursor is a position in a list of emails ordered newest first by
+// received_at and then by id.
+type Cursor struct {
+	ReceivedAt time.Time
+	ID         uint64
+}
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("page limit must be positive")
+)
+
+// String encodes the cursor compactly enough for LINE postback data.
+func (c Cursor) String() string {
+	return strconv.FormatInt(c.ReceivedAt.UnixNano(), 36) + "." + strconv.FormatUint(c.ID, 36)
+}
+
+func ParseCursor(s string) (*Cursor, error) {
+	ts, id, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(ts, 36, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	emailID, err := strconv.ParseUint(id, 36, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{ReceivedAt: time.Unix(0, nanos).UTC(), ID: emailID}, nil
+}
+
+// PageRequest asks for up to Limit emails following Cursor; a nil Cursor
+// starts with the newest email. Limit must be positive.
+type PageRequest struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// Page is one page of emails. Next is nil on the last page.
+type Page struct {
+	Emails []Email
+	Next   *Cursor
+}
+
 type EmailRepo interface {
 	CreateEmail(ctx context.Context, email *Email) error
+	// CreateEmailWithOutbox stores the email, its notifications and its
+	// webhook deliveries in one transaction.
+	CreateEmailWithOutbox(ctx context.Context, email *Email, entries []*outbox.Entry, deliveries []*hook.Delivery) error
 	GetEmailByGmailMessageID(ctx context.Context, gmailMessageID string) (*Email, error)
-	GetEmailsByUserID(ctx context.Context, userID string) ([]Email, error)
-	GetUnnotifiedEmailsByUserID(ctx context.Context, userID string) ([]Email, error)
-	GetRecentEmails(ctx context.Context, userID string, since time.Time) ([]Email, error)
+	GetEmailsByUserID(ctx context.Context, userID string, page PageRequest) (*Page, error)
+	GetUnnotifiedEmailsByUserID(ctx context.Context, userID string, page PageRequest) (*Page, error)
+	GetRecentEmails(ctx context.Context, userID string, since time.Time, page PageRequest) (*Page, error)
 	MarkEmailAsNotified(ctx context.Context, gmailMessageID string) error
 	DeleteEmailsByUserID(ctx context.Context, userID string) error
+	// SearchEmails returns the emails of the user whose sender, subject or
+	// preview contain query, newest first.
+	SearchEmails(ctx context.Context, userID, query string, page PageRequest) (*Page, error)
+	// DeleteEmailsOlderThan deletes up to limit emails of the user received
+	// before the given time, oldest first, and reports how many it deleted.
+	DeleteEmailsOlderThan(ctx context.Context, userID string, before time.Time, limit int) (int64, error)
+	// ClearNotifiedEmailContent drops the sender, subject and preview of up
+	// to limit notified emails, keeping only what deduplication needs.
+	ClearNotifiedEmailContent(ctx context.Context, limit int) (int64, error)
 }
@@ -0,0 +1,33 @@
+package email
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{ReceivedAt: time.Date(2025, 11, 20, 9, 30, 15, 123456789, time.UTC), ID: 42},
+		{ReceivedAt: time.Unix(0, 0).UTC(), ID: 1},
+		{ReceivedAt: time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC), ID: 1<<64 - 1},
+	}
+
+	for _, c := range cursors {
+		got, err := ParseCursor(c.String())
+		if err != nil {
+			t.Fatalf("ParseCursor(%q): %v", c.String(), err)
+		}
+		if !got.ReceivedAt.Equal(c.ReceivedAt) || got.ID != c.ID {
+			t.Errorf("ParseCursor(%q) = %+v, want %+v", c.String(), *got, c)
+		}
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	for _, s := range []string{"", "abc", ".1", "1.", "1.-1", "zzzzzzzzzzzzzzzz.1", "1.2.3"} {
+		if _, err := ParseCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("ParseCursor(%q) = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package event
+
+import (
+	"context"
+	"time"
+)
+
+type EventRepo interface {
+	// MarkProcessed records the event ID and reports whether it is seen for the first time within ttl.
+	MarkProcessed(ctx context.Context, eventID string, ttl time.Duration) (bool, error)
+}
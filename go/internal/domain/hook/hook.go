@@ -0,0 +1,129 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Event types sent to webhook endpoints.
+const (
+	EventEmailReceived = "email.received"
+	EventAuthLinked    = "auth.linked"
+	EventAuthRevoked   = "auth.revoked"
+)
+
+// EventVersion is the version of the event payloads. It changes only when a
+// field is removed or changes meaning.
+const EventVersion = 1
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+var ErrInvalidURL = errors.New("invalid webhook url")
+
+// Endpoint is the HTTPS URL a user receives events on.
+type Endpoint struct {
+	ID     uint64
+	UserID string
+	URL    string
+	// Secret is the HMAC-SHA256 key of the signatures.
+	Secret string
+	// CreatedBy is the audit actor who registered the endpoint.
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Event is the JSON body of a webhook request.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// UserID is the users.id the event concerns.
+	UserID string `json:"user_id"`
+	Data   any    `json:"data"`
+}
+
+type EmailReceivedData struct {
+	// MessageID is the Gmail message ID, or its hash when message IDs are
+	// not stored.
+	MessageID  string    `json:"message_id"`
+	ThreadID   string    `json:"thread_id,omitempty"`
+	From       string    `json:"from"`
+	To         string    `json:"to,omitempty"`
+	Subject    string    `json:"subject"`
+	Snippet    string    `json:"snippet"`
+	ReceivedAt time.Time `json:"received_at"`
+	Link       string    `json:"link,omitempty"`
+}
+
+type AuthData struct {
+	Provider string `json:"provider"`
+}
+
+// Delivery is one attempt series of sending an event to an endpoint.
+type Delivery struct {
+	ID         uint64
+	EndpointID uint64
+	UserID     string
+	// EventID is shared by the redeliveries of an event so receivers can
+	// deduplicate.
+	EventID   string
+	EventType string
+	Payload   string
+	Status    string
+	Attempts  int
+	// ResponseStatus is the HTTP status of the last attempt, if it got a
+	// response.
+	ResponseStatus *int
+	LastError      *string
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+	// RedeliveryOf is the delivery this one manually repeats.
+	RedeliveryOf *uint64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type HookRepo interface {
+	// GetEndpointByUserID returns nil if the user has no endpoint.
+	GetEndpointByUserID(ctx context.Context, userID string) (*Endpoint, error)
+	GetEndpoint(ctx context.Context, id uint64) (*Endpoint, error)
+	// SaveEndpoint adds the user's endpoint or replaces its URL and secret.
+	SaveEndpoint(ctx context.Context, endpoint *Endpoint) error
+	// DeleteEndpoint also drops the deliveries of the endpoint.
+	DeleteEndpoint(ctx context.Context, userID string) (bool, error)
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	// ClaimDueDeliveries returns pending deliveries that are due and leases
+	// them until leaseUntil so that other dispatchers skip them meanwhile.
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]Delivery, error)
+	MarkDelivered(ctx context.Context, id uint64, responseStatus int, deliveredAt time.Time) error
+	Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, responseStatus *int, lastError string) error
+	MarkFailed(ctx context.Context, id uint64, responseStatus *int, lastError string) error
+	// GetDelivery returns nil unless the delivery belongs to the user.
+	GetDelivery(ctx context.Context, userID string, id uint64) (*Delivery, error)
+	// ListDeliveries returns the newest deliveries of a user, older than
+	// beforeID unless it is zero.
+	ListDeliveries(ctx context.Context, userID string, beforeID uint64, limit int) ([]Delivery, error)
+	// DeleteFinishedDeliveries deletes up to limit delivered or failed
+	// deliveries of a user created before the given time.
+	DeleteFinishedDeliveries(ctx context.Context, userID string, before time.Time, limit int) (int64, error)
+	// ClearDeliveredPayloads empties the payload of up to limit delivered
+	// deliveries.
+	ClearDeliveredPayloads(ctx context.Context, limit int) (int64, error)
+}
+
+// Sender posts deliveries to endpoints.
+type Sender interface {
+	// Send returns the HTTP status of the response, or 0 if there was none.
+	// Any status but 2xx is an error.
+	Send(ctx context.Context, endpoint *Endpoint, delivery *Delivery) (int, error)
+	// ValidateURL checks an endpoint URL before it is registered.
+	ValidateURL(rawURL string) error
+}
@@ -0,0 +1,104 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusDead    = "dead"
+)
+
+const (
+	TypeGmailPush   = "gmail_push"
+	TypeGmailResync = "gmail_resync"
+	TypeGmailSync   = "gmail_sync"
+	TypeOutlookPush = "outlook_push"
+	TypeWatchRenew  = "watch_renew"
+)
+
+// Payloads are stored as JSON in queued jobs, so their keys are fixed by
+// tags and survive field renames.
+
+// GmailPushPayload is the payload of TypeGmailPush jobs, the notification
+// Gmail publishes for a mailbox change. The address Gmail sends is dropped:
+// every linked mailbox is synced anyway, and erasing a user could not find
+// jobs by an address flux does not store. Its keys are the ones of the
+// Gmail message it is decoded from.
+type GmailPushPayload struct {
+	HistoryID uint64 `json:"historyId"`
+}
+
+// GmailSyncPayload is the payload of TypeGmailSync jobs, which sync one
+// mailbox after a push notification.
+type GmailSyncPayload struct {
+	LineUserID string `json:"line_user_id"`
+	HistoryID  uint64 `json:"history_id"`
+}
+
+// GmailResyncPayload is the payload of TypeGmailResync jobs.
+type GmailResyncPayload struct {
+	LineUserID string `json:"line_user_id"`
+}
+
+// OutlookPushPayload is the payload of TypeOutlookPush jobs.
+type OutlookPushPayload struct {
+	SubscriptionID string `json:"subscription_id"`
+}
+
+// WatchRenewPayload is the payload of TypeWatchRenew jobs, which renew a
+// watch the provider asked to be renewed.
+type WatchRenewPayload struct {
+	WatchID string `json:"watch_id"`
+}
+
+// ResyncDedupKeyPrefix starts the dedup keys of the resync jobs of a user.
+func ResyncDedupKeyPrefix(userID string) string {
+	return "resync:" + userID + ":"
+}
+
+type Job struct {
+	ID          uint64
+	Type        string
+	DedupKey    *string
+	Payload     []byte
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LockedAt    *time.Time
+	LastError   *string
+	// TraceParent links the job to the trace of the request that enqueued it.
+	TraceParent *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type JobRepo interface {
+	// CreateJob ignores jobs whose DedupKey already exists.
+	CreateJob(ctx context.Context, job *Job) error
+	// ClaimJob marks the next due pending job as running and returns it, or nil when none is due.
+	ClaimJob(ctx context.Context, now time.Time) (*Job, error)
+	CompleteJob(ctx context.Context, id uint64) error
+	RetryJob(ctx context.Context, id uint64, runAt time.Time, lastError string) error
+	DeadLetterJob(ctx context.Context, id uint64, lastError string) error
+	// RequeueStaleJobs hands out the running jobs locked before lockedBefore
+	// again, unless their attempts are used up.
+	RequeueStaleJobs(ctx context.Context, lockedBefore time.Time) (int64, error)
+	// DeadLetterStaleJobs dead-letters the stale running jobs whose attempts
+	// are used up.
+	DeadLetterStaleJobs(ctx context.Context, lockedBefore time.Time) (int64, error)
+	// DeleteDoneJobs deletes up to limit jobs that finished before the given
+	// time.
+	DeleteDoneJobs(ctx context.Context, before time.Time, limit int) (int64, error)
+	CountJobsByStatus(ctx context.Context) (map[string]int64, error)
+	ListJobsByStatus(ctx context.Context, status string, limit int) ([]Job, error)
+	// ReplayDeadJob moves a dead job back to pending with a fresh attempt
+	// budget. It reports false when the job does not exist or is not dead.
+	ReplayDeadJob(ctx context.Context, id uint64) (bool, error)
+	// GetOldestPendingRunAt returns nil when no job is pending.
+	GetOldestPendingRunAt(ctx context.Context) (*time.Time, error)
+}
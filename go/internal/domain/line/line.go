@@ -1,14 +1,108 @@
 package line
 
-import "context"
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Rich menu aliases provisioned by the richmenu command. Unlinked users see
+// the default menu, linked users get the linked menu attached individually.
+const (
+	RichMenuAliasUnlinked = "flux-unlinked"
+	RichMenuAliasLinked   = "flux-linked"
+)
+
+const (
+	ActionTypeMessage  = "message"
+	ActionTypePostback = "postback"
+	ActionTypeURI      = "uri"
+)
 
 type NotificationMessage struct {
 	UserID  string
 	Message string
 }
 
+// ReplyToken is the token of an inbound event. It can be used once, and only
+// until ExpiresAt, to answer the event without consuming push quota.
+type ReplyToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (t ReplyToken) IsValid(now time.Time) bool {
+	return t.Token != "" && now.Before(t.ExpiresAt)
+}
+
+type Action struct {
+	Type  string
+	Label string
+	Text  string
+	Data  string
+	URI   string
+}
+
+func MessageAction(label, text string) Action {
+	return Action{Type: ActionTypeMessage, Label: label, Text: text}
+}
+
+func PostbackAction(label, data, displayText string) Action {
+	return Action{Type: ActionTypePostback, Label: label, Data: data, Text: displayText}
+}
+
+// MaxQuickReplyItems is the number of quick reply buttons LINE shows per message.
+const MaxQuickReplyItems = 13
+
+// Message is a text message with optional quick reply buttons.
+type Message struct {
+	Text       string
+	QuickReply []Action
+}
+
+func NewTextMessage(text string) Message {
+	return Message{Text: text}
+}
+
+func (m Message) WithQuickReply(actions ...Action) Message {
+	m.QuickReply = append(append([]Action(nil), m.QuickReply...), actions...)
+	return m
+}
+
+type RichMenuArea struct {
+	X      int64
+	Y      int64
+	Width  int64
+	Height int64
+	Action Action
+}
+
+type RichMenu struct {
+	Name        string
+	ChatBarText string
+	Width       int64
+	Height      int64
+	Selected    bool
+	Areas       []RichMenuArea
+}
+
 type LineRepo interface {
 	SendTextMessage(ctx context.Context, userID, message string) error
 	PushMessage(ctx context.Context, userID, message string) error
+	PushMessages(ctx context.Context, userID string, messages ...Message) error
+	// PushMessagesWithRetryKey is idempotent for a given retryKey: a request
+	// already accepted by LINE is not delivered again.
+	PushMessagesWithRetryKey(ctx context.Context, userID, retryKey string, messages ...Message) error
+	ReplyMessage(ctx context.Context, replyToken string, messages ...Message) error
 	SendButtonMessage(ctx context.Context, userID, text, buttonText, buttonURL string) error
+
+	CreateRichMenu(ctx context.Context, menu *RichMenu) (string, error)
+	UploadRichMenuImage(ctx context.Context, richMenuID, contentType string, image io.Reader) error
+	DeleteRichMenu(ctx context.Context, richMenuID string) error
+	SetDefaultRichMenu(ctx context.Context, richMenuID string) error
+	// GetRichMenuIDByAlias returns an empty ID when the alias does not exist.
+	GetRichMenuIDByAlias(ctx context.Context, aliasID string) (string, error)
+	SetRichMenuAlias(ctx context.Context, aliasID, richMenuID string) error
+	LinkRichMenu(ctx context.Context, userID, richMenuID string) error
+	UnlinkRichMenu(ctx context.Context, userID string) error
 }
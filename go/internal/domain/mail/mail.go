@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Providers a user can link their mailbox with.
+const (
+	ProviderGmail   = "gmail"
+	ProviderOutlook = "outlook"
+)
+
+var (
+	// ErrRevokeUnsupported is returned by RevokeToken of providers without
+	// a revocation endpoint.
+	ErrRevokeUnsupported = errors.New("token revocation is not supported")
+	// ErrCursorExpired is returned by GetChanges when the provider no
+	// longer knows the cursor; the sync has to start over.
+	ErrCursorExpired = errors.New("sync cursor expired")
+	// ErrInsufficientScope is returned when the user granted fewer scopes
+	// than the call needs, as mailboxes linked before a scope was added
+	// did. Linking the mailbox again asks for the missing scope.
+	ErrInsufficientScope = errors.New("the grant lacks a required scope")
+)
+
+type Message struct {
+	ID       string
+	ThreadID string
+	From     string
+	To       string
+	Subject  string
+	Snippet  string
+	Date     time.Time
+	// Link opens the message in the web client of the provider.
+	Link string
+}
+
+type ListOptions struct {
+	MaxResults int64
+	PageToken  string
+	UnreadOnly bool
+	// After limits the list to messages received after the time, if set.
+	After time.Time
+}
+
+type MessageList struct {
+	Messages      []*Message
+	NextPageToken string
+}
+
+type Watch struct {
+	// ID identifies the watch for providers that need it to renew or stop
+	// it.
+	ID        string
+	HistoryID uint64
+	// Cursor is where GetChanges continues, for providers that sync
+	// incrementally.
+	Cursor     string
+	Expiration time.Time
+}
+
+// Changes are the unread messages added to a mailbox since a cursor.
+type Changes struct {
+	Messages []*Message
+	// Cursor continues the sync after these messages.
+	Cursor string
+}
+
+// MailProvider reads the mailbox of a user with their OAuth token.
+type MailProvider interface {
+	GetUnreadMessages(ctx context.Context, token *oauth2.Token, maxResults int64) ([]*Message, error)
+	ListMessages(ctx context.Context, token *oauth2.Token, opts ListOptions) (*MessageList, error)
+	GetMessage(ctx context.Context, token *oauth2.Token, messageID string) (*Message, error)
+	MarkAsRead(ctx context.Context, token *oauth2.Token, messageIDs []string) error
+	// WatchMailbox asks the provider to notify flux of new messages. The
+	// watch with watchID, if any, may be renewed instead.
+	WatchMailbox(ctx context.Context, token *oauth2.Token, watchID string) (*Watch, error)
+	StopWatch(ctx context.Context, token *oauth2.Token, watchID string) error
+	// GetChanges returns the unread messages added since cursor. An empty
+	// cursor starts at the current state of the mailbox.
+	GetChanges(ctx context.Context, token *oauth2.Token, cursor string) (*Changes, error)
+	RevokeToken(ctx context.Context, token *oauth2.Token) error
+	// RefreshToken returns a new access token for the refresh token of
+	// token. The other methods use the token they are given as is, so
+	// callers refresh and save expiring tokens first.
+	RefreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error)
+	GetAuthURL(state string) string
+	ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error)
+}
@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/huavcjj/flux/internal/domain/line"
+)
+
+var ErrInvalidRecipient = errors.New("invalid recipient")
+
+// RateLimitError is returned by Send when the channel asks to wait before
+// the next request to recipient.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// Notification is a channel independent notification. Each Notifier renders
+// it in the format of its channel.
+type Notification struct {
+	Title   string `json:"title"`
+	Sender  string `json:"sender,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
+	// Link opens the email in Gmail.
+	Link string `json:"link,omitempty"`
+	// Text is the plain text rendering, used by channels without rich
+	// formatting and as a fallback.
+	Text string `json:"text"`
+	// RetryKey is passed to APIs that deduplicate retried requests. It is
+	// the retry key of the outbox entry and not stored with the message.
+	RetryKey string `json:"-"`
+}
+
+type Notifier interface {
+	// Send delivers n to recipient, whose format depends on the channel.
+	Send(ctx context.Context, recipient string, n Notification) error
+	// ValidateRecipient checks a recipient before a user registers it.
+	ValidateRecipient(recipient string) error
+}
+
+// Responder is implemented by notifiers of chat channels that also take
+// commands. Replies to those commands are sent through it instead of LINE.
+type Responder interface {
+	Respond(ctx context.Context, recipient string, messages ...line.Message) error
+}
+
+// Channel is a destination a user receives notifications on in addition
+// to LINE.
+type Channel struct {
+	UserID  string
+	Channel string
+	// Target is the channel specific destination, e.g. a webhook. It can
+	// grant access to the destination and must not be logged.
+	Target    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// LinkCode is a one-time code that links a chat of a chat channel to the
+// user when it is sent there.
+type LinkCode struct {
+	Code      string
+	Channel   string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+type ChannelRepo interface {
+	ListChannels(ctx context.Context, userID string) ([]Channel, error)
+	// GetChannelByTarget returns the channel registered with target, or nil.
+	// Chat channels use it to find the user of an inbound message.
+	GetChannelByTarget(ctx context.Context, channel, target string) (*Channel, error)
+	// SaveChannel adds the channel or replaces its target.
+	SaveChannel(ctx context.Context, channel *Channel) error
+	DeleteChannel(ctx context.Context, userID, channel string) (bool, error)
+	// CreateLinkCode stores code and drops the codes that have expired.
+	CreateLinkCode(ctx context.Context, code *LinkCode) error
+	// TakeLinkCode deletes the code and returns its user, or an empty string
+	// if the code does not exist or expired before now.
+	TakeLinkCode(ctx context.Context, channel, code string, now time.Time) (string, error)
+}
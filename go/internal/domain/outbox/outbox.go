@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+const (
+	ChannelLine     = "line"
+	ChannelSlack    = "slack"
+	ChannelDiscord  = "discord"
+	ChannelTelegram = "telegram"
+)
+
+type Entry struct {
+	ID uint64
+	// IdempotencyKey identifies the notification, e.g. one per Gmail message.
+	IdempotencyKey string
+	// RetryKey is sent to the delivery API so a retried request is not delivered twice.
+	RetryKey      string
+	UserID        string
+	EmailID       *uint64
+	Channel       string
+	Recipient     string
+	Message       string
+	Status        string
+	Attempts      int
+	LastError     *string
+	TraceParent   *string
+	NextAttemptAt time.Time
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type OutboxRepo interface {
+	// CreateEntry ignores entries whose IdempotencyKey already exists.
+	CreateEntry(ctx context.Context, entry *Entry) error
+	// ClaimDueEntries returns pending entries that are due and leases them
+	// until leaseUntil so that other dispatchers skip them meanwhile.
+	ClaimDueEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]Entry, error)
+	// MarkSent also flags the related email as notified.
+	MarkSent(ctx context.Context, entry *Entry, sentAt time.Time) error
+	Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id uint64, lastError string) error
+	CountEntriesByStatus(ctx context.Context) (map[string]int64, error)
+	// ListRecentEntries returns the newest entries, optionally only those
+	// with the given status.
+	ListRecentEntries(ctx context.Context, status string, limit int) ([]Entry, error)
+	// DeleteFinishedEntries deletes up to limit sent or failed entries of a
+	// user created before the given time.
+	DeleteFinishedEntries(ctx context.Context, userID string, before time.Time, limit int) (int64, error)
+	// ClearSentMessages empties the message of up to limit sent entries.
+	ClearSentMessages(ctx context.Context, limit int) (int64, error)
+}
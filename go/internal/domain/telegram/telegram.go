@@ -0,0 +1,22 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/huavcjj/flux/internal/domain/notifier"
+)
+
+// Callback data of inline keyboard buttons. Message actions send their text
+// as a command, postback actions carry the postback data.
+const (
+	CallbackMessagePrefix  = "m:"
+	CallbackPostbackPrefix = "p:"
+)
+
+// TelegramRepo talks to the Bot API. Recipients are chat IDs.
+type TelegramRepo interface {
+	notifier.Notifier
+	notifier.Responder
+	// AnswerCallbackQuery acknowledges a pressed inline keyboard button.
+	AnswerCallbackQuery(ctx context.Context, callbackQueryID string) error
+}
@@ -4,24 +4,66 @@ import (
 	"context"
 	"time"
 
+	"github.com/huavcjj/flux/internal/domain/mail"
 	"golang.org/x/oauth2"
 )
 
+// User is a LINE user and the mailbox they linked. The token and watch
+// fields belong to MailProvider, which is Gmail unless the user linked
+// another provider. MailWatchID identifies the watch of providers that need
+// it, e.g. an Outlook subscription, and MailSyncCursor is where the next
+// incremental sync starts.
 type User struct {
-	ID                  string
-	LineUserID          string
-	GmailAccessToken    *string
-	GmailRefreshToken   *string
-	GmailTokenExpiresAt *int64
-	IsActive            bool
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	ID                 string
+	LineUserID         string
+	MailProvider       string
+	MailAccessToken    *string
+	MailRefreshToken   *string
+	MailTokenExpiresAt *int64
+	GmailHistoryID     *uint64
+	MailWatchExpiresAt *int64
+	MailWatchID        *string
+	MailSyncCursor     *string
+	EmailRetentionDays *int
+	IsActive           bool
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// ListFilter selects users for listing. Query matches the user ID exactly or
+// part of the LINE user ID; an empty query matches everyone.
+type ListFilter struct {
+	Query  string
+	Limit  int
+	Offset int
 }
 
 type UserRepo interface {
 	CreateUser(ctx context.Context, user *User) error
 	GetUserByLineUserID(ctx context.Context, lineUserID string) (*User, error)
-	UpdateGmailTokens(ctx context.Context, lineUserID string, token *oauth2.Token) error
+	// UpdateMailTokens links the mailbox of provider, or unlinks it with an
+	// empty token. The watch of the previous link is forgotten.
+	UpdateMailTokens(ctx context.Context, lineUserID, provider string, token *oauth2.Token) error
+	// UpdateRefreshedMailTokens saves the refreshed token of the linked
+	// mailbox, unless the user has linked another provider since.
+	UpdateRefreshedMailTokens(ctx context.Context, userID, provider string, token *oauth2.Token) error
 	GetUserByID(ctx context.Context, userID string) (*User, error)
+	// GetUserByMailWatchID returns nil if no active user has the watch.
+	GetUserByMailWatchID(ctx context.Context, watchID string) (*User, error)
 	GetAllActiveUsers(ctx context.Context) ([]User, error)
+	UpdateMailWatch(ctx context.Context, lineUserID string, watch *mail.Watch) error
+	UpdateMailSyncCursor(ctx context.Context, userID, cursor string) error
+	CountWatchesExpiringBetween(ctx context.Context, from, to time.Time) (int64, error)
+	// GetUsersWithWatchExpiringBefore returns the active linked users whose
+	// watch expires before the time or was never set up.
+	GetUsersWithWatchExpiringBefore(ctx context.Context, before time.Time) ([]User, error)
+	ListUsers(ctx context.Context, filter ListFilter) ([]User, error)
+	// EraseUser deletes the user together with their stored emails,
+	// notifications, notification channels, link codes and queued resyncs
+	// in one transaction.
+	EraseUser(ctx context.Context, userID string) (bool, error)
+	// DeactivateUser reports false when no user has the ID.
+	DeactivateUser(ctx context.Context, userID string) (bool, error)
+	// UpdateEmailRetention sets the retention override; nil restores the default.
+	UpdateEmailRetention(ctx context.Context, lineUserID string, days *int) error
 }
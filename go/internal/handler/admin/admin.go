@@ -0,0 +1,423 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/service/admin"
+	"github.com/huavcjj/flux/internal/service/audit"
+	"github.com/huavcjj/flux/internal/service/hook"
+	"github.com/huavcjj/flux/internal/service/notification"
+)
+
+type Config struct {
+	// Token is the static bearer token accepted by the API.
+	Token string
+	// AllowClientCert accepts requests authenticated by a client certificate
+	// that the TLS server already verified.
+	AllowClientCert bool
+}
+
+type AdminHandler struct {
+	adminService *admin.Service
+	auditService *audit.Service
+	hookService  *hook.Service
+	cfg          Config
+}
+
+func NewAdminHandler(adminService *admin.Service, auditService *audit.Service, hookService *hook.Service, cfg Config) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		auditService: auditService,
+		hookService:  hookService,
+		cfg:          cfg,
+	}
+}
+
+// Routes returns the /admin API. Every route requires authentication.
+func (h *AdminHandler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/users", h.listUsers)
+	mux.HandleFunc("GET /admin/users/{id}", h.getUser)
+	mux.HandleFunc("POST /admin/users/{id}/resync", h.resyncUser)
+	mux.HandleFunc("POST /admin/users/{id}/watch", h.rewatchUser)
+	mux.HandleFunc("POST /admin/users/{id}/deactivate", h.deactivateUser)
+	mux.HandleFunc("GET /admin/users/{id}/audit", h.listAuditEvents)
+	mux.HandleFunc("GET /admin/users/{id}/audit/export", h.exportAuditEvents)
+	mux.HandleFunc("GET /admin/users/{id}/webhook", h.getWebhook)
+	mux.HandleFunc("PUT /admin/users/{id}/webhook", h.putWebhook)
+	mux.HandleFunc("DELETE /admin/users/{id}/webhook", h.deleteWebhook)
+	mux.HandleFunc("GET /admin/users/{id}/webhook/deliveries", h.listWebhookDeliveries)
+	mux.HandleFunc("POST /admin/users/{id}/webhook/deliveries/{delivery}/redeliver", h.redeliverWebhook)
+	mux.HandleFunc("GET /admin/notifications", h.listNotifications)
+	mux.HandleFunc("GET /admin/jobs", h.listJobs)
+	mux.HandleFunc("POST /admin/jobs/{id}/replay", h.replayJob)
+
+	return h.authenticate(mux)
+}
+
+// Protect requires the admin authentication for next, which is served
+// outside /admin/.
+func (h *AdminHandler) Protect(next http.Handler) http.Handler {
+	return h.authenticate(next)
+}
+
+type actorKey struct{}
+
+func (h *AdminHandler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithRequestClient(r.Context(), r)
+
+		actor, ok := h.actor(r)
+		if !ok {
+			// Anyone who reaches the port can fail to authenticate, so
+			// denials only go to the application log; the audit trail
+			// records what authenticated admins do.
+			client, _ := auditRepo.ClientFromContext(ctx)
+			slog.Warn("admin authentication failed", "method", r.Method, "path", r.URL.Path, "ip", client.IPAddress, "user_agent", client.UserAgent)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="flux-admin"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, actorKey{}, actor)))
+	})
+}
+
+func (h *AdminHandler) actor(r *http.Request) (string, bool) {
+	if h.cfg.AllowClientCert && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName, true
+	}
+
+	if h.cfg.Token == "" {
+		return "", false
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.Token)) != 1 {
+		return "", false
+	}
+	return "token", true
+}
+
+// record writes the audit event of an admin action. The subject of users.*
+// and audit.* actions is a user; other subjects are kept as the detail.
+func (h *AdminHandler) record(r *http.Request, action, subject string, err error) {
+	actor, _ := r.Context().Value(actorKey{}).(string)
+	event := auditRepo.Event{
+		Actor:  auditRepo.ActorAdminPrefix + actor,
+		Action: auditRepo.ActionAdminPrefix + action,
+		Result: auditRepo.ResultSuccess,
+	}
+	if subject != "" {
+		if strings.HasPrefix(action, "users.") || strings.HasPrefix(action, "audit.") {
+			event.SubjectUserID = &subject
+		} else {
+			event.Detail = &subject
+		}
+	}
+	if err != nil {
+		event.Result = auditRepo.ResultFailure
+	}
+	h.auditService.Log(r.Context(), event)
+}
+
+type userResponse struct {
+	ID             string     `json:"id"`
+	LineUserID     string     `json:"line_user_id"`
+	Active         bool       `json:"active"`
+	GmailLinked    bool       `json:"gmail_linked"`
+	MailProvider   string     `json:"mail_provider,omitempty"`
+	WatchExpiresAt *time.Time `json:"watch_expires_at,omitempty"`
+	HistoryID      *uint64    `json:"history_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func toUserResponse(u *userRepo.User) userResponse {
+	res := userResponse{
+		ID:         u.ID,
+		LineUserID: u.LineUserID,
+		Active:     u.IsActive,
+		HistoryID:  u.GmailHistoryID,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+	}
+	if u.MailAccessToken != nil && *u.MailAccessToken != "" {
+		res.GmailLinked = u.MailProvider == mailRepo.ProviderGmail
+		res.MailProvider = u.MailProvider
+	}
+	if u.MailWatchExpiresAt != nil {
+		expiresAt := time.Unix(*u.MailWatchExpiresAt, 0)
+		res.WatchExpiresAt = &expiresAt
+	}
+	return res
+}
+
+type notificationResponse struct {
+	ID             uint64     `json:"id"`
+	IdempotencyKey string     `json:"idempotency_key"`
+	UserID         string     `json:"user_id"`
+	Channel        string     `json:"channel"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastError      *string    `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func toNotificationResponse(e *outboxRepo.Entry) notificationResponse {
+	return notificationResponse{
+		ID:             e.ID,
+		IdempotencyKey: e.IdempotencyKey,
+		UserID:         e.UserID,
+		Channel:        e.Channel,
+		Status:         e.Status,
+		Attempts:       e.Attempts,
+		LastError:      e.LastError,
+		NextAttemptAt:  e.NextAttemptAt,
+		SentAt:         e.SentAt,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+type jobResponse struct {
+	ID          uint64          `json:"id"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	Payload     json.RawMessage `json:"payload"`
+	LastError   *string         `json:"last_error,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func toJobResponse(j *jobRepo.Job) jobResponse {
+	return jobResponse{
+		ID:          j.ID,
+		Type:        j.Type,
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		Payload:     json.RawMessage(j.Payload),
+		LastError:   j.LastError,
+		RunAt:       j.RunAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+}
+
+func (h *AdminHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	users, err := h.adminService.ListUsers(r.Context(), userRepo.ListFilter{
+		Query:  q.Get("q"),
+		Limit:  queryInt(q.Get("limit")),
+		Offset: queryInt(q.Get("offset")),
+	})
+	h.record(r, "users.list", q.Get("q"), err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := make([]userResponse, 0, len(users))
+	for i := range users {
+		res = append(res, toUserResponse(&users[i]))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *AdminHandler) getUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	user, err := h.adminService.GetUser(r.Context(), id)
+	h.record(r, "users.get", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(user))
+}
+
+func (h *AdminHandler) resyncUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	err := h.adminService.ResyncUser(r.Context(), id)
+	h.record(r, "users.resync", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
+
+func (h *AdminHandler) rewatchUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	watch, err := h.adminService.RewatchUser(r.Context(), id)
+	h.record(r, "users.watch", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"history_id": watch.HistoryID,
+		"expires_at": watch.Expiration,
+	})
+}
+
+func (h *AdminHandler) deactivateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	err := h.adminService.DeactivateUser(r.Context(), id)
+	h.record(r, "users.deactivate", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deactivated"})
+}
+
+func (h *AdminHandler) listNotifications(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	entries, err := h.adminService.ListNotifications(r.Context(), q.Get("status"), queryInt(q.Get("limit")))
+	h.record(r, "notifications.list", q.Get("status"), err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := make([]notificationResponse, 0, len(entries))
+	for i := range entries {
+		res = append(res, toNotificationResponse(&entries[i]))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *AdminHandler) listJobs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	jobs, err := h.adminService.ListJobs(r.Context(), q.Get("status"), queryInt(q.Get("limit")))
+	h.record(r, "jobs.list", q.Get("status"), err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := make([]jobResponse, 0, len(jobs))
+	for i := range jobs {
+		res = append(res, toJobResponse(&jobs[i]))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *AdminHandler) replayJob(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	err = h.adminService.ReplayJob(r.Context(), id)
+	h.record(r, "jobs.replay", idStr, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "pending"})
+}
+
+func (h *AdminHandler) listAuditEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	q := r.URL.Query()
+	before, err := queryUint(q.Get("before"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid before")
+		return
+	}
+
+	events, err := h.auditService.ListEvents(r.Context(), id, before, queryInt(q.Get("limit")))
+	h.record(r, "audit.list", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := make([]audit.Record, 0, len(events))
+	for i := range events {
+		res = append(res, audit.NewRecord(&events[i]))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// exportAuditEvents streams every event of the user as JSON lines.
+func (h *AdminHandler) exportAuditEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	written, err := h.auditService.ExportJSONL(r.Context(), id, w)
+	h.record(r, "audit.export", id, err)
+	if err != nil {
+		if written == 0 {
+			writeServiceError(w, err)
+			return
+		}
+		// The status line is already sent, so the error can only be
+		// logged; the client sees a truncated stream.
+		slog.Error("failed to export audit events", "error", err)
+	}
+}
+
+func queryUint(v string) (uint64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
+
+func queryInt(v string) int {
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, admin.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, notification.ErrNotLinked):
+		writeError(w, http.StatusConflict, "user has not linked a mailbox")
+	case errors.Is(err, hook.ErrNotFound):
+		writeError(w, http.StatusNotFound, "webhook not found")
+	case errors.Is(err, hook.ErrDisabled):
+		writeError(w, http.StatusConflict, "webhooks are disabled")
+	case errors.Is(err, hook.ErrPayloadPruned):
+		writeError(w, http.StatusGone, "delivery payload was pruned")
+	case errors.Is(err, hookRepo.ErrInvalidURL):
+		writeError(w, http.StatusBadRequest, "url must be a public https url")
+	default:
+		slog.Error("admin request failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write admin response", "error", err)
+	}
+}
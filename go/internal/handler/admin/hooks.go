@@ -0,0 +1,164 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
+)
+
+// maxWebhookRequest bounds the body of PUT /admin/users/{id}/webhook.
+const maxWebhookRequest = 4 << 10
+
+type webhookRequest struct {
+	URL string `json:"url"`
+}
+
+type webhookResponse struct {
+	ID     uint64 `json:"id"`
+	UserID string `json:"user_id"`
+	URL    string `json:"url"`
+	// Secret is only returned when the endpoint is registered.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toWebhookResponse(e *hookRepo.Endpoint) webhookResponse {
+	return webhookResponse{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		URL:       e.URL,
+		CreatedBy: e.CreatedBy,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+type webhookDeliveryResponse struct {
+	ID             uint64          `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	RedeliveryOf   *uint64         `json:"redelivery_of,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func toWebhookDeliveryResponse(d *hookRepo.Delivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		RedeliveryOf:   d.RedeliveryOf,
+		Payload:        json.RawMessage(d.Payload),
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+func (h *AdminHandler) getWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	endpoint, err := h.hookService.GetEndpoint(r.Context(), id)
+	h.record(r, "users.webhook.get", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toWebhookResponse(endpoint))
+}
+
+// putWebhook registers the endpoint of the user, replacing any previous one
+// and its secret.
+func (h *AdminHandler) putWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req webhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookRequest)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	actor, _ := r.Context().Value(actorKey{}).(string)
+	endpoint, err := h.registerWebhook(r, id, req.URL, actor)
+	h.record(r, "users.webhook.put", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := toWebhookResponse(endpoint)
+	res.Secret = endpoint.Secret
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *AdminHandler) registerWebhook(r *http.Request, userID, rawURL, actor string) (*hookRepo.Endpoint, error) {
+	if _, err := h.adminService.GetUser(r.Context(), userID); err != nil {
+		return nil, err
+	}
+	return h.hookService.RegisterEndpoint(r.Context(), userID, rawURL, auditRepo.ActorAdminPrefix+actor)
+}
+
+func (h *AdminHandler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	err := h.hookService.RemoveEndpoint(r.Context(), id)
+	h.record(r, "users.webhook.delete", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (h *AdminHandler) listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	q := r.URL.Query()
+	before, err := queryUint(q.Get("before"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid before")
+		return
+	}
+
+	deliveries, err := h.hookService.ListDeliveries(r.Context(), id, before, queryInt(q.Get("limit")))
+	h.record(r, "users.webhook.deliveries", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		res = append(res, toWebhookDeliveryResponse(&deliveries[i]))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *AdminHandler) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deliveryID, err := strconv.ParseUint(r.PathValue("delivery"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	delivery, err := h.hookService.Redeliver(r.Context(), id, deliveryID)
+	h.record(r, "users.webhook.redeliver", id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"id": delivery.ID, "status": delivery.Status})
+}
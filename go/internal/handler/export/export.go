@@ -0,0 +1,69 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	"github.com/huavcjj/flux/internal/service/audit"
+	"github.com/huavcjj/flux/internal/service/notification"
+)
+
+type ExportHandler struct {
+	notificationService *notification.Service
+	auditRepo           auditRepo.AuditLogger
+}
+
+func NewExportHandler(notificationService *notification.Service, auditRepo auditRepo.AuditLogger) *ExportHandler {
+	return &ExportHandler{
+		notificationService: notificationService,
+		auditRepo:           auditRepo,
+	}
+}
+
+// HandleDownload serves the ZIP behind a signed data export link.
+func (h *ExportHandler) HandleDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := audit.WithRequestClient(r.Context(), r)
+
+	userID, err := h.notificationService.VerifyExportLink(r.URL.Query(), time.Now())
+	if err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, notification.ErrExportLinkExpired) {
+			status = http.StatusGone
+		}
+		// The subject is unverified, so it is not recorded.
+		detail := err.Error()
+		h.auditRepo.Log(ctx, auditRepo.Event{
+			Actor:  auditRepo.ActorUser,
+			Action: auditRepo.ActionExport,
+			Result: auditRepo.ResultDenied,
+			Detail: &detail,
+		})
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="flux-export-%s.zip"`, time.Now().Format("20060102")))
+	w.Header().Set("Cache-Control", "no-store")
+
+	// Nothing is written before the user is found, so that case can still
+	// get a status. Later failures can only be logged; the truncated
+	// archive fails to open.
+	if err := h.notificationService.WriteDataExport(ctx, userID, w); err != nil {
+		if errors.Is(err, notification.ErrUserNotFound) {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.Error("failed to write data export", "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	"github.com/huavcjj/flux/internal/service/audit"
+	"github.com/huavcjj/flux/internal/service/notification"
+)
+
+const (
+	htmlError   = `<html><body><h1>❌ 認証失敗</h1></body></html>`
+	htmlSuccess = `<html><body><h1>✅ 認証完了</h1></body></html>`
+)
+
+// OAuthHandler completes the mail auth of a provider from its redirect.
+type OAuthHandler struct {
+	notificationService *notification.Service
+	auditRepo           auditRepo.AuditLogger
+	provider            string
+	linkAction          string
+}
+
+func NewGmailOAuthHandler(notificationService *notification.Service, auditLogger auditRepo.AuditLogger) *OAuthHandler {
+	return &OAuthHandler{
+		notificationService: notificationService,
+		auditRepo:           auditLogger,
+		provider:            mailRepo.ProviderGmail,
+		linkAction:          auditRepo.ActionGmailLink,
+	}
+}
+
+func NewOutlookOAuthHandler(notificationService *notification.Service, auditLogger auditRepo.AuditLogger) *OAuthHandler {
+	return &OAuthHandler{
+		notificationService: notificationService,
+		auditRepo:           auditLogger,
+		provider:            mailRepo.ProviderOutlook,
+		linkAction:          auditRepo.ActionOutlookLink,
+	}
+}
+
+func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+
+	// The exchange outlives a client that disconnects early.
+	ctx := audit.WithRequestClient(context.WithoutCancel(r.Context()), r)
+
+	if code == "" || state == "" {
+		slog.Error("missing code or state", "code", code, "state", state)
+		detail := "missing code or state"
+		if errMsg := r.URL.Query().Get("error"); errMsg != "" {
+			detail = "authorization error: " + errMsg
+		}
+		h.auditRepo.Log(ctx, auditRepo.Event{
+			Actor:  auditRepo.ActorUser,
+			Action: h.linkAction,
+			Result: auditRepo.ResultDenied,
+			Detail: &detail,
+		})
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.notificationService.CompleteMailAuth(ctx, state, h.provider, code, lineRepo.ReplyToken{}); err != nil {
+		slog.Error("failed to complete mail auth", "user_id", state, "provider", h.provider, "error", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, htmlError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, htmlSuccess)
+}
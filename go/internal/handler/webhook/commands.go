@@ -0,0 +1,331 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/huavcjj/flux/internal/command"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/service/notification"
+)
+
+const (
+	// maxRetentionDays bounds the retention a user can type.
+	maxRetentionDays = 3650
+
+	// MySQL's ngram parser indexes pairs of characters, so shorter
+	// queries never match.
+	minSearchQuery = 2
+	maxSearchQuery = 50
+
+	// maxWebhookURL is the size of notification_channels.target.
+	maxWebhookURL = 255
+	// maxHookURL is the size of webhook_endpoints.url.
+	maxHookURL = 2048
+)
+
+// newCommandRouter registers the text commands understood by the bot. Adding
+// a command only requires another Register call here.
+func newCommandRouter(service *notification.Service, mailListLimit, mailListMaxLimit int) *command.Router {
+	router := command.NewRouter(service, notification.CommandHelp, "help", "使い方", "?")
+	requireMailLink := requireMailLinkMiddleware(service)
+
+	router.Register(&command.Command{
+		Name:        notification.CommandGmailAuth,
+		Aliases:     []string{"連携", "link"},
+		Description: "Gmailアカウントを連携します",
+		ParseArgs:   command.NoArgs,
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.StartMailAuth(ctx, req.UserID, mailRepo.ProviderGmail, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandOutlookAuth,
+		Aliases:     []string{"outlook"},
+		Description: "Microsoftアカウント (Outlook) を連携します",
+		ParseArgs:   command.NoArgs,
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.StartMailAuth(ctx, req.UserID, mailRepo.ProviderOutlook, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandUnreadMail,
+		Aliases:     []string{"未読", "未読メール", "unread"},
+		Description: "未読メールを表示します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.SendUnreadEmailList(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandMailList,
+		Aliases:     []string{"一覧", "メール一覧", "list"},
+		Usage:       notification.CommandMailList + " [件数]",
+		Description: fmt.Sprintf("最新メールを表示します (件数は1〜%d、既定%d)", mailListMaxLimit, mailListLimit),
+		ParseArgs:   command.OptionalInt(mailListLimit, 1, mailListMaxLimit),
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.SendEmailList(ctx, req.UserID, int64(req.Args.(int)), req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandMailUnlink,
+		Aliases:     []string{"連携解除", "unlink"},
+		Description: "連携中のメールアカウントの連携を解除します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.UnlinkMail(ctx, req.UserID, "", req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandGmailUnlink,
+		Description: "Gmail連携を解除します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.UnlinkMail(ctx, req.UserID, mailRepo.ProviderGmail, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandOutlookUnlink,
+		Description: "Outlook連携を解除します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.UnlinkMail(ctx, req.UserID, mailRepo.ProviderOutlook, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandSearch,
+		Aliases:     []string{"search", "メール検索"},
+		Usage:       notification.CommandSearch + " キーワード",
+		Description: "保存済みメールを差出人・件名・本文で検索します",
+		ParseArgs:   command.Text(minSearchQuery, maxSearchQuery),
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.SearchStoredEmails(ctx, req.UserID, req.Args.(string), "", req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandRetention,
+		Aliases:     []string{"retention"},
+		Usage:       notification.CommandRetention + " [日数|既定]",
+		Description: "保存済みメールの保存期間を表示・変更します",
+		ParseArgs:   parseRetentionArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			args := req.Args.(retentionArgs)
+			if args.show {
+				return service.SendEmailRetention(ctx, req.UserID, req.ReplyToken)
+			}
+			return service.SetEmailRetention(ctx, req.UserID, args.days, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandChannels,
+		Aliases:     []string{"channels"},
+		Description: "新着メールの通知先を表示します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.SendChannels(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandSlackLink,
+		Aliases:     []string{"slack"},
+		Usage:       notification.CommandSlackLink + " <Incoming Webhook URL>",
+		Description: "新着メールをSlackにも通知します",
+		ParseArgs:   command.Text(1, maxWebhookURL),
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.AddChannel(ctx, req.UserID, outboxRepo.ChannelSlack, req.Args.(string), req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandSlackUnlink,
+		Description: "Slackへの通知を停止します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.RemoveChannel(ctx, req.UserID, outboxRepo.ChannelSlack, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandDiscordLink,
+		Aliases:     []string{"discord"},
+		Usage:       notification.CommandDiscordLink + " <Webhook URL>",
+		Description: "新着メールをDiscordにも通知します",
+		ParseArgs:   command.Text(1, maxWebhookURL),
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.AddChannel(ctx, req.UserID, outboxRepo.ChannelDiscord, req.Args.(string), req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandDiscordUnlink,
+		Description: "Discordへの通知を停止します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.RemoveChannel(ctx, req.UserID, outboxRepo.ChannelDiscord, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandTelegramLink,
+		Aliases:     []string{"telegram"},
+		Description: "Telegramでも通知を受け取り、コマンドを使えるようにします",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.StartTelegramLink(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandTelegramUnlink,
+		Description: "Telegram連携を解除します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.RemoveChannel(ctx, req.UserID, outboxRepo.ChannelTelegram, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandHookRegister,
+		Usage:       notification.CommandHookRegister + " <https://...>",
+		Description: "新着メールなどのイベントを指定URLに送信します",
+		ParseArgs:   command.Text(1, maxHookURL),
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.RegisterWebhook(ctx, req.UserID, req.Args.(string), req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandHookRemove,
+		Description: "Webhookの登録を解除します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.RemoveWebhook(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandHookHistory,
+		Description: "Webhookの送信履歴を表示します",
+		ParseArgs:   command.NoArgs,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.SendWebhookDeliveries(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandHookRedeliver,
+		Usage:       notification.CommandHookRedeliver + " 番号",
+		Description: "Webhookの送信をやり直します",
+		ParseArgs:   parseDeliveryID,
+		Middleware:  []command.Middleware{requireMailLink},
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.RedeliverWebhook(ctx, req.UserID, req.Args.(uint64), req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandDataExport,
+		Aliases:     []string{"export"},
+		Description: "保存されているデータをダウンロードします",
+		ParseArgs:   command.NoArgs,
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.SendDataExportLink(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	router.Register(&command.Command{
+		Name:        notification.CommandDataErase,
+		Aliases:     []string{"erase"},
+		Description: "保存されているすべてのデータを削除します",
+		ParseArgs:   command.NoArgs,
+		Handler: func(ctx context.Context, req *command.Request) error {
+			return service.ConfirmDataErasure(ctx, req.UserID, req.ReplyToken)
+		},
+	})
+
+	return router
+}
+
+// retentionArgs shows the retention when show is set, and otherwise sets it
+// to days, where nil restores the default.
+type retentionArgs struct {
+	show bool
+	days *int
+}
+
+func parseRetentionArgs(args []string) (any, error) {
+	switch {
+	case len(args) == 0:
+		return retentionArgs{show: true}, nil
+	case len(args) > 1:
+		return nil, command.ErrUsage
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "既定", "default", "reset":
+		return retentionArgs{}, nil
+	}
+
+	days, err := strconv.Atoi(strings.TrimSuffix(args[0], "日"))
+	if err != nil || days < 1 || days > maxRetentionDays {
+		return nil, command.ErrUsage
+	}
+	return retentionArgs{days: &days}, nil
+}
+
+// parseDeliveryID accepts a delivery number as shown in the history, with
+// or without its "#".
+func parseDeliveryID(args []string) (any, error) {
+	if len(args) != 1 {
+		return nil, command.ErrUsage
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil || id == 0 {
+		return nil, command.ErrUsage
+	}
+	return id, nil
+}
+
+func requireMailLinkMiddleware(service *notification.Service) command.Middleware {
+	return func(next command.HandlerFunc) command.HandlerFunc {
+		return func(ctx context.Context, req *command.Request) error {
+			linked, err := service.IsMailLinked(ctx, req.UserID)
+			if err != nil {
+				return err
+			}
+			if !linked {
+				return service.SendAuthRequired(ctx, req.UserID, req.ReplyToken)
+			}
+			return next(ctx, req)
+		}
+	}
+}
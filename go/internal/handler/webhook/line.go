@@ -6,29 +6,49 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/huavcjj/flux/internal/command"
+	eventRepo "github.com/huavcjj/flux/internal/domain/event"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
 	"github.com/huavcjj/flux/internal/service/notification"
+	"github.com/huavcjj/flux/internal/tracing"
 	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
-	cmdGmailAuth  = "Gmail連携"
-	cmdUnreadMail = "未読mail"
-	cmdMailList   = "mail一覧"
-	mailListLimit = 10
+	webhookEventTTL = 24 * time.Hour
+	// replyTokenTTL keeps a safety margin below the one minute LINE allows
+	// between an event and the reply to it, counted from the event
+	// timestamp.
+	replyTokenTTL = 50 * time.Second
 )
 
 type LineWebhookHandler struct {
 	notificationService *notification.Service
+	eventRepo           eventRepo.EventRepo
+	router              *command.Router
 	channelSecret       string
 }
 
-func NewLineWebhookHandler(notificationService *notification.Service) *LineWebhookHandler {
+type LineWebhookConfig struct {
+	ChannelSecret string
+	// MailListLimit is the default count of the mail list command and
+	// MailListMaxLimit the largest count a user may ask for.
+	MailListLimit    int
+	MailListMaxLimit int
+}
+
+func NewLineWebhookHandler(notificationService *notification.Service, eventRepo eventRepo.EventRepo, cfg LineWebhookConfig) *LineWebhookHandler {
 	return &LineWebhookHandler{
 		notificationService: notificationService,
-		channelSecret:       os.Getenv("LINE_CHANNEL_SECRET"),
+		eventRepo:           eventRepo,
+		router:              newCommandRouter(notificationService, cfg.MailListLimit, cfg.MailListMaxLimit),
+		channelSecret:       cfg.ChannelSecret,
 	}
 }
 
@@ -52,8 +72,11 @@ func (h *LineWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Reques
 	}
 
 	for _, event := range cb.Events {
-		if msgEvent, ok := event.(webhook.MessageEvent); ok {
-			h.handleMessageEvent(r.Context(), msgEvent)
+		switch e := event.(type) {
+		case webhook.MessageEvent:
+			h.handleMessageEvent(r.Context(), e)
+		case webhook.PostbackEvent:
+			h.handlePostbackEvent(r.Context(), e)
 		}
 	}
 
@@ -67,13 +90,116 @@ func (h *LineWebhookHandler) handleMessageEvent(ctx context.Context, event webho
 		return
 	}
 
+	if !h.markEventProcessed(ctx, event.WebhookEventId, event.DeliveryContext) {
+		return
+	}
+
+	userID := h.extractUserID(event.Source)
+	if userID == "" {
+		slog.Error("could not extract user ID from source")
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "line.event.message", tracing.UserID(userID), attribute.String("line.webhook_event_id", event.WebhookEventId))
+	defer span.End()
+
+	h.processTextMessage(ctx, userID, textMsg.Text, newReplyToken(event.ReplyToken, event.Timestamp))
+}
+
+func (h *LineWebhookHandler) handlePostbackEvent(ctx context.Context, event webhook.PostbackEvent) {
+	if event.Postback == nil {
+		return
+	}
+
+	if !h.markEventProcessed(ctx, event.WebhookEventId, event.DeliveryContext) {
+		return
+	}
+
 	userID := h.extractUserID(event.Source)
 	if userID == "" {
 		slog.Error("could not extract user ID from source")
 		return
 	}
 
-	h.processTextMessage(ctx, userID, textMsg.Text)
+	ctx, span := tracing.Start(ctx, "line.event.postback", tracing.UserID(userID), attribute.String("line.webhook_event_id", event.WebhookEventId))
+	defer span.End()
+
+	data, err := url.ParseQuery(event.Postback.Data)
+	if err != nil {
+		slog.Error("failed to parse postback data", "user_id", userID, "error", err)
+		return
+	}
+
+	replyToken := newReplyToken(event.ReplyToken, event.Timestamp)
+	action := data.Get("action")
+	span.SetAttributes(attribute.String("line.postback_action", action))
+	slog.Info("received postback", "user_id", userID, "action", action)
+
+	if err := dispatchPostback(ctx, h.notificationService, userID, data, replyToken); err != nil {
+		tracing.RecordError(span, err)
+		slog.Error("failed to process postback", "user_id", userID, "action", action, "error", err)
+	}
+}
+
+// dispatchPostback runs the action of postback data. It serves the postback
+// buttons of every chat channel.
+func dispatchPostback(ctx context.Context, service *notification.Service, userID string, data url.Values, replyToken lineRepo.ReplyToken) error {
+	switch data.Get("action") {
+	case notification.PostbackActionMailList:
+		limit, _ := strconv.ParseInt(data.Get("limit"), 10, 64)
+		return service.SendMoreEmails(ctx, userID, data.Get("unread") == "1", data.Get("page"), limit, replyToken)
+	case notification.PostbackActionMarkRead:
+		var ids []string
+		if v := data.Get("ids"); v != "" {
+			ids = strings.Split(v, ",")
+		}
+		return service.MarkAsRead(ctx, userID, ids, replyToken)
+	case notification.PostbackActionStoredList:
+		limit, _ := strconv.ParseInt(data.Get("limit"), 10, 64)
+		return service.SendMoreStoredEmails(ctx, userID, data.Get("after"), limit, replyToken)
+	case notification.PostbackActionErase:
+		at, _ := strconv.ParseInt(data.Get("at"), 10, 64)
+		return service.EraseUserData(ctx, userID, time.Unix(at, 0), replyToken)
+	case notification.PostbackActionSearch:
+		return service.SearchStoredEmails(ctx, userID, data.Get("q"), data.Get("after"), replyToken)
+	}
+	return nil
+}
+
+// newReplyToken expires the reply token of an event that occurred at
+// timestamp, in Unix milliseconds. LINE starts the deadline at the event,
+// so a redelivered or delayed event may arrive with its token expired.
+func newReplyToken(token string, timestamp int64) lineRepo.ReplyToken {
+	return lineRepo.ReplyToken{
+		Token:     token,
+		ExpiresAt: time.UnixMilli(timestamp).Add(replyTokenTTL),
+	}
+}
+
+// markEventProcessed reports whether the event should be handled. LINE may
+// redeliver the same event, so already processed IDs are skipped.
+func (h *LineWebhookHandler) markEventProcessed(ctx context.Context, eventID string, deliveryContext *webhook.DeliveryContext) bool {
+	isRedelivery := deliveryContext != nil && deliveryContext.IsRedelivery
+	if isRedelivery {
+		slog.Info("received redelivered webhook event", "webhook_event_id", eventID)
+	}
+
+	if eventID == "" || h.eventRepo == nil {
+		return true
+	}
+
+	first, err := h.eventRepo.MarkProcessed(ctx, eventID, webhookEventTTL)
+	if err != nil {
+		slog.Error("failed to record webhook event", "webhook_event_id", eventID, "error", err)
+		return true
+	}
+
+	if !first {
+		slog.Info("skipping duplicate webhook event", "webhook_event_id", eventID, "is_redelivery", isRedelivery)
+		return false
+	}
+
+	return true
 }
 
 func (h *LineWebhookHandler) extractUserID(source webhook.SourceInterface) string {
@@ -87,32 +213,22 @@ func (h *LineWebhookHandler) extractUserID(source webhook.SourceInterface) strin
 	return userID
 }
 
-func (h *LineWebhookHandler) processTextMessage(ctx context.Context, userID, text string) {
+func (h *LineWebhookHandler) processTextMessage(ctx context.Context, userID, text string, replyToken lineRepo.ReplyToken) {
 	text = strings.TrimSpace(text)
 	slog.Info("received text message", "user_id", userID, "text", text)
 
 	if h.notificationService.IsAuthPending(userID) {
-		h.handleAuthCode(ctx, userID, text)
+		h.handleAuthCode(ctx, userID, text, replyToken)
 		return
 	}
 
-	var err error
-	switch text {
-	case cmdGmailAuth:
-		err = h.notificationService.StartGmailAuth(ctx, userID)
-	case cmdUnreadMail:
-		err = h.notificationService.SendUnreadEmailList(ctx, userID)
-	case cmdMailList:
-		err = h.notificationService.SendEmailList(ctx, userID, mailListLimit)
-	}
-
-	if err != nil {
+	if err := h.router.Dispatch(ctx, userID, text, replyToken); err != nil {
 		slog.Error("failed to process text message", "user_id", userID, "text", text, "error", err)
 	}
 }
 
-func (h *LineWebhookHandler) handleAuthCode(ctx context.Context, userID, code string) {
-	if err := h.notificationService.CompleteGmailAuth(ctx, userID, code); err != nil {
-		slog.Error("failed to complete Gmail auth", "user_id", userID, "error", err)
+func (h *LineWebhookHandler) handleAuthCode(ctx context.Context, userID, code string, replyToken lineRepo.ReplyToken) {
+	if err := h.notificationService.CompletePendingAuth(ctx, userID, code, replyToken); err != nil {
+		slog.Error("failed to complete mail auth", "user_id", userID, "error", err)
 	}
 }
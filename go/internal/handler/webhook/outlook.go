@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	"github.com/huavcjj/flux/internal/service/queue"
+)
+
+const maxOutlookNotification = 1 << 20
+
+// OutlookNotification is a batch of Microsoft Graph change or lifecycle
+// notifications.
+type OutlookNotification struct {
+	Value []struct {
+		SubscriptionID string `json:"subscriptionId"`
+		ClientState    string `json:"clientState"`
+		ChangeType     string `json:"changeType"`
+		Resource       string `json:"resource"`
+		// LifecycleEvent is only set on lifecycle notifications.
+		LifecycleEvent string `json:"lifecycleEvent"`
+	} `json:"value"`
+}
+
+type OutlookWebhookHandler struct {
+	queueService *queue.Service
+	clientState  string
+}
+
+// NewOutlookWebhookHandler accepts the change notifications of the mail
+// subscriptions created with clientState.
+func NewOutlookWebhookHandler(queueService *queue.Service, clientState string) *OutlookWebhookHandler {
+	return &OutlookWebhookHandler{
+		queueService: queueService,
+		clientState:  clientState,
+	}
+}
+
+// HandleWebhook only enqueues the notifications, since Graph expects an
+// answer within a few seconds; the job workers do the Graph and LINE calls.
+func (h *OutlookWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Graph validates the endpoint of a new subscription by asking for the
+	// token back.
+	if token := r.URL.Query().Get("validationToken"); token != "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(token))
+		return
+	}
+
+	var batch OutlookNotification
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxOutlookNotification)).Decode(&batch); err != nil {
+		slog.Error("failed to decode Outlook notification", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	for _, n := range batch.Value {
+		if subtle.ConstantTimeCompare([]byte(n.ClientState), []byte(h.clientState)) != 1 {
+			slog.Warn("rejected Outlook notification with invalid client state", "subscription_id", n.SubscriptionID)
+			continue
+		}
+
+		jobType, dedupKey, payload := outlookJob(n.SubscriptionID, n.ChangeType, n.Resource, n.LifecycleEvent)
+		if jobType == "" {
+			slog.Info("ignoring Outlook lifecycle notification", "subscription_id", n.SubscriptionID, "lifecycle_event", n.LifecycleEvent)
+			continue
+		}
+		if err := h.queueService.Enqueue(r.Context(), jobType, dedupKey, payload); err != nil {
+			slog.Error("failed to enqueue Outlook notification", "subscription_id", n.SubscriptionID, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// outlookJob returns the job handling a notification, or an empty type for
+// lifecycle events that need none.
+func outlookJob(subscriptionID, changeType, resource, lifecycleEvent string) (string, string, any) {
+	switch lifecycleEvent {
+	case "":
+		slog.Info("received Outlook notification", "subscription_id", subscriptionID, "change_type", changeType)
+		// Resources hold long message IDs, so they are hashed to fit the
+		// dedup key.
+		sum := sha256.Sum256([]byte(resource))
+		return jobRepo.TypeOutlookPush, "outlook:" + subscriptionID + ":" + hex.EncodeToString(sum[:]),
+			jobRepo.OutlookPushPayload{SubscriptionID: subscriptionID}
+	case "missed":
+		// Graph dropped change notifications; the sync catches up on them.
+		slog.Warn("Outlook notifications were missed", "subscription_id", subscriptionID)
+		return jobRepo.TypeOutlookPush, "outlook:" + subscriptionID + ":missed:" + strconv.FormatInt(time.Now().Unix(), 10),
+			jobRepo.OutlookPushPayload{SubscriptionID: subscriptionID}
+	case "reauthorizationRequired", "subscriptionRemoved":
+		// Renewing reauthorizes the subscription, or creates a new one if
+		// Graph removed it.
+		slog.Warn("Outlook subscription needs renewal", "subscription_id", subscriptionID, "lifecycle_event", lifecycleEvent)
+		return jobRepo.TypeWatchRenew, "watch-renew:" + subscriptionID + ":" + strconv.FormatInt(time.Now().Unix(), 10),
+			jobRepo.WatchRenewPayload{WatchID: subscriptionID}
+	default:
+		return "", "", nil
+	}
+}
@@ -1,13 +1,14 @@
 package webhook
 
 import (
-	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 
-	"github.com/huavcjj/flux/internal/service/notification"
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	"github.com/huavcjj/flux/internal/service/queue"
 )
 
 type PubSubMessage struct {
@@ -21,15 +22,17 @@ type PubSubMessage struct {
 }
 
 type PubSubWebhookHandler struct {
-	notificationService *notification.Service
+	queueService *queue.Service
 }
 
-func NewPubSubWebhookHandler(notificationService *notification.Service) *PubSubWebhookHandler {
+func NewPubSubWebhookHandler(queueService *queue.Service) *PubSubWebhookHandler {
 	return &PubSubWebhookHandler{
-		notificationService: notificationService,
+		queueService: queueService,
 	}
 }
 
+// HandlePubSub only enqueues the notification so Pub/Sub gets its
+// acknowledgement right away; the job workers do the Gmail and LINE calls.
 func (h *PubSubWebhookHandler) HandlePubSub(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -45,8 +48,20 @@ func (h *PubSubWebhookHandler) HandlePubSub(w http.ResponseWriter, r *http.Reque
 
 	slog.Info("received Gmail notification", "message_id", msg.Message.MessageID, "publish_time", msg.Message.PublishTime)
 
-	if err := h.notificationService.ProcessGmailPushNotification(context.Background()); err != nil {
-		slog.Error("failed to process Gmail notification", "error", err)
+	var notification jobRepo.GmailPushPayload
+	if data, err := base64.StdEncoding.DecodeString(msg.Message.Data); err == nil {
+		if err := json.Unmarshal(data, &notification); err != nil {
+			slog.Warn("failed to decode Gmail notification data", "message_id", msg.Message.MessageID, "error", err)
+		}
+	}
+
+	var dedupKey string
+	if msg.Message.MessageID != "" {
+		dedupKey = "pubsub:" + msg.Message.MessageID
+	}
+
+	if err := h.queueService.Enqueue(r.Context(), jobRepo.TypeGmailPush, dedupKey, notification); err != nil {
+		slog.Error("failed to enqueue Gmail notification", "message_id", msg.Message.MessageID, "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/huavcjj/flux/internal/command"
+	eventRepo "github.com/huavcjj/flux/internal/domain/event"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	telegramRepo "github.com/huavcjj/flux/internal/domain/telegram"
+	"github.com/huavcjj/flux/internal/service/notification"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// telegramSecretHeader carries the secret_token given to setWebhook.
+	telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+	maxTelegramUpdate    = 1 << 20
+
+	// Bot commands that link a chat, e.g. "/start CODE" from a deep link.
+	telegramCmdStart = "start"
+	telegramCmdLink  = "link"
+)
+
+// TelegramUpdate is the part of a Bot API update flux handles.
+type TelegramUpdate struct {
+	UpdateID      int64                  `json:"update_id"`
+	Message       *TelegramMessage       `json:"message"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query"`
+}
+
+type TelegramMessage struct {
+	MessageID int64        `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type TelegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	Message *TelegramMessage `json:"message"`
+	Data    string           `json:"data"`
+}
+
+type TelegramWebhookHandler struct {
+	notificationService *notification.Service
+	eventRepo           eventRepo.EventRepo
+	telegramRepo        telegramRepo.TelegramRepo
+	router              *command.Router
+	secretToken         string
+}
+
+type TelegramWebhookConfig struct {
+	SecretToken      string
+	MailListLimit    int
+	MailListMaxLimit int
+}
+
+// NewTelegramWebhookHandler serves the commands of the LINE bot to linked
+// Telegram chats.
+func NewTelegramWebhookHandler(notificationService *notification.Service, eventRepo eventRepo.EventRepo, telegramRepo telegramRepo.TelegramRepo, cfg TelegramWebhookConfig) *TelegramWebhookHandler {
+	return &TelegramWebhookHandler{
+		notificationService: notificationService,
+		eventRepo:           eventRepo,
+		telegramRepo:        telegramRepo,
+		router:              newCommandRouter(notificationService, cfg.MailListLimit, cfg.MailListMaxLimit),
+		secretToken:         cfg.SecretToken,
+	}
+}
+
+func (h *TelegramWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(telegramSecretHeader)), []byte(h.secretToken)) != 1 {
+		slog.Warn("rejected Telegram webhook with invalid secret token")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var update TelegramUpdate
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTelegramUpdate)).Decode(&update); err != nil {
+		slog.Error("failed to decode Telegram update", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if h.markUpdateProcessed(r.Context(), update.UpdateID) {
+		switch {
+		case update.Message != nil:
+			h.handleMessage(r.Context(), update.Message)
+		case update.CallbackQuery != nil:
+			h.handleCallbackQuery(r.Context(), update.CallbackQuery)
+		}
+	}
+
+	// Any other status makes Telegram redeliver the update.
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "OK")
+}
+
+// markUpdateProcessed reports whether the update should be handled.
+// Telegram redelivers updates that were not acknowledged in time.
+func (h *TelegramWebhookHandler) markUpdateProcessed(ctx context.Context, updateID int64) bool {
+	if h.eventRepo == nil {
+		return true
+	}
+
+	eventID := "telegram:" + strconv.FormatInt(updateID, 10)
+	first, err := h.eventRepo.MarkProcessed(ctx, eventID, webhookEventTTL)
+	if err != nil {
+		slog.Error("failed to record webhook event", "webhook_event_id", eventID, "error", err)
+		return true
+	}
+	if !first {
+		slog.Info("skipping duplicate webhook event", "webhook_event_id", eventID)
+		return false
+	}
+	return true
+}
+
+func (h *TelegramWebhookHandler) handleMessage(ctx context.Context, msg *TelegramMessage) {
+	chatID, ok := privateChatID(msg)
+	if !ok || msg.Text == "" {
+		return
+	}
+	ctx = notification.WithReplyChannel(ctx, outboxRepo.ChannelTelegram, chatID)
+
+	text := normalizeTelegramCommand(strings.TrimSpace(msg.Text))
+	name, code, _ := strings.Cut(text, " ")
+	if code = strings.TrimSpace(code); code != "" && (name == telegramCmdStart || name == telegramCmdLink) {
+		if err := h.notificationService.LinkTelegram(ctx, chatID, code); err != nil {
+			slog.Error("failed to link Telegram chat", "error", err)
+		}
+		return
+	}
+
+	userID, ok := h.linkedUser(ctx, chatID)
+	if !ok {
+		return
+	}
+	if name == telegramCmdStart {
+		text = notification.CommandHelp
+	}
+
+	ctx, span := tracing.Start(ctx, "telegram.update.message", tracing.UserID(userID))
+	defer span.End()
+
+	slog.Info("received Telegram text message", "user_id", userID, "text", text)
+	h.processText(ctx, userID, text)
+}
+
+func (h *TelegramWebhookHandler) handleCallbackQuery(ctx context.Context, query *TelegramCallbackQuery) {
+	if err := h.telegramRepo.AnswerCallbackQuery(ctx, query.ID); err != nil {
+		slog.Warn("failed to answer Telegram callback query", "error", err)
+	}
+
+	if query.Message == nil {
+		return
+	}
+	chatID, ok := privateChatID(query.Message)
+	if !ok {
+		return
+	}
+	ctx = notification.WithReplyChannel(ctx, outboxRepo.ChannelTelegram, chatID)
+
+	userID, ok := h.linkedUser(ctx, chatID)
+	if !ok {
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "telegram.update.callback_query", tracing.UserID(userID))
+	defer span.End()
+
+	if text, ok := strings.CutPrefix(query.Data, telegramRepo.CallbackMessagePrefix); ok {
+		h.processText(ctx, userID, text)
+		return
+	}
+
+	postback, ok := strings.CutPrefix(query.Data, telegramRepo.CallbackPostbackPrefix)
+	if !ok {
+		return
+	}
+	data, err := url.ParseQuery(postback)
+	if err != nil {
+		slog.Error("failed to parse postback data", "user_id", userID, "error", err)
+		return
+	}
+
+	action := data.Get("action")
+	span.SetAttributes(attribute.String("line.postback_action", action))
+	slog.Info("received Telegram postback", "user_id", userID, "action", action)
+
+	if err := dispatchPostback(ctx, h.notificationService, userID, data, lineRepo.ReplyToken{}); err != nil {
+		tracing.RecordError(span, err)
+		slog.Error("failed to process postback", "user_id", userID, "action", action, "error", err)
+	}
+}
+
+// linkedUser returns the LINE user ID the chat is linked to. Unlinked chats
+// are told how to link.
+func (h *TelegramWebhookHandler) linkedUser(ctx context.Context, chatID string) (string, bool) {
+	userID, err := h.notificationService.TelegramUser(ctx, chatID)
+	if err != nil {
+		slog.Error("failed to get Telegram user", "error", err)
+		return "", false
+	}
+	if userID == "" {
+		if err := h.notificationService.SendTelegramLinkRequired(ctx, chatID); err != nil {
+			slog.Error("failed to send Telegram link instructions", "error", err)
+		}
+		return "", false
+	}
+	return userID, true
+}
+
+// processText runs a command as the linked user, like a LINE text message.
+// Replies go to the chat through the reply channel in ctx.
+func (h *TelegramWebhookHandler) processText(ctx context.Context, userID, text string) {
+	if h.notificationService.IsAuthPending(userID) {
+		if err := h.notificationService.CompletePendingAuth(ctx, userID, text, lineRepo.ReplyToken{}); err != nil {
+			slog.Error("failed to complete mail auth", "user_id", userID, "error", err)
+		}
+		return
+	}
+
+	if err := h.router.Dispatch(ctx, userID, text, lineRepo.ReplyToken{}); err != nil {
+		slog.Error("failed to process text message", "user_id", userID, "text", text, "error", err)
+	}
+}
+
+// privateChatID returns the chat of msg if it is a private chat. In groups
+// every member could run commands as the linked user.
+func privateChatID(msg *TelegramMessage) (string, bool) {
+	if msg.Chat.Type != "private" {
+		return "", false
+	}
+	return strconv.FormatInt(msg.Chat.ID, 10), true
+}
+
+// normalizeTelegramCommand turns a bot command such as "/unread@flux_bot 5"
+// into the text command "unread 5". Other text is returned as is.
+func normalizeTelegramCommand(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return text
+	}
+	name, args, _ := strings.Cut(text[1:], " ")
+	name, _, _ = strings.Cut(name, "@")
+	if args == "" {
+		return name
+	}
+	return name + " " + args
+}
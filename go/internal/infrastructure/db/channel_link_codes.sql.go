@@ -0,0 +1,89 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: channel_link_codes.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createChannelLinkCode = `-- name: CreateChannelLinkCode :exec
+INSERT INTO channel_link_codes (code, channel, user_id, expires_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateChannelLinkCodeParams struct {
+	Code      string    `db:"code" json:"code"`
+	Channel   string    `db:"channel" json:"channel"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) CreateChannelLinkCode(ctx context.Context, arg CreateChannelLinkCodeParams) error {
+	_, err := q.exec(ctx, q.createChannelLinkCodeStmt, createChannelLinkCode,
+		arg.Code,
+		arg.Channel,
+		arg.UserID,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteChannelLinkCode = `-- name: DeleteChannelLinkCode :execrows
+DELETE FROM channel_link_codes
+WHERE code = ?
+`
+
+func (q *Queries) DeleteChannelLinkCode(ctx context.Context, code string) (int64, error) {
+	result, err := q.exec(ctx, q.deleteChannelLinkCodeStmt, deleteChannelLinkCode, code)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteChannelLinkCodesByUserID = `-- name: DeleteChannelLinkCodesByUserID :exec
+DELETE FROM channel_link_codes
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteChannelLinkCodesByUserID(ctx context.Context, userID string) error {
+	_, err := q.exec(ctx, q.deleteChannelLinkCodesByUserIDStmt, deleteChannelLinkCodesByUserID, userID)
+	return err
+}
+
+const deleteExpiredChannelLinkCodes = `-- name: DeleteExpiredChannelLinkCodes :exec
+DELETE FROM channel_link_codes
+WHERE expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredChannelLinkCodes(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.exec(ctx, q.deleteExpiredChannelLinkCodesStmt, deleteExpiredChannelLinkCodes, expiresAt)
+	return err
+}
+
+const getChannelLinkCode = `-- name: GetChannelLinkCode :one
+SELECT code, channel, user_id, expires_at, created_at FROM channel_link_codes
+WHERE code = ? AND channel = ?
+`
+
+type GetChannelLinkCodeParams struct {
+	Code    string `db:"code" json:"code"`
+	Channel string `db:"channel" json:"channel"`
+}
+
+func (q *Queries) GetChannelLinkCode(ctx context.Context, arg GetChannelLinkCodeParams) (ChannelLinkCode, error) {
+	row := q.queryRow(ctx, q.getChannelLinkCodeStmt, getChannelLinkCode, arg.Code, arg.Channel)
+	var i ChannelLinkCode
+	err := row.Scan(
+		&i.Code,
+		&i.Channel,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -11,6 +11,25 @@ import (
 	"time"
 )
 
+const clearNotifiedEmailContent = `-- name: ClearNotifiedEmailContent :execrows
+UPDATE emails
+SET sender_email = '',
+    subject = NULL,
+    body_preview = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE is_notified = true
+  AND (sender_email <> '' OR subject IS NOT NULL OR body_preview IS NOT NULL)
+LIMIT ?
+`
+
+func (q *Queries) ClearNotifiedEmailContent(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.exec(ctx, q.clearNotifiedEmailContentStmt, clearNotifiedEmailContent, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createEmail = `-- name: CreateEmail :execresult
 INSERT INTO emails (
     user_id,
@@ -57,6 +76,27 @@ func (q *Queries) DeleteEmailsByUserID(ctx context.Context, userID string) error
 	return err
 }
 
+const deleteEmailsOlderThan = `-- name: DeleteEmailsOlderThan :execrows
+DELETE FROM emails
+WHERE user_id = ? AND received_at < ?
+ORDER BY received_at
+LIMIT ?
+`
+
+type DeleteEmailsOlderThanParams struct {
+	UserID     string    `db:"user_id" json:"user_id"`
+	ReceivedAt time.Time `db:"received_at" json:"received_at"`
+	Limit      int32     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) DeleteEmailsOlderThan(ctx context.Context, arg DeleteEmailsOlderThanParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteEmailsOlderThanStmt, deleteEmailsOlderThan, arg.UserID, arg.ReceivedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getEmailByGmailMessageID = `-- name: GetEmailByGmailMessageID :one
 SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
 WHERE gmail_message_id = ?
@@ -84,11 +124,29 @@ func (q *Queries) GetEmailByGmailMessageID(ctx context.Context, gmailMessageID s
 const getEmailsByUserID = `-- name: GetEmailsByUserID :many
 SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
 WHERE user_id = ?
-ORDER BY received_at DESC
+  AND (? = 0
+       OR received_at < ?
+       OR (received_at = ? AND id < ?))
+ORDER BY received_at DESC, id DESC
+LIMIT ?
 `
 
-func (q *Queries) GetEmailsByUserID(ctx context.Context, userID string) ([]Email, error) {
-	rows, err := q.query(ctx, q.getEmailsByUserIDStmt, getEmailsByUserID, userID)
+type GetEmailsByUserIDParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	CursorID         uint64    `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	Limit            int32     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) GetEmailsByUserID(ctx context.Context, arg GetEmailsByUserIDParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.getEmailsByUserIDStmt, getEmailsByUserID,
+		arg.UserID,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.CursorReceivedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -124,16 +182,31 @@ func (q *Queries) GetEmailsByUserID(ctx context.Context, userID string) ([]Email
 const getRecentEmails = `-- name: GetRecentEmails :many
 SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
 WHERE user_id = ? AND received_at >= ?
-ORDER BY received_at DESC
+  AND (? = 0
+       OR received_at < ?
+       OR (received_at = ? AND id < ?))
+ORDER BY received_at DESC, id DESC
+LIMIT ?
 `
 
 type GetRecentEmailsParams struct {
-	UserID     string    `db:"user_id" json:"user_id"`
-	ReceivedAt time.Time `db:"received_at" json:"received_at"`
+	UserID           string    `db:"user_id" json:"user_id"`
+	Since            time.Time `db:"since" json:"since"`
+	CursorID         uint64    `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	Limit            int32     `db:"limit" json:"limit"`
 }
 
 func (q *Queries) GetRecentEmails(ctx context.Context, arg GetRecentEmailsParams) ([]Email, error) {
-	rows, err := q.query(ctx, q.getRecentEmailsStmt, getRecentEmails, arg.UserID, arg.ReceivedAt)
+	rows, err := q.query(ctx, q.getRecentEmailsStmt, getRecentEmails,
+		arg.UserID,
+		arg.Since,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.CursorReceivedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -169,11 +242,29 @@ func (q *Queries) GetRecentEmails(ctx context.Context, arg GetRecentEmailsParams
 const getUnnotifiedEmailsByUserID = `-- name: GetUnnotifiedEmailsByUserID :many
 SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
 WHERE user_id = ? AND is_notified = false
-ORDER BY received_at DESC
+  AND (? = 0
+       OR received_at < ?
+       OR (received_at = ? AND id < ?))
+ORDER BY received_at DESC, id DESC
+LIMIT ?
 `
 
-func (q *Queries) GetUnnotifiedEmailsByUserID(ctx context.Context, userID string) ([]Email, error) {
-	rows, err := q.query(ctx, q.getUnnotifiedEmailsByUserIDStmt, getUnnotifiedEmailsByUserID, userID)
+type GetUnnotifiedEmailsByUserIDParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	CursorID         uint64    `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	Limit            int32     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) GetUnnotifiedEmailsByUserID(ctx context.Context, arg GetUnnotifiedEmailsByUserIDParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.getUnnotifiedEmailsByUserIDStmt, getUnnotifiedEmailsByUserID,
+		arg.UserID,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.CursorReceivedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +309,79 @@ func (q *Queries) MarkEmailAsNotified(ctx context.Context, gmailMessageID string
 	return err
 }
 
+const markEmailAsNotifiedByID = `-- name: MarkEmailAsNotifiedByID :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) MarkEmailAsNotifiedByID(ctx context.Context, id uint64) error {
+	_, err := q.exec(ctx, q.markEmailAsNotifiedByIDStmt, markEmailAsNotifiedByID, id)
+	return err
+}
+
+const searchEmails = `-- name: SearchEmails :many
+SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
+WHERE user_id = ?
+  AND MATCH (sender_email, subject, body_preview) AGAINST (? IN BOOLEAN MODE)
+  AND (? = 0
+       OR received_at < ?
+       OR (received_at = ? AND id < ?))
+ORDER BY received_at DESC, id DESC
+LIMIT ?
+`
+
+type SearchEmailsParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	Query            string    `db:"query" json:"query"`
+	CursorID         uint64    `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	Limit            int32     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) SearchEmails(ctx context.Context, arg SearchEmailsParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.searchEmailsStmt, searchEmails,
+		arg.UserID,
+		arg.Query,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.CursorReceivedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Email{}
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.GmailMessageID,
+			&i.SenderEmail,
+			&i.Subject,
+			&i.BodyPreview,
+			&i.ReceivedAt,
+			&i.IsNotified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateEmailNotified = `-- name: UpdateEmailNotified :exec
 UPDATE emails
 SET is_notified = ?,
@@ -6,9 +6,30 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type AuditEvent struct {
+	ID            uint64         `db:"id" json:"id"`
+	Actor         string         `db:"actor" json:"actor"`
+	SubjectUserID sql.NullString `db:"subject_user_id" json:"subject_user_id"`
+	Action        string         `db:"action" json:"action"`
+	Result        string         `db:"result" json:"result"`
+	IpAddress     sql.NullString `db:"ip_address" json:"ip_address"`
+	UserAgent     sql.NullString `db:"user_agent" json:"user_agent"`
+	Detail        sql.NullString `db:"detail" json:"detail"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+}
+
+type ChannelLinkCode struct {
+	Code      string       `db:"code" json:"code"`
+	Channel   string       `db:"channel" json:"channel"`
+	UserID    string       `db:"user_id" json:"user_id"`
+	ExpiresAt time.Time    `db:"expires_at" json:"expires_at"`
+	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
+}
+
 type Email struct {
 	ID             uint64         `db:"id" json:"id"`
 	UserID         string         `db:"user_id" json:"user_id"`
@@ -22,14 +43,97 @@ type Email struct {
 	UpdatedAt      sql.NullTime   `db:"updated_at" json:"updated_at"`
 }
 
+type Job struct {
+	ID          uint64          `db:"id" json:"id"`
+	Type        string          `db:"type" json:"type"`
+	DedupKey    sql.NullString  `db:"dedup_key" json:"dedup_key"`
+	Payload     json.RawMessage `db:"payload" json:"payload"`
+	Status      string          `db:"status" json:"status"`
+	Attempts    int32           `db:"attempts" json:"attempts"`
+	MaxAttempts int32           `db:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time       `db:"run_at" json:"run_at"`
+	LockedAt    sql.NullTime    `db:"locked_at" json:"locked_at"`
+	LastError   sql.NullString  `db:"last_error" json:"last_error"`
+	CreatedAt   sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt   sql.NullTime    `db:"updated_at" json:"updated_at"`
+	TraceParent sql.NullString  `db:"trace_parent" json:"trace_parent"`
+}
+
+type NotificationChannel struct {
+	ID        uint64       `db:"id" json:"id"`
+	UserID    string       `db:"user_id" json:"user_id"`
+	Channel   string       `db:"channel" json:"channel"`
+	Target    string       `db:"target" json:"target"`
+	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at" json:"updated_at"`
+}
+
+type NotificationOutbox struct {
+	ID             uint64         `db:"id" json:"id"`
+	IdempotencyKey string         `db:"idempotency_key" json:"idempotency_key"`
+	RetryKey       string         `db:"retry_key" json:"retry_key"`
+	UserID         string         `db:"user_id" json:"user_id"`
+	EmailID        sql.NullInt64  `db:"email_id" json:"email_id"`
+	Channel        string         `db:"channel" json:"channel"`
+	Recipient      string         `db:"recipient" json:"recipient"`
+	Message        string         `db:"message" json:"message"`
+	Status         string         `db:"status" json:"status"`
+	Attempts       int32          `db:"attempts" json:"attempts"`
+	LastError      sql.NullString `db:"last_error" json:"last_error"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	SentAt         sql.NullTime   `db:"sent_at" json:"sent_at"`
+	CreatedAt      sql.NullTime   `db:"created_at" json:"created_at"`
+	UpdatedAt      sql.NullTime   `db:"updated_at" json:"updated_at"`
+	TraceParent    sql.NullString `db:"trace_parent" json:"trace_parent"`
+}
+
 type User struct {
-	ID                  string         `db:"id" json:"id"`
-	LineUserID          string         `db:"line_user_id" json:"line_user_id"`
-	GmailAccessToken    sql.NullString `db:"gmail_access_token" json:"gmail_access_token"`
-	GmailRefreshToken   sql.NullString `db:"gmail_refresh_token" json:"gmail_refresh_token"`
-	GmailTokenExpiresAt sql.NullInt64  `db:"gmail_token_expires_at" json:"gmail_token_expires_at"`
-	IsActive            sql.NullBool   `db:"is_active" json:"is_active"`
-	CreatedAt           sql.NullTime   `db:"created_at" json:"created_at"`
-	UpdatedAt           sql.NullTime   `db:"updated_at" json:"updated_at"`
-	GmailHistoryID      sql.NullInt64  `db:"gmail_history_id" json:"gmail_history_id"`
+	ID                 string         `db:"id" json:"id"`
+	LineUserID         string         `db:"line_user_id" json:"line_user_id"`
+	MailAccessToken    sql.NullString `db:"mail_access_token" json:"mail_access_token"`
+	MailRefreshToken   sql.NullString `db:"mail_refresh_token" json:"mail_refresh_token"`
+	MailTokenExpiresAt sql.NullInt64  `db:"mail_token_expires_at" json:"mail_token_expires_at"`
+	IsActive           sql.NullBool   `db:"is_active" json:"is_active"`
+	CreatedAt          sql.NullTime   `db:"created_at" json:"created_at"`
+	UpdatedAt          sql.NullTime   `db:"updated_at" json:"updated_at"`
+	GmailHistoryID     sql.NullInt64  `db:"gmail_history_id" json:"gmail_history_id"`
+	MailWatchExpiresAt sql.NullInt64  `db:"mail_watch_expires_at" json:"mail_watch_expires_at"`
+	EmailRetentionDays sql.NullInt32  `db:"email_retention_days" json:"email_retention_days"`
+	MailProvider       string         `db:"mail_provider" json:"mail_provider"`
+	MailWatchID        sql.NullString `db:"mail_watch_id" json:"mail_watch_id"`
+	MailSyncCursor     sql.NullString `db:"mail_sync_cursor" json:"mail_sync_cursor"`
+}
+
+type WebhookDelivery struct {
+	ID             uint64         `db:"id" json:"id"`
+	EndpointID     uint64         `db:"endpoint_id" json:"endpoint_id"`
+	UserID         string         `db:"user_id" json:"user_id"`
+	EventID        string         `db:"event_id" json:"event_id"`
+	EventType      string         `db:"event_type" json:"event_type"`
+	Payload        string         `db:"payload" json:"payload"`
+	Status         string         `db:"status" json:"status"`
+	Attempts       int32          `db:"attempts" json:"attempts"`
+	ResponseStatus sql.NullInt32  `db:"response_status" json:"response_status"`
+	LastError      sql.NullString `db:"last_error" json:"last_error"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at" json:"delivered_at"`
+	RedeliveryOf   sql.NullInt64  `db:"redelivery_of" json:"redelivery_of"`
+	CreatedAt      sql.NullTime   `db:"created_at" json:"created_at"`
+	UpdatedAt      sql.NullTime   `db:"updated_at" json:"updated_at"`
+}
+
+type WebhookEndpoint struct {
+	ID        uint64       `db:"id" json:"id"`
+	UserID    string       `db:"user_id" json:"user_id"`
+	Url       string       `db:"url" json:"url"`
+	Secret    string       `db:"secret" json:"secret"`
+	CreatedBy string       `db:"created_by" json:"created_by"`
+	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at" json:"updated_at"`
+}
+
+type WebhookEvent struct {
+	EventID   string       `db:"event_id" json:"event_id"`
+	ExpiresAt time.Time    `db:"expires_at" json:"expires_at"`
+	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
 }
@@ -0,0 +1,42 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhook_events.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createWebhookEvent = `-- name: CreateWebhookEvent :execresult
+INSERT INTO webhook_events (
+    event_id,
+    expires_at
+) VALUES (
+    ?, ?
+)
+ON DUPLICATE KEY UPDATE
+    expires_at = IF(expires_at < CURRENT_TIMESTAMP, VALUES(expires_at), expires_at)
+`
+
+type CreateWebhookEventParams struct {
+	EventID   string    `db:"event_id" json:"event_id"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) (sql.Result, error) {
+	return q.exec(ctx, q.createWebhookEventStmt, createWebhookEvent, arg.EventID, arg.ExpiresAt)
+}
+
+const deleteExpiredWebhookEvents = `-- name: DeleteExpiredWebhookEvents :exec
+DELETE FROM webhook_events
+WHERE expires_at < ?
+`
+
+func (q *Queries) DeleteExpiredWebhookEvents(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.exec(ctx, q.deleteExpiredWebhookEventsStmt, deleteExpiredWebhookEvents, expiresAt)
+	return err
+}
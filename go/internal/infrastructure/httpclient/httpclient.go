@@ -0,0 +1,26 @@
+// Package httpclient provides the HTTP client for services whose URLs are
+// secrets, such as webhook URLs and bot API URLs holding a token.
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// New returns a client with the timeout. It is deliberately not traced by
+// otelhttp, which would record the request URLs in spans.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// Redact drops the URL from HTTP client errors, since errors end up in
+// logs, the outbox and the webhook delivery log.
+func Redact(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit_events.sql
+
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const clearAuditEventClientInfo = `-- name: ClearAuditEventClientInfo :exec
+UPDATE audit_events
+SET ip_address = NULL,
+    user_agent = NULL
+WHERE subject_user_id = $1
+`
+
+func (q *Queries) ClearAuditEventClientInfo(ctx context.Context, subjectUserID sql.NullString) error {
+	_, err := q.exec(ctx, q.clearAuditEventClientInfoStmt, clearAuditEventClientInfo, subjectUserID)
+	return err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    actor,
+    subject_user_id,
+    action,
+    result,
+    ip_address,
+    user_agent,
+    detail
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+`
+
+type CreateAuditEventParams struct {
+	Actor         string         `db:"actor" json:"actor"`
+	SubjectUserID sql.NullString `db:"subject_user_id" json:"subject_user_id"`
+	Action        string         `db:"action" json:"action"`
+	Result        string         `db:"result" json:"result"`
+	IpAddress     sql.NullString `db:"ip_address" json:"ip_address"`
+	UserAgent     sql.NullString `db:"user_agent" json:"user_agent"`
+	Detail        sql.NullString `db:"detail" json:"detail"`
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error {
+	_, err := q.exec(ctx, q.createAuditEventStmt, createAuditEvent,
+		arg.Actor,
+		arg.SubjectUserID,
+		arg.Action,
+		arg.Result,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.Detail,
+	)
+	return err
+}
+
+const listAuditEventsBySubject = `-- name: ListAuditEventsBySubject :many
+SELECT id, actor, subject_user_id, action, result, ip_address, user_agent, detail, created_at FROM audit_events
+WHERE subject_user_id = $1
+  AND ($2::bigint = 0 OR id < $2::bigint)
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListAuditEventsBySubjectParams struct {
+	SubjectUserID sql.NullString `db:"subject_user_id" json:"subject_user_id"`
+	BeforeID      int64          `db:"before_id" json:"before_id"`
+	RowLimit      int32          `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) ListAuditEventsBySubject(ctx context.Context, arg ListAuditEventsBySubjectParams) ([]AuditEvent, error) {
+	rows, err := q.query(ctx, q.listAuditEventsBySubjectStmt, listAuditEventsBySubject, arg.SubjectUserID, arg.BeforeID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.SubjectUserID,
+			&i.Action,
+			&i.Result,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Detail,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,328 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: jobs.sql
+
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const countJobsByStatus = `-- name: CountJobsByStatus :many
+SELECT status, COUNT(*) AS count FROM jobs
+GROUP BY status
+`
+
+type CountJobsByStatusRow struct {
+	Status string `db:"status" json:"status"`
+	Count  int64  `db:"count" json:"count"`
+}
+
+func (q *Queries) CountJobsByStatus(ctx context.Context) ([]CountJobsByStatusRow, error) {
+	rows, err := q.query(ctx, q.countJobsByStatusStmt, countJobsByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountJobsByStatusRow{}
+	for rows.Next() {
+		var i CountJobsByStatusRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createJob = `-- name: CreateJob :execresult
+INSERT INTO jobs (
+    type,
+    dedup_key,
+    payload,
+    max_attempts,
+    run_at,
+    trace_parent
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT DO NOTHING
+`
+
+type CreateJobParams struct {
+	Type        string          `db:"type" json:"type"`
+	DedupKey    sql.NullString  `db:"dedup_key" json:"dedup_key"`
+	Payload     json.RawMessage `db:"payload" json:"payload"`
+	MaxAttempts int32           `db:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time       `db:"run_at" json:"run_at"`
+	TraceParent sql.NullString  `db:"trace_parent" json:"trace_parent"`
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (sql.Result, error) {
+	return q.exec(ctx, q.createJobStmt, createJob,
+		arg.Type,
+		arg.DedupKey,
+		arg.Payload,
+		arg.MaxAttempts,
+		arg.RunAt,
+		arg.TraceParent,
+	)
+}
+
+const deadLetterStaleJobs = `-- name: DeadLetterStaleJobs :execresult
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = 'worker lost while running the job'
+WHERE status = 'running' AND locked_at < $1 AND attempts >= max_attempts
+`
+
+func (q *Queries) DeadLetterStaleJobs(ctx context.Context, lockedAt sql.NullTime) (sql.Result, error) {
+	return q.exec(ctx, q.deadLetterStaleJobsStmt, deadLetterStaleJobs, lockedAt)
+}
+
+const deleteDoneJobsBefore = `-- name: DeleteDoneJobsBefore :execrows
+DELETE FROM jobs
+WHERE id IN (
+    SELECT j.id FROM jobs j
+    WHERE j.status = 'done' AND j.updated_at < $1
+    ORDER BY j.updated_at
+    LIMIT $2
+)
+`
+
+type DeleteDoneJobsBeforeParams struct {
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Limit     int32     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) DeleteDoneJobsBefore(ctx context.Context, arg DeleteDoneJobsBeforeParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteDoneJobsBeforeStmt, deleteDoneJobsBefore, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteJobsByUserID = `-- name: DeleteJobsByUserID :exec
+DELETE FROM jobs
+WHERE EXISTS (
+    SELECT 1 FROM users u
+    WHERE u.id = $1 AND (
+        jobs.dedup_key LIKE '%' || u.id || '%'
+        OR jobs.dedup_key LIKE '%' || u.line_user_id || '%'
+        OR jobs.dedup_key LIKE '%' || u.mail_watch_id || '%'
+        OR jobs.payload::text LIKE '%' || u.id || '%'
+        OR jobs.payload::text LIKE '%' || u.line_user_id || '%'
+        OR jobs.payload::text LIKE '%' || u.mail_watch_id || '%'
+    )
+)
+`
+
+// The jobs of a user name their ID, LINE user ID or mail watch in the
+// dedup key or payload.
+func (q *Queries) DeleteJobsByUserID(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.deleteJobsByUserIDStmt, deleteJobsByUserID, id)
+	return err
+}
+
+const getNextPendingJobForUpdate = `-- name: GetNextPendingJobForUpdate :one
+SELECT id, type, dedup_key, payload, status, attempts, max_attempts, run_at, locked_at, last_error, trace_parent, created_at, updated_at FROM jobs
+WHERE status = 'pending' AND run_at <= $1
+ORDER BY run_at, id
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) GetNextPendingJobForUpdate(ctx context.Context, runAt time.Time) (Job, error) {
+	row := q.queryRow(ctx, q.getNextPendingJobForUpdateStmt, getNextPendingJobForUpdate, runAt)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.DedupKey,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.LastError,
+		&i.TraceParent,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOldestPendingJobRunAt = `-- name: GetOldestPendingJobRunAt :one
+SELECT run_at FROM jobs
+WHERE status = 'pending'
+ORDER BY run_at
+LIMIT 1
+`
+
+func (q *Queries) GetOldestPendingJobRunAt(ctx context.Context) (time.Time, error) {
+	row := q.queryRow(ctx, q.getOldestPendingJobRunAtStmt, getOldestPendingJobRunAt)
+	var run_at time.Time
+	err := row.Scan(&run_at)
+	return run_at, err
+}
+
+const listJobsByStatus = `-- name: ListJobsByStatus :many
+SELECT id, type, dedup_key, payload, status, attempts, max_attempts, run_at, locked_at, last_error, trace_parent, created_at, updated_at FROM jobs
+WHERE status = $1
+ORDER BY updated_at DESC, id DESC
+LIMIT $2
+`
+
+type ListJobsByStatusParams struct {
+	Status string `db:"status" json:"status"`
+	Limit  int32  `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListJobsByStatus(ctx context.Context, arg ListJobsByStatusParams) ([]Job, error) {
+	rows, err := q.query(ctx, q.listJobsByStatusStmt, listJobsByStatus, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.DedupKey,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.LastError,
+			&i.TraceParent,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markJobDead = `-- name: MarkJobDead :exec
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = $1
+WHERE id = $2
+`
+
+type MarkJobDeadParams struct {
+	LastError sql.NullString `db:"last_error" json:"last_error"`
+	ID        int64          `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkJobDead(ctx context.Context, arg MarkJobDeadParams) error {
+	_, err := q.exec(ctx, q.markJobDeadStmt, markJobDead, arg.LastError, arg.ID)
+	return err
+}
+
+const markJobDone = `-- name: MarkJobDone :exec
+UPDATE jobs
+SET status = 'done',
+    locked_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkJobDone(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.markJobDoneStmt, markJobDone, id)
+	return err
+}
+
+const markJobRunning = `-- name: MarkJobRunning :exec
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = $1
+WHERE id = $2
+`
+
+type MarkJobRunningParams struct {
+	LockedAt sql.NullTime `db:"locked_at" json:"locked_at"`
+	ID       int64        `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkJobRunning(ctx context.Context, arg MarkJobRunningParams) error {
+	_, err := q.exec(ctx, q.markJobRunningStmt, markJobRunning, arg.LockedAt, arg.ID)
+	return err
+}
+
+const replayDeadJob = `-- name: ReplayDeadJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    run_at = CURRENT_TIMESTAMP,
+    locked_at = NULL,
+    last_error = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'dead'
+`
+
+func (q *Queries) ReplayDeadJob(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.replayDeadJobStmt, replayDeadJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requeueStaleJobs = `-- name: RequeueStaleJobs :execresult
+UPDATE jobs
+SET status = 'pending',
+    locked_at = NULL,
+    last_error = 'worker lost while running the job'
+WHERE status = 'running' AND locked_at < $1 AND attempts < max_attempts
+`
+
+func (q *Queries) RequeueStaleJobs(ctx context.Context, lockedAt sql.NullTime) (sql.Result, error) {
+	return q.exec(ctx, q.requeueStaleJobsStmt, requeueStaleJobs, lockedAt)
+}
+
+const rescheduleJob = `-- name: RescheduleJob :exec
+UPDATE jobs
+SET status = 'pending',
+    locked_at = NULL,
+    run_at = $1,
+    last_error = $2
+WHERE id = $3
+`
+
+type RescheduleJobParams struct {
+	RunAt     time.Time      `db:"run_at" json:"run_at"`
+	LastError sql.NullString `db:"last_error" json:"last_error"`
+	ID        int64          `db:"id" json:"id"`
+}
+
+func (q *Queries) RescheduleJob(ctx context.Context, arg RescheduleJobParams) error {
+	_, err := q.exec(ctx, q.rescheduleJobStmt, rescheduleJob, arg.RunAt, arg.LastError, arg.ID)
+	return err
+}
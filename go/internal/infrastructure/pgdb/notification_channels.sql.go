@@ -0,0 +1,117 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notification_channels.sql
+
+package pgdb
+
+import (
+	"context"
+)
+
+const deleteNotificationChannel = `-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels
+WHERE user_id = $1 AND channel = $2
+`
+
+type DeleteNotificationChannelParams struct {
+	UserID  string `db:"user_id" json:"user_id"`
+	Channel string `db:"channel" json:"channel"`
+}
+
+func (q *Queries) DeleteNotificationChannel(ctx context.Context, arg DeleteNotificationChannelParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteNotificationChannelStmt, deleteNotificationChannel, arg.UserID, arg.Channel)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteNotificationChannelsByUserID = `-- name: DeleteNotificationChannelsByUserID :exec
+DELETE FROM notification_channels
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteNotificationChannelsByUserID(ctx context.Context, userID string) error {
+	_, err := q.exec(ctx, q.deleteNotificationChannelsByUserIDStmt, deleteNotificationChannelsByUserID, userID)
+	return err
+}
+
+const getNotificationChannelByTarget = `-- name: GetNotificationChannelByTarget :one
+SELECT id, user_id, channel, target, created_at, updated_at FROM notification_channels
+WHERE channel = $1 AND target = $2
+ORDER BY id
+LIMIT 1
+`
+
+type GetNotificationChannelByTargetParams struct {
+	Channel string `db:"channel" json:"channel"`
+	Target  string `db:"target" json:"target"`
+}
+
+func (q *Queries) GetNotificationChannelByTarget(ctx context.Context, arg GetNotificationChannelByTargetParams) (NotificationChannel, error) {
+	row := q.queryRow(ctx, q.getNotificationChannelByTargetStmt, getNotificationChannelByTarget, arg.Channel, arg.Target)
+	var i NotificationChannel
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Channel,
+		&i.Target,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listNotificationChannelsByUserID = `-- name: ListNotificationChannelsByUserID :many
+SELECT id, user_id, channel, target, created_at, updated_at FROM notification_channels
+WHERE user_id = $1
+ORDER BY channel
+`
+
+func (q *Queries) ListNotificationChannelsByUserID(ctx context.Context, userID string) ([]NotificationChannel, error) {
+	rows, err := q.query(ctx, q.listNotificationChannelsByUserIDStmt, listNotificationChannelsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationChannel{}
+	for rows.Next() {
+		var i NotificationChannel
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Channel,
+			&i.Target,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertNotificationChannel = `-- name: UpsertNotificationChannel :exec
+INSERT INTO notification_channels (user_id, channel, target)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, channel) DO UPDATE SET target = EXCLUDED.target
+`
+
+type UpsertNotificationChannelParams struct {
+	UserID  string `db:"user_id" json:"user_id"`
+	Channel string `db:"channel" json:"channel"`
+	Target  string `db:"target" json:"target"`
+}
+
+func (q *Queries) UpsertNotificationChannel(ctx context.Context, arg UpsertNotificationChannelParams) error {
+	_, err := q.exec(ctx, q.upsertNotificationChannelStmt, upsertNotificationChannel, arg.UserID, arg.Channel, arg.Target)
+	return err
+}
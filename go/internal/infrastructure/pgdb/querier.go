@@ -0,0 +1,100 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package pgdb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type Querier interface {
+	ClearAuditEventClientInfo(ctx context.Context, subjectUserID sql.NullString) error
+	ClearDeliveredWebhookPayloads(ctx context.Context, limit int32) (int64, error)
+	ClearNotifiedEmailContent(ctx context.Context, limit int32) (int64, error)
+	ClearSentOutboxMessages(ctx context.Context, limit int32) (int64, error)
+	CountJobsByStatus(ctx context.Context) ([]CountJobsByStatusRow, error)
+	CountOutboxEntriesByStatus(ctx context.Context) ([]CountOutboxEntriesByStatusRow, error)
+	CountUsersWithWatchExpiringBetween(ctx context.Context, arg CountUsersWithWatchExpiringBetweenParams) (int64, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateChannelLinkCode(ctx context.Context, arg CreateChannelLinkCodeParams) error
+	CreateEmail(ctx context.Context, arg CreateEmailParams) (int64, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (sql.Result, error)
+	CreateOutboxEntry(ctx context.Context, arg CreateOutboxEntryParams) (sql.Result, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (int64, error)
+	CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) (sql.Result, error)
+	DeactivateUser(ctx context.Context, id string) (int64, error)
+	DeadLetterStaleJobs(ctx context.Context, lockedAt sql.NullTime) (sql.Result, error)
+	DeleteChannelLinkCode(ctx context.Context, code string) (int64, error)
+	DeleteChannelLinkCodesByUserID(ctx context.Context, userID string) error
+	DeleteDoneJobsBefore(ctx context.Context, arg DeleteDoneJobsBeforeParams) (int64, error)
+	DeleteEmailsByUserID(ctx context.Context, userID string) error
+	DeleteEmailsOlderThan(ctx context.Context, arg DeleteEmailsOlderThanParams) (int64, error)
+	DeleteExpiredChannelLinkCodes(ctx context.Context, expiresAt time.Time) error
+	DeleteExpiredWebhookEvents(ctx context.Context, expiresAt time.Time) error
+	DeleteFinishedOutboxEntries(ctx context.Context, arg DeleteFinishedOutboxEntriesParams) (int64, error)
+	DeleteFinishedWebhookDeliveries(ctx context.Context, arg DeleteFinishedWebhookDeliveriesParams) (int64, error)
+	// The jobs of a user name their ID, LINE user ID or mail watch in the
+	// dedup key or payload.
+	DeleteJobsByUserID(ctx context.Context, id string) error
+	DeleteNotificationChannel(ctx context.Context, arg DeleteNotificationChannelParams) (int64, error)
+	DeleteNotificationChannelsByUserID(ctx context.Context, userID string) error
+	DeleteOutboxEntriesByUserID(ctx context.Context, userID string) error
+	DeleteUser(ctx context.Context, id string) (int64, error)
+	DeleteWebhookDeliveriesByUserID(ctx context.Context, userID string) error
+	DeleteWebhookEndpointByUserID(ctx context.Context, userID string) (int64, error)
+	GetAllActiveUsers(ctx context.Context) ([]User, error)
+	GetChannelLinkCode(ctx context.Context, arg GetChannelLinkCodeParams) (ChannelLinkCode, error)
+	GetDueOutboxEntriesForUpdate(ctx context.Context, arg GetDueOutboxEntriesForUpdateParams) ([]NotificationOutbox, error)
+	GetDueWebhookDeliveriesForUpdate(ctx context.Context, arg GetDueWebhookDeliveriesForUpdateParams) ([]WebhookDelivery, error)
+	GetEmailByGmailMessageID(ctx context.Context, gmailMessageID string) (Email, error)
+	GetEmailsByUserID(ctx context.Context, arg GetEmailsByUserIDParams) ([]Email, error)
+	GetNextPendingJobForUpdate(ctx context.Context, runAt time.Time) (Job, error)
+	GetNotificationChannelByTarget(ctx context.Context, arg GetNotificationChannelByTargetParams) (NotificationChannel, error)
+	GetOldestPendingJobRunAt(ctx context.Context) (time.Time, error)
+	GetRecentEmails(ctx context.Context, arg GetRecentEmailsParams) ([]Email, error)
+	GetUnnotifiedEmailsByUserID(ctx context.Context, arg GetUnnotifiedEmailsByUserIDParams) ([]Email, error)
+	GetUserByID(ctx context.Context, id string) (User, error)
+	GetUserByLineUserID(ctx context.Context, lineUserID string) (User, error)
+	GetUserByMailWatchID(ctx context.Context, mailWatchID sql.NullString) (User, error)
+	GetUsersWithWatchExpiringBefore(ctx context.Context, beforeUnix sql.NullInt64) ([]User, error)
+	GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	GetWebhookEndpointByUserID(ctx context.Context, userID string) (WebhookEndpoint, error)
+	LeaseOutboxEntry(ctx context.Context, arg LeaseOutboxEntryParams) error
+	LeaseWebhookDelivery(ctx context.Context, arg LeaseWebhookDeliveryParams) error
+	ListAuditEventsBySubject(ctx context.Context, arg ListAuditEventsBySubjectParams) ([]AuditEvent, error)
+	ListJobsByStatus(ctx context.Context, arg ListJobsByStatusParams) ([]Job, error)
+	ListNotificationChannelsByUserID(ctx context.Context, userID string) ([]NotificationChannel, error)
+	ListRecentOutboxEntries(ctx context.Context, arg ListRecentOutboxEntriesParams) ([]NotificationOutbox, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListWebhookDeliveriesByUserID(ctx context.Context, arg ListWebhookDeliveriesByUserIDParams) ([]WebhookDelivery, error)
+	MarkEmailAsNotified(ctx context.Context, gmailMessageID string) error
+	MarkEmailAsNotifiedByID(ctx context.Context, id int64) error
+	MarkJobDead(ctx context.Context, arg MarkJobDeadParams) error
+	MarkJobDone(ctx context.Context, id int64) error
+	MarkJobRunning(ctx context.Context, arg MarkJobRunningParams) error
+	MarkOutboxEntryFailed(ctx context.Context, arg MarkOutboxEntryFailedParams) error
+	MarkOutboxEntrySent(ctx context.Context, arg MarkOutboxEntrySentParams) error
+	MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	ReplayDeadJob(ctx context.Context, id int64) (int64, error)
+	RequeueStaleJobs(ctx context.Context, lockedAt sql.NullTime) (sql.Result, error)
+	RescheduleJob(ctx context.Context, arg RescheduleJobParams) error
+	RescheduleOutboxEntry(ctx context.Context, arg RescheduleOutboxEntryParams) error
+	RescheduleWebhookDelivery(ctx context.Context, arg RescheduleWebhookDeliveryParams) error
+	SearchEmails(ctx context.Context, arg SearchEmailsParams) ([]Email, error)
+	UpdateEmailNotified(ctx context.Context, arg UpdateEmailNotifiedParams) error
+	UpdateUserEmailRetention(ctx context.Context, arg UpdateUserEmailRetentionParams) error
+	UpdateUserMailSyncCursor(ctx context.Context, arg UpdateUserMailSyncCursorParams) error
+	UpdateUserMailTokens(ctx context.Context, arg UpdateUserMailTokensParams) error
+	UpdateUserMailWatch(ctx context.Context, arg UpdateUserMailWatchParams) error
+	UpdateUserRefreshedMailTokens(ctx context.Context, arg UpdateUserRefreshedMailTokensParams) error
+	UpsertNotificationChannel(ctx context.Context, arg UpsertNotificationChannelParams) error
+	UpsertWebhookEndpoint(ctx context.Context, arg UpsertWebhookEndpointParams) error
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	audit_domain "github.com/huavcjj/flux/internal/domain/audit"
+	"github.com/huavcjj/flux/internal/infrastructure/db"
+)
+
+type auditRepo struct {
+	queries *db.Queries
+}
+
+var _ audit_domain.AuditRepo = (*auditRepo)(nil)
+
+func NewAuditRepo(dbConn *sql.DB) audit_domain.AuditRepo {
+	return &auditRepo{
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *auditRepo) CreateEvent(ctx context.Context, event *audit_domain.Event) error {
+	err := r.queries.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		Actor:         event.Actor,
+		SubjectUserID: nullString(event.SubjectUserID),
+		Action:        event.Action,
+		Result:        event.Result,
+		IpAddress:     nullString(event.IPAddress),
+		UserAgent:     nullString(event.UserAgent),
+		Detail:        nullString(event.Detail),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+func (r *auditRepo) ListEventsBySubject(ctx context.Context, subjectUserID string, beforeID uint64, limit int) ([]audit_domain.Event, error) {
+	dbEvents, err := r.queries.ListAuditEventsBySubject(ctx, db.ListAuditEventsBySubjectParams{
+		SubjectUserID: sql.NullString{String: subjectUserID, Valid: true},
+		BeforeID:      beforeID,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	events := make([]audit_domain.Event, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		events = append(events, audit_domain.Event{
+			ID:            dbEvent.ID,
+			Actor:         dbEvent.Actor,
+			SubjectUserID: stringPtr(dbEvent.SubjectUserID),
+			Action:        dbEvent.Action,
+			Result:        dbEvent.Result,
+			IPAddress:     stringPtr(dbEvent.IpAddress),
+			UserAgent:     stringPtr(dbEvent.UserAgent),
+			Detail:        stringPtr(dbEvent.Detail),
+			CreatedAt:     dbEvent.CreatedAt,
+		})
+	}
+
+	return events, nil
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
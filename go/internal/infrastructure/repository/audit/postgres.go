@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	audit_domain "github.com/huavcjj/flux/internal/domain/audit"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+)
+
+type postgresAuditRepo struct {
+	queries *pgdb.Queries
+}
+
+var _ audit_domain.AuditRepo = (*postgresAuditRepo)(nil)
+
+func NewPostgresAuditRepo(dbConn *sql.DB) audit_domain.AuditRepo {
+	return &postgresAuditRepo{
+		queries: pgdb.New(dbConn),
+	}
+}
+
+func (r *postgresAuditRepo) CreateEvent(ctx context.Context, event *audit_domain.Event) error {
+	err := r.queries.CreateAuditEvent(ctx, pgdb.CreateAuditEventParams{
+		Actor:         event.Actor,
+		SubjectUserID: nullString(event.SubjectUserID),
+		Action:        event.Action,
+		Result:        event.Result,
+		IpAddress:     nullString(event.IPAddress),
+		UserAgent:     nullString(event.UserAgent),
+		Detail:        nullString(event.Detail),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAuditRepo) ListEventsBySubject(ctx context.Context, subjectUserID string, beforeID uint64, limit int) ([]audit_domain.Event, error) {
+	dbEvents, err := r.queries.ListAuditEventsBySubject(ctx, pgdb.ListAuditEventsBySubjectParams{
+		SubjectUserID: sql.NullString{String: subjectUserID, Valid: true},
+		BeforeID:      int64(beforeID),
+		RowLimit:      int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	events := make([]audit_domain.Event, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		events = append(events, audit_domain.Event{
+			ID:            uint64(dbEvent.ID),
+			Actor:         dbEvent.Actor,
+			SubjectUserID: stringPtr(dbEvent.SubjectUserID),
+			Action:        dbEvent.Action,
+			Result:        dbEvent.Result,
+			IPAddress:     stringPtr(dbEvent.IpAddress),
+			UserAgent:     stringPtr(dbEvent.UserAgent),
+			Detail:        stringPtr(dbEvent.Detail),
+			CreatedAt:     dbEvent.CreatedAt,
+		})
+	}
+
+	return events, nil
+}
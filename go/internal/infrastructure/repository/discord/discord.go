@@ -0,0 +1,289 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+	"github.com/huavcjj/flux/internal/infrastructure/httpclient"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+// DefaultBaseURL serves Discord webhooks.
+const DefaultBaseURL = "https://discord.com"
+
+const (
+	webhookPath = "/api/webhooks/"
+
+	noSubject = "(件名なし)"
+	// gmailColor is the accent of the embed.
+	gmailColor = 0xEA4335
+
+	maxContent     = 2000
+	maxAuthorName  = 256
+	maxTitle       = 256
+	maxDescription = 4096
+
+	requestTimeout = 10 * time.Second
+	// maxWait is how long Send waits for a rate limit to reset. Longer
+	// limits are returned as a RateLimitError so the outbox retries later.
+	maxWait = 5 * time.Second
+)
+
+type discordRepo struct {
+	baseURL string
+	client  *http.Client
+
+	mu sync.Mutex
+	// blocked holds when each webhook, by ID, may be called again. The
+	// empty ID is the global limit.
+	blocked map[string]time.Time
+}
+
+var _ notifier_domain.Notifier = (*discordRepo)(nil)
+
+// NewDiscordRepo returns a notifier posting to webhooks under baseURL.
+// Recipients are webhook URLs.
+func NewDiscordRepo(baseURL string) notifier_domain.Notifier {
+	return &discordRepo{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpclient.New(requestTimeout),
+		blocked: make(map[string]time.Time),
+	}
+}
+
+// ValidateRecipient accepts webhook URLs of the form
+// <base URL>/api/webhooks/<id>/<token>.
+func (r *discordRepo) ValidateRecipient(recipient string) error {
+	id, ok := r.webhookID(recipient)
+	if !ok {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	if u, err := url.Parse(recipient); err != nil || u.RawQuery != "" || u.Fragment != "" {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	return nil
+}
+
+// webhookID returns the ID part of a webhook URL under the base URL.
+func (r *discordRepo) webhookID(recipient string) (string, bool) {
+	rest, ok := strings.CutPrefix(recipient, r.baseURL+webhookPath)
+	if !ok {
+		return "", false
+	}
+	id, token, ok := strings.Cut(rest, "/")
+	if !ok || id == "" || token == "" || strings.Contains(token, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+func (r *discordRepo) Send(ctx context.Context, recipient string, n notifier_domain.Notification) error {
+	ctx, span := tracing.Start(ctx, "discord.Send")
+	defer span.End()
+
+	if err := r.ValidateRecipient(recipient); err != nil {
+		return tracing.Error(span, fmt.Errorf("recipient is not a webhook under %s: %w", r.baseURL, err))
+	}
+	id, _ := r.webhookID(recipient)
+
+	body, err := json.Marshal(render(n))
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to encode Discord message: %w", err))
+	}
+
+	// A rate limited request is retried once if the limit resets within
+	// maxWait; longer limits are left to the outbox.
+	for attempt := 0; ; attempt++ {
+		if err := r.wait(ctx, id); err != nil {
+			return tracing.Error(span, err)
+		}
+
+		err := r.post(ctx, id, recipient, body)
+		var rateLimit *notifier_domain.RateLimitError
+		if err == nil || attempt > 0 || !errors.As(err, &rateLimit) {
+			return tracing.Error(span, err)
+		}
+	}
+}
+
+func (r *discordRepo) post(ctx context.Context, id, recipient string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Discord request: %w", httpclient.Redact(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Discord message: %w", httpclient.Redact(err))
+	}
+	defer resp.Body.Close()
+
+	r.observe(id, resp.Header)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, global := rateLimit(resp)
+		if global {
+			id = ""
+		}
+		r.block(id, retryAfter)
+		return fmt.Errorf("discord webhook returned %s: %w", resp.Status, &notifier_domain.RateLimitError{RetryAfter: retryAfter})
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// The body is a short JSON error such as {"message": "Unknown Webhook"}.
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("discord webhook returned %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
+
+// wait blocks until neither the webhook nor the global rate limit is
+// exhausted, or returns a RateLimitError if that takes longer than maxWait.
+func (r *discordRepo) wait(ctx context.Context, id string) error {
+	r.mu.Lock()
+	until := r.blocked[""]
+	if t := r.blocked[id]; t.After(until) {
+		until = t
+	}
+	r.mu.Unlock()
+
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+	if d > maxWait {
+		return fmt.Errorf("discord webhook: %w", &notifier_domain.RateLimitError{RetryAfter: d})
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe records the bucket state Discord reports on every response.
+func (r *discordRepo) observe(id string, h http.Header) {
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	resetAfter, err := strconv.ParseFloat(h.Get("X-RateLimit-Reset-After"), 64)
+	if err != nil {
+		return
+	}
+	r.block(id, seconds(resetAfter))
+}
+
+func (r *discordRepo) block(id string, d time.Duration) {
+	until := time.Now().Add(d)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until.After(r.blocked[id]) {
+		r.blocked[id] = until
+	}
+	// Drop expired limits so the map does not grow with every webhook.
+	now := time.Now()
+	for k, t := range r.blocked {
+		if !t.After(now) {
+			delete(r.blocked, k)
+		}
+	}
+}
+
+// rateLimit reads a 429 response. The body has the precise delay; the
+// Retry-After header is the fallback.
+func rateLimit(resp *http.Response) (time.Duration, bool) {
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+		Global     bool    `json:"global"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1024)).Decode(&body)
+
+	global := body.Global || resp.Header.Get("X-RateLimit-Global") == "true" || resp.Header.Get("X-RateLimit-Scope") == "global"
+	if body.RetryAfter > 0 {
+		return seconds(body.RetryAfter), global
+	}
+	if s, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && s > 0 {
+		return seconds(s), global
+	}
+	return time.Second, global
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+type message struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []embed `json:"embeds"`
+	// AllowedMentions is empty so email content never pings anyone.
+	AllowedMentions allowedMentions `json:"allowed_mentions"`
+}
+
+type embed struct {
+	Author      *author `json:"author,omitempty"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	URL         string  `json:"url,omitempty"`
+	Color       int     `json:"color"`
+}
+
+type author struct {
+	Name string `json:"name"`
+}
+
+type allowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// render builds the embed of n: the sender as author, the subject as title
+// linking to the message and the snippet as description.
+func render(n notifier_domain.Notification) message {
+	subject := n.Subject
+	if subject == "" {
+		subject = noSubject
+	}
+
+	e := embed{
+		Title:       truncate(subject, maxTitle),
+		Description: truncate(n.Snippet, maxDescription),
+		URL:         n.Link,
+		Color:       gmailColor,
+	}
+	if n.Sender != "" {
+		e.Author = &author{Name: truncate(n.Sender, maxAuthorName)}
+	}
+
+	return message{
+		Content:         truncate(n.Title, maxContent),
+		Embeds:          []embed{e},
+		AllowedMentions: allowedMentions{Parse: []string{}},
+	}
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
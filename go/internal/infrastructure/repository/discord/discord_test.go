@@ -0,0 +1,158 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+)
+
+const webhook = "/api/webhooks/123456789/token"
+
+func TestSend(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != webhook {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := NewDiscordRepo(srv.URL).Send(context.Background(), srv.URL+webhook, notifier_domain.Notification{
+		Title:   "新着メール",
+		Sender:  "Alice <alice@example.com>",
+		Subject: "Hello @everyone",
+		Snippet: "Lunch?",
+		Link:    "https://mail.google.com/mail/#inbox/1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"content": "新着メール",
+		"embeds": []any{map[string]any{
+			"author":      map[string]any{"name": "Alice <alice@example.com>"},
+			"title":       "Hello @everyone",
+			"description": "Lunch?",
+			"url":         "https://mail.google.com/mail/#inbox/1",
+			"color":       float64(gmailColor),
+		}},
+		"allowed_mentions": map[string]any{"parse": []any{}},
+	}
+	if g, w := mustJSON(t, got), mustJSON(t, want); g != w {
+		t.Errorf("payload = %s\nwant %s", g, w)
+	}
+}
+
+func TestSendRetriesShortRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"retry_after": 0.05, "global": false}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if err := NewDiscordRepo(srv.URL).Send(context.Background(), srv.URL+webhook, notifier_domain.Notification{Title: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
+
+func TestSendRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"retry_after": 60, "global": false}`))
+	}))
+	defer srv.Close()
+
+	repo := NewDiscordRepo(srv.URL)
+	err := repo.Send(context.Background(), srv.URL+webhook, notifier_domain.Notification{Title: "x"})
+	var rateLimit *notifier_domain.RateLimitError
+	if !errors.As(err, &rateLimit) || rateLimit.RetryAfter <= 59*time.Second || rateLimit.RetryAfter > time.Minute {
+		t.Fatalf("Send = %v, want a RateLimitError of about 1m", err)
+	}
+
+	// The webhook stays blocked without another request.
+	err = repo.Send(context.Background(), srv.URL+webhook, notifier_domain.Notification{Title: "x"})
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("second Send = %v, want a RateLimitError", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
+
+func TestSendObservesExhaustedBucket(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "30")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := NewDiscordRepo(srv.URL)
+	if err := repo.Send(context.Background(), srv.URL+webhook, notifier_domain.Notification{Title: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	err := repo.Send(context.Background(), srv.URL+webhook, notifier_domain.Notification{Title: "x"})
+	var rateLimit *notifier_domain.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("Send = %v, want a RateLimitError", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
+
+func TestValidateRecipient(t *testing.T) {
+	repo := NewDiscordRepo(DefaultBaseURL)
+	tests := []struct {
+		recipient string
+		valid     bool
+	}{
+		{"https://discord.com/api/webhooks/123/token", true},
+		{"https://discord.com/api/webhooks/abc/token", false},
+		{"https://discord.com/api/webhooks/123/", false},
+		{"https://discord.com/api/webhooks/123/token/extra", false},
+		{"https://discord.com/api/webhooks/123/token?wait=true", false},
+		{"https://example.com/api/webhooks/123/token", false},
+	}
+	for _, tt := range tests {
+		if err := repo.ValidateRecipient(tt.recipient); (err == nil) != tt.valid {
+			t.Errorf("ValidateRecipient(%q) = %v, want valid %v", tt.recipient, err, tt.valid)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
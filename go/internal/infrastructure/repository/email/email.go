@@ -7,10 +7,15 @@ import (
 	"time"
 
 	email_domain "github.com/huavcjj/flux/internal/domain/email"
+	hook_domain "github.com/huavcjj/flux/internal/domain/hook"
+	outbox_domain "github.com/huavcjj/flux/internal/domain/outbox"
 	"github.com/huavcjj/flux/internal/infrastructure/db"
+	hookrepo "github.com/huavcjj/flux/internal/infrastructure/repository/hook"
+	outboxrepo "github.com/huavcjj/flux/internal/infrastructure/repository/outbox"
 )
 
 type emailRepo struct {
+	db      *sql.DB
 	queries *db.Queries
 }
 
@@ -18,11 +23,54 @@ var _ email_domain.EmailRepo = (*emailRepo)(nil)
 
 func NewEmailRepo(dbConn *sql.DB) email_domain.EmailRepo {
 	return &emailRepo{
+		db:      dbConn,
 		queries: db.New(dbConn),
 	}
 }
 
 func (r *emailRepo) CreateEmail(ctx context.Context, email *email_domain.Email) error {
+	_, err := r.createEmail(ctx, r.queries, email)
+	return err
+}
+
+func (r *emailRepo) CreateEmailWithOutbox(ctx context.Context, email *email_domain.Email, entries []*outbox_domain.Entry, deliveries []*hook_domain.Delivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	emailID, err := r.createEmail(ctx, qtx, email)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entry.UserID = email.UserID
+		entry.EmailID = &emailID
+		if err := outboxrepo.CreateEntry(ctx, qtx, entry); err != nil {
+			return err
+		}
+	}
+
+	for _, delivery := range deliveries {
+		delivery.UserID = email.UserID
+		if err := hookrepo.CreateDelivery(ctx, qtx, delivery); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit email: %w", err)
+	}
+
+	email.ID = emailID
+	return nil
+}
+
+func (r *emailRepo) createEmail(ctx context.Context, q *db.Queries, email *email_domain.Email) (uint64, error) {
 	var subject, bodyPreview sql.NullString
 
 	if email.Subject != nil {
@@ -32,7 +80,7 @@ func (r *emailRepo) CreateEmail(ctx context.Context, email *email_domain.Email)
 		bodyPreview = sql.NullString{String: *email.BodyPreview, Valid: true}
 	}
 
-	_, err := r.queries.CreateEmail(ctx, db.CreateEmailParams{
+	result, err := q.CreateEmail(ctx, db.CreateEmailParams{
 		UserID:         email.UserID,
 		GmailMessageID: email.GmailMessageID,
 		SenderEmail:    email.SenderEmail,
@@ -42,10 +90,15 @@ func (r *emailRepo) CreateEmail(ctx context.Context, email *email_domain.Email)
 		IsNotified:     sql.NullBool{Bool: email.IsNotified, Valid: true},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create email: %w", err)
+		return 0, fmt.Errorf("failed to create email: %w", err)
 	}
 
-	return nil
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get email id: %w", err)
+	}
+
+	return uint64(id), nil
 }
 
 func (r *emailRepo) GetEmailByGmailMessageID(ctx context.Context, gmailMessageID string) (*email_domain.Email, error) {
@@ -60,49 +113,65 @@ func (r *emailRepo) GetEmailByGmailMessageID(ctx context.Context, gmailMessageID
 	return r.dbEmailToDomain(dbEmail), nil
 }
 
-func (r *emailRepo) GetEmailsByUserID(ctx context.Context, userID string) ([]email_domain.Email, error) {
-	dbEmails, err := r.queries.GetEmailsByUserID(ctx, userID)
+func (r *emailRepo) GetEmailsByUserID(ctx context.Context, userID string, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
+	}
+
+	after := cursorOrStart(page.Cursor)
+
+	dbEmails, err := r.queries.GetEmailsByUserID(ctx, db.GetEmailsByUserIDParams{
+		UserID:           userID,
+		CursorID:         after.ID,
+		CursorReceivedAt: after.ReceivedAt,
+		Limit:            int32(page.Limit + 1),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get emails by user id: %w", err)
 	}
 
-	emails := make([]email_domain.Email, 0, len(dbEmails))
-	for _, dbEmail := range dbEmails {
-		emails = append(emails, *r.dbEmailToDomain(dbEmail))
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *emailRepo) GetUnnotifiedEmailsByUserID(ctx context.Context, userID string, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
 	}
 
-	return emails, nil
-}
+	after := cursorOrStart(page.Cursor)
 
-func (r *emailRepo) GetUnnotifiedEmailsByUserID(ctx context.Context, userID string) ([]email_domain.Email, error) {
-	dbEmails, err := r.queries.GetUnnotifiedEmailsByUserID(ctx, userID)
+	dbEmails, err := r.queries.GetUnnotifiedEmailsByUserID(ctx, db.GetUnnotifiedEmailsByUserIDParams{
+		UserID:           userID,
+		CursorID:         after.ID,
+		CursorReceivedAt: after.ReceivedAt,
+		Limit:            int32(page.Limit + 1),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get unnotified emails by user id: %w", err)
 	}
 
-	emails := make([]email_domain.Email, 0, len(dbEmails))
-	for _, dbEmail := range dbEmails {
-		emails = append(emails, *r.dbEmailToDomain(dbEmail))
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *emailRepo) GetRecentEmails(ctx context.Context, userID string, since time.Time, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
 	}
 
-	return emails, nil
-}
+	after := cursorOrStart(page.Cursor)
 
-func (r *emailRepo) GetRecentEmails(ctx context.Context, userID string, since time.Time) ([]email_domain.Email, error) {
 	dbEmails, err := r.queries.GetRecentEmails(ctx, db.GetRecentEmailsParams{
-		UserID:     userID,
-		ReceivedAt: since,
+		UserID:           userID,
+		Since:            since,
+		CursorID:         after.ID,
+		CursorReceivedAt: after.ReceivedAt,
+		Limit:            int32(page.Limit + 1),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent emails: %w", err)
 	}
 
-	emails := make([]email_domain.Email, 0, len(dbEmails))
-	for _, dbEmail := range dbEmails {
-		emails = append(emails, *r.dbEmailToDomain(dbEmail))
-	}
-
-	return emails, nil
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
 }
 
 func (r *emailRepo) MarkEmailAsNotified(ctx context.Context, gmailMessageID string) error {
@@ -123,6 +192,57 @@ func (r *emailRepo) DeleteEmailsByUserID(ctx context.Context, userID string) err
 	return nil
 }
 
+func (r *emailRepo) SearchEmails(ctx context.Context, userID, query string, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
+	}
+
+	after := cursorOrStart(page.Cursor)
+
+	dbEmails, err := r.queries.SearchEmails(ctx, db.SearchEmailsParams{
+		UserID:           userID,
+		Query:            booleanPhrase(query),
+		CursorID:         after.ID,
+		CursorReceivedAt: after.ReceivedAt,
+		Limit:            int32(page.Limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search emails: %w", err)
+	}
+
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *emailRepo) DeleteEmailsOlderThan(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteEmailsOlderThan(ctx, db.DeleteEmailsOlderThanParams{
+		UserID:     userID,
+		ReceivedAt: before,
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old emails: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *emailRepo) ClearNotifiedEmailContent(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearNotifiedEmailContent(ctx, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear notified email content: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *emailRepo) dbEmailsToDomain(dbEmails []db.Email) []email_domain.Email {
+	emails := make([]email_domain.Email, 0, len(dbEmails))
+	for _, dbEmail := range dbEmails {
+		emails = append(emails, *r.dbEmailToDomain(dbEmail))
+	}
+	return emails
+}
+
 func (r *emailRepo) dbEmailToDomain(dbEmail db.Email) *email_domain.Email {
 	email := &email_domain.Email{
 		ID:             dbEmail.ID,
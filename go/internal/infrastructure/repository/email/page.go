@@ -0,0 +1,49 @@
+package email
+
+import (
+	"strings"
+
+	email_domain "github.com/huavcjj/flux/internal/domain/email"
+)
+
+// cursorOrStart returns the cursor to continue from. The zero cursor starts
+// at the newest email, since the queries treat an id of 0 as no cursor.
+func cursorOrStart(cursor *email_domain.Cursor) email_domain.Cursor {
+	if cursor == nil {
+		return email_domain.Cursor{}
+	}
+	return *cursor
+}
+
+// checkPage rejects page requests that cannot return a page.
+func checkPage(page email_domain.PageRequest) error {
+	if page.Limit <= 0 {
+		return email_domain.ErrInvalidLimit
+	}
+	return nil
+}
+
+// newPage trims emails, fetched with one row more than limit, to a page and
+// points Next at its last email when more rows follow.
+func newPage(emails []email_domain.Email, limit int) *email_domain.Page {
+	page := &email_domain.Page{Emails: emails}
+	if len(emails) > limit {
+		page.Emails = emails[:limit]
+		last := page.Emails[limit-1]
+		page.Next = &email_domain.Cursor{ReceivedAt: last.ReceivedAt, ID: last.ID}
+	}
+	return page
+}
+
+// booleanPhrase searches query as one phrase in MySQL boolean mode, so the
+// ngram parser matches the text in order instead of any of its bigrams.
+func booleanPhrase(query string) string {
+	return `"` + strings.ReplaceAll(query, `"`, " ") + `"`
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern builds a LIKE pattern matching query anywhere.
+func containsPattern(query string) string {
+	return "%" + likeEscaper.Replace(query) + "%"
+}
@@ -0,0 +1,43 @@
+package email
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	email_domain "github.com/huavcjj/flux/internal/domain/email"
+)
+
+func TestCheckPage(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		if err := checkPage(email_domain.PageRequest{Limit: limit}); !errors.Is(err, email_domain.ErrInvalidLimit) {
+			t.Errorf("checkPage(limit %d) = %v, want ErrInvalidLimit", limit, err)
+		}
+	}
+	if err := checkPage(email_domain.PageRequest{Limit: 1}); err != nil {
+		t.Errorf("checkPage(limit 1) = %v", err)
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	received := time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC)
+	emails := []email_domain.Email{
+		{ID: 3, ReceivedAt: received},
+		{ID: 2, ReceivedAt: received.Add(-time.Minute)},
+		{ID: 1, ReceivedAt: received.Add(-2 * time.Minute)},
+	}
+
+	page := newPage(emails, 2)
+	if len(page.Emails) != 2 {
+		t.Fatalf("got %d emails, want 2", len(page.Emails))
+	}
+	want := email_domain.Cursor{ReceivedAt: emails[1].ReceivedAt, ID: 2}
+	if page.Next == nil || *page.Next != want {
+		t.Errorf("Next = %v, want %v", page.Next, want)
+	}
+
+	last := newPage(emails, 3)
+	if len(last.Emails) != 3 || last.Next != nil {
+		t.Errorf("last page = %d emails, Next %v; want 3 emails and no Next", len(last.Emails), last.Next)
+	}
+}
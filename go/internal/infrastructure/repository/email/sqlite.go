@@ -0,0 +1,266 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	email_domain "github.com/huavcjj/flux/internal/domain/email"
+	hook_domain "github.com/huavcjj/flux/internal/domain/hook"
+	outbox_domain "github.com/huavcjj/flux/internal/domain/outbox"
+	hookrepo "github.com/huavcjj/flux/internal/infrastructure/repository/hook"
+	outboxrepo "github.com/huavcjj/flux/internal/infrastructure/repository/outbox"
+	"github.com/huavcjj/flux/internal/infrastructure/sqlitedb"
+)
+
+type sqliteEmailRepo struct {
+	db      *sql.DB
+	queries *sqlitedb.Queries
+}
+
+var _ email_domain.EmailRepo = (*sqliteEmailRepo)(nil)
+
+func NewSQLiteEmailRepo(dbConn *sql.DB) email_domain.EmailRepo {
+	return &sqliteEmailRepo{
+		db:      dbConn,
+		queries: sqlitedb.New(dbConn),
+	}
+}
+
+func (r *sqliteEmailRepo) CreateEmail(ctx context.Context, email *email_domain.Email) error {
+	_, err := r.createEmail(ctx, r.queries, email)
+	return err
+}
+
+func (r *sqliteEmailRepo) CreateEmailWithOutbox(ctx context.Context, email *email_domain.Email, entries []*outbox_domain.Entry, deliveries []*hook_domain.Delivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	emailID, err := r.createEmail(ctx, qtx, email)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entry.UserID = email.UserID
+		entry.EmailID = &emailID
+		if err := outboxrepo.CreateSQLiteEntry(ctx, qtx, entry); err != nil {
+			return err
+		}
+	}
+
+	for _, delivery := range deliveries {
+		delivery.UserID = email.UserID
+		if err := hookrepo.CreateSQLiteDelivery(ctx, qtx, delivery); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit email: %w", err)
+	}
+
+	email.ID = emailID
+	return nil
+}
+
+func (r *sqliteEmailRepo) createEmail(ctx context.Context, q *sqlitedb.Queries, email *email_domain.Email) (uint64, error) {
+	var subject, bodyPreview sql.NullString
+
+	if email.Subject != nil {
+		subject = sql.NullString{String: *email.Subject, Valid: true}
+	}
+	if email.BodyPreview != nil {
+		bodyPreview = sql.NullString{String: *email.BodyPreview, Valid: true}
+	}
+
+	result, err := q.CreateEmail(ctx, sqlitedb.CreateEmailParams{
+		UserID:         email.UserID,
+		GmailMessageID: email.GmailMessageID,
+		SenderEmail:    email.SenderEmail,
+		Subject:        subject,
+		BodyPreview:    bodyPreview,
+		ReceivedAt:     email.ReceivedAt.UTC(),
+		IsNotified:     email.IsNotified,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create email: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get email id: %w", err)
+	}
+
+	return uint64(id), nil
+}
+
+func (r *sqliteEmailRepo) GetEmailByGmailMessageID(ctx context.Context, gmailMessageID string) (*email_domain.Email, error) {
+	dbEmail, err := r.queries.GetEmailByGmailMessageID(ctx, gmailMessageID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get email by gmail message id: %w", err)
+	}
+
+	return r.dbEmailToDomain(dbEmail), nil
+}
+
+func (r *sqliteEmailRepo) GetEmailsByUserID(ctx context.Context, userID string, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
+	}
+
+	after := cursorOrStart(page.Cursor)
+
+	dbEmails, err := r.queries.GetEmailsByUserID(ctx, sqlitedb.GetEmailsByUserIDParams{
+		UserID:           userID,
+		CursorID:         int64(after.ID),
+		CursorReceivedAt: after.ReceivedAt.UTC(),
+		RowLimit:         int64(page.Limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emails by user id: %w", err)
+	}
+
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *sqliteEmailRepo) GetUnnotifiedEmailsByUserID(ctx context.Context, userID string, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
+	}
+
+	after := cursorOrStart(page.Cursor)
+
+	dbEmails, err := r.queries.GetUnnotifiedEmailsByUserID(ctx, sqlitedb.GetUnnotifiedEmailsByUserIDParams{
+		UserID:           userID,
+		CursorID:         int64(after.ID),
+		CursorReceivedAt: after.ReceivedAt.UTC(),
+		RowLimit:         int64(page.Limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unnotified emails by user id: %w", err)
+	}
+
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *sqliteEmailRepo) GetRecentEmails(ctx context.Context, userID string, since time.Time, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
+	}
+
+	after := cursorOrStart(page.Cursor)
+
+	dbEmails, err := r.queries.GetRecentEmails(ctx, sqlitedb.GetRecentEmailsParams{
+		UserID:           userID,
+		Since:            since.UTC(),
+		CursorID:         int64(after.ID),
+		CursorReceivedAt: after.ReceivedAt.UTC(),
+		RowLimit:         int64(page.Limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent emails: %w", err)
+	}
+
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *sqliteEmailRepo) MarkEmailAsNotified(ctx context.Context, gmailMessageID string) error {
+	err := r.queries.MarkEmailAsNotified(ctx, gmailMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark email as notified: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteEmailRepo) DeleteEmailsByUserID(ctx context.Context, userID string) error {
+	err := r.queries.DeleteEmailsByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete emails by user id: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteEmailRepo) SearchEmails(ctx context.Context, userID, query string, page email_domain.PageRequest) (*email_domain.Page, error) {
+	if err := checkPage(page); err != nil {
+		return nil, err
+	}
+
+	after := cursorOrStart(page.Cursor)
+
+	dbEmails, err := r.queries.SearchEmails(ctx, sqlitedb.SearchEmailsParams{
+		UserID:           userID,
+		Query:            query,
+		CursorID:         int64(after.ID),
+		CursorReceivedAt: after.ReceivedAt.UTC(),
+		RowLimit:         int64(page.Limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search emails: %w", err)
+	}
+
+	return newPage(r.dbEmailsToDomain(dbEmails), page.Limit), nil
+}
+
+func (r *sqliteEmailRepo) DeleteEmailsOlderThan(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteEmailsOlderThan(ctx, sqlitedb.DeleteEmailsOlderThanParams{
+		UserID:     userID,
+		ReceivedAt: before.UTC(),
+		Limit:      int64(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old emails: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *sqliteEmailRepo) ClearNotifiedEmailContent(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearNotifiedEmailContent(ctx, int64(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear notified email content: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *sqliteEmailRepo) dbEmailsToDomain(dbEmails []sqlitedb.Email) []email_domain.Email {
+	emails := make([]email_domain.Email, 0, len(dbEmails))
+	for _, dbEmail := range dbEmails {
+		emails = append(emails, *r.dbEmailToDomain(dbEmail))
+	}
+	return emails
+}
+
+func (r *sqliteEmailRepo) dbEmailToDomain(dbEmail sqlitedb.Email) *email_domain.Email {
+	email := &email_domain.Email{
+		ID:             uint64(dbEmail.ID),
+		UserID:         dbEmail.UserID,
+		GmailMessageID: dbEmail.GmailMessageID,
+		SenderEmail:    dbEmail.SenderEmail,
+		ReceivedAt:     dbEmail.ReceivedAt,
+		IsNotified:     dbEmail.IsNotified,
+		CreatedAt:      dbEmail.CreatedAt,
+		UpdatedAt:      dbEmail.UpdatedAt,
+	}
+
+	if dbEmail.Subject.Valid {
+		email.Subject = &dbEmail.Subject.String
+	}
+	if dbEmail.BodyPreview.Valid {
+		email.BodyPreview = &dbEmail.BodyPreview.String
+	}
+
+	return email
+}
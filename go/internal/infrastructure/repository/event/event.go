@@ -0,0 +1,78 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	event_domain "github.com/huavcjj/flux/internal/domain/event"
+	"github.com/huavcjj/flux/internal/infrastructure/db"
+)
+
+const pruneInterval = time.Hour
+
+// eventRepo keeps recently seen event IDs in memory and falls back to the
+// database so that duplicates are detected across restarts and instances.
+type eventRepo struct {
+	queries *db.Queries
+	cache   *memoryEventRepo
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+var _ event_domain.EventRepo = (*eventRepo)(nil)
+
+func NewEventRepo(dbConn *sql.DB) event_domain.EventRepo {
+	return &eventRepo{
+		queries: db.New(dbConn),
+		cache:   newMemoryEventRepo(),
+	}
+}
+
+func (r *eventRepo) MarkProcessed(ctx context.Context, eventID string, ttl time.Duration) (bool, error) {
+	first, err := r.cache.MarkProcessed(ctx, eventID, ttl)
+	if err != nil {
+		return false, err
+	}
+	if !first {
+		return false, nil
+	}
+
+	r.pruneExpired(ctx)
+
+	result, err := r.queries.CreateWebhookEvent(ctx, db.CreateWebhookEventParams{
+		EventID:   eventID,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+
+	// ON DUPLICATE KEY UPDATE reports 1 for a new row, 2 when an expired row
+	// was refreshed and 0 when a live row already existed.
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *eventRepo) pruneExpired(ctx context.Context) {
+	r.mu.Lock()
+	now := time.Now()
+	if now.Sub(r.lastPrune) < pruneInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastPrune = now
+	r.mu.Unlock()
+
+	if err := r.queries.DeleteExpiredWebhookEvents(ctx, now); err != nil {
+		slog.Warn("failed to prune expired webhook events", "error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	event_domain "github.com/huavcjj/flux/internal/domain/event"
+)
+
+const memorySweepInterval = time.Minute
+
+type memoryEventRepo struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	lastSweep time.Time
+}
+
+var _ event_domain.EventRepo = (*memoryEventRepo)(nil)
+
+func NewMemoryEventRepo() event_domain.EventRepo {
+	return newMemoryEventRepo()
+}
+
+func newMemoryEventRepo() *memoryEventRepo {
+	return &memoryEventRepo{
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+func (r *memoryEventRepo) MarkProcessed(ctx context.Context, eventID string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.sweep(now)
+
+	if expiresAt, ok := r.expiresAt[eventID]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	r.expiresAt[eventID] = now.Add(ttl)
+	return true, nil
+}
+
+func (r *memoryEventRepo) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	for eventID, expiresAt := range r.expiresAt {
+		if !now.Before(expiresAt) {
+			delete(r.expiresAt, eventID)
+		}
+	}
+	r.lastSweep = now
+}
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	event_domain "github.com/huavcjj/flux/internal/domain/event"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+)
+
+type postgresEventRepo struct {
+	queries *pgdb.Queries
+	cache   *memoryEventRepo
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+var _ event_domain.EventRepo = (*postgresEventRepo)(nil)
+
+func NewPostgresEventRepo(dbConn *sql.DB) event_domain.EventRepo {
+	return &postgresEventRepo{
+		queries: pgdb.New(dbConn),
+		cache:   newMemoryEventRepo(),
+	}
+}
+
+func (r *postgresEventRepo) MarkProcessed(ctx context.Context, eventID string, ttl time.Duration) (bool, error) {
+	first, err := r.cache.MarkProcessed(ctx, eventID, ttl)
+	if err != nil {
+		return false, err
+	}
+	if !first {
+		return false, nil
+	}
+
+	r.pruneExpired(ctx)
+
+	result, err := r.queries.CreateWebhookEvent(ctx, pgdb.CreateWebhookEventParams{
+		EventID:   eventID,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+
+	// The upsert changes no row when a live event already exists.
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *postgresEventRepo) pruneExpired(ctx context.Context) {
+	r.mu.Lock()
+	now := time.Now()
+	if now.Sub(r.lastPrune) < pruneInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastPrune = now
+	r.mu.Unlock()
+
+	if err := r.queries.DeleteExpiredWebhookEvents(ctx, now); err != nil {
+		slog.Warn("failed to prune expired webhook events", "error", err)
+	}
+}
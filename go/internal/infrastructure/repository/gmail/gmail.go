@@ -2,44 +2,77 @@ package gmail
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	gmail_repo "github.com/huavcjj/flux/internal/domain/gmail"
+	mail_domain "github.com/huavcjj/flux/internal/domain/mail"
+	"github.com/huavcjj/flux/internal/metrics"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
+const (
+	// revokeURL is Google's OAuth 2.0 token revocation endpoint.
+	revokeURL = "https://oauth2.googleapis.com/revoke"
+
+	messageURL = "https://mail.google.com/mail/#all/"
+)
+
 type gmailRepo struct {
 	config *oauth2.Config
 	ctx    context.Context
+	client *http.Client
+	// topicName receives the watch notifications of linked mailboxes.
+	topicName string
 }
 
-var _ gmail_repo.GmailRepo = (*gmailRepo)(nil)
+var _ mail_domain.MailProvider = (*gmailRepo)(nil)
 
-func NewGmailRepo(ctx context.Context, credentialsPath string) (gmail_repo.GmailRepo, error) {
+func NewGmailRepo(ctx context.Context, credentialsPath, topicName string) (mail_domain.MailProvider, error) {
 	b, err := os.ReadFile(credentialsPath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read credentials file: %w", err)
 	}
 
-	config, err := google.ConfigFromJSON(b, gmail.GmailReadonlyScope)
+	// Modify scope is needed to mark messages as read from LINE.
+	config, err := google.ConfigFromJSON(b, gmail.GmailModifyScope)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse credentials: %w", err)
 	}
 
+	// Requests are traced as children of the span in their request context.
+	client := &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+
 	return &gmailRepo{
-		config: config,
-		ctx:    ctx,
+		config:    config,
+		ctx:       ctx,
+		client:    client,
+		topicName: topicName,
 	}, nil
 }
 
 // getServiceWithToken creates a Gmail service using the provided OAuth token
+// getServiceWithToken never refreshes token, so a refreshed token cannot get
+// lost; the caller refreshes it through RefreshToken.
 func (r *gmailRepo) getServiceWithToken(token *oauth2.Token) (*gmail.Service, error) {
-	client := r.config.Client(r.ctx, token)
+	client := oauth2.NewClient(r.ctx, oauth2.StaticTokenSource(token))
 	srv, err := gmail.NewService(r.ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create gmail service: %w", err)
@@ -47,31 +80,10 @@ func (r *gmailRepo) getServiceWithToken(token *oauth2.Token) (*gmail.Service, er
 	return srv, nil
 }
 
-func (r *gmailRepo) GetLatestMessages(ctx context.Context, token *oauth2.Token, maxResults int64) ([]*gmail_repo.Message, error) {
-	service, err := r.getServiceWithToken(token)
-	if err != nil {
-		return nil, err
-	}
-
-	user := "me"
-	msgs, err := service.Users.Messages.List(user).MaxResults(maxResults).Do()
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve messages: %w", err)
-	}
-
-	var messages []*gmail_repo.Message
-	for _, m := range msgs.Messages {
-		msg, err := r.GetMessage(ctx, token, m.Id)
-		if err != nil {
-			return nil, err
-		}
-		messages = append(messages, msg)
-	}
-
-	return messages, nil
-}
+func (r *gmailRepo) GetUnreadMessages(ctx context.Context, token *oauth2.Token, maxResults int64) ([]*mail_domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "gmail.GetUnreadMessages")
+	defer span.End()
 
-func (r *gmailRepo) GetUnreadMessages(ctx context.Context, token *oauth2.Token, maxResults int64) ([]*gmail_repo.Message, error) {
 	service, err := r.getServiceWithToken(token)
 	if err != nil {
 		return nil, err
@@ -79,15 +91,17 @@ func (r *gmailRepo) GetUnreadMessages(ctx context.Context, token *oauth2.Token,
 
 	user := "me"
 	// Use label filtering instead of query to get only unread messages
-	msgs, err := service.Users.Messages.List(user).LabelIds("UNREAD").MaxResults(maxResults).Do()
+	msgs, err := service.Users.Messages.List(user).LabelIds("UNREAD").MaxResults(maxResults).Context(ctx).Do()
+	observeCall(span, "messages.list", err)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve unread messages: %w", err)
 	}
 
-	var messages []*gmail_repo.Message
+	var messages []*mail_domain.Message
 	for _, m := range msgs.Messages {
 		// Get message with minimal format to verify labels
-		minimalMsg, err := service.Users.Messages.Get(user, m.Id).Format("minimal").Do()
+		minimalMsg, err := service.Users.Messages.Get(user, m.Id).Format("minimal").Context(ctx).Do()
+		observeCall(span, "messages.get", err)
 		if err != nil {
 			continue
 		}
@@ -115,14 +129,88 @@ func (r *gmailRepo) GetUnreadMessages(ctx context.Context, token *oauth2.Token,
 	return messages, nil
 }
 
-func (r *gmailRepo) GetMessage(ctx context.Context, token *oauth2.Token, messageID string) (*gmail_repo.Message, error) {
+func (r *gmailRepo) ListMessages(ctx context.Context, token *oauth2.Token, opts mail_domain.ListOptions) (*mail_domain.MessageList, error) {
+	ctx, span := tracing.Start(ctx, "gmail.ListMessages", attribute.Bool("gmail.unread_only", opts.UnreadOnly))
+	defer span.End()
+
+	service, err := r.getServiceWithToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user := "me"
+	call := service.Users.Messages.List(user).MaxResults(opts.MaxResults)
+	if opts.UnreadOnly {
+		call = call.LabelIds("UNREAD")
+	}
+	if opts.PageToken != "" {
+		call = call.PageToken(opts.PageToken)
+	}
+	if !opts.After.IsZero() {
+		call = call.Q(fmt.Sprintf("after:%d", opts.After.Unix()))
+	}
+
+	msgs, err := call.Context(ctx).Do()
+	observeCall(span, "messages.list", err)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve messages: %w", err)
+	}
+
+	list := &mail_domain.MessageList{NextPageToken: msgs.NextPageToken}
+	for _, m := range msgs.Messages {
+		msg, err := r.GetMessage(ctx, token, m.Id)
+		if err != nil {
+			continue // Skip messages we can't retrieve
+		}
+		list.Messages = append(list.Messages, msg)
+	}
+
+	return list, nil
+}
+
+func (r *gmailRepo) MarkAsRead(ctx context.Context, token *oauth2.Token, messageIDs []string) error {
+	ctx, span := tracing.Start(ctx, "gmail.MarkAsRead", tracing.MessageCount(len(messageIDs)))
+	defer span.End()
+
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	service, err := r.getServiceWithToken(token)
+	if err != nil {
+		return err
+	}
+
+	user := "me"
+	req := &gmail.BatchModifyMessagesRequest{
+		Ids:            messageIDs,
+		RemoveLabelIds: []string{"UNREAD"},
+	}
+	err = service.Users.Messages.BatchModify(user, req).Context(ctx).Do()
+	observeCall(span, "messages.batchModify", err)
+	// Mailboxes linked with the readonly scope may not modify labels.
+	if isInsufficientScope(err) {
+		return fmt.Errorf("unable to mark messages as read: %w", mail_domain.ErrInsufficientScope)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to mark messages as read: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gmailRepo) GetMessage(ctx context.Context, token *oauth2.Token, messageID string) (*mail_domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "gmail.GetMessage", tracing.MessageID(messageID))
+	defer span.End()
+
 	service, err := r.getServiceWithToken(token)
 	if err != nil {
 		return nil, err
 	}
 
 	user := "me"
-	msg, err := service.Users.Messages.Get(user, messageID).Format("full").Do()
+	msg, err := service.Users.Messages.Get(user, messageID).Format("full").Context(ctx).Do()
+	observeCall(span, "messages.get", err)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve message: %w", err)
 	}
@@ -147,12 +235,14 @@ func (r *gmailRepo) GetMessage(ctx context.Context, token *oauth2.Token, message
 		}
 	}
 
+	span.SetAttributes(tracing.SenderDomain(from))
+
 	snippet := msg.Snippet
 	if len(snippet) > 100 {
 		snippet = snippet[:100] + "..."
 	}
 
-	return &gmail_repo.Message{
+	return &mail_domain.Message{
 		ID:       msg.Id,
 		ThreadID: msg.ThreadId,
 		From:     from,
@@ -160,34 +250,115 @@ func (r *gmailRepo) GetMessage(ctx context.Context, token *oauth2.Token, message
 		Subject:  subject,
 		Snippet:  snippet,
 		Date:     date,
+		Link:     messageURL + msg.Id,
 	}, nil
 }
 
-func (r *gmailRepo) WatchMailbox(ctx context.Context, token *oauth2.Token, topicName string) error {
+// WatchMailbox starts or replaces the watch of the mailbox, so watchID is
+// not needed.
+func (r *gmailRepo) WatchMailbox(ctx context.Context, token *oauth2.Token, watchID string) (*mail_domain.Watch, error) {
+	ctx, span := tracing.Start(ctx, "gmail.WatchMailbox")
+	defer span.End()
+
 	service, err := r.getServiceWithToken(token)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	user := "me"
 	watchRequest := &gmail.WatchRequest{
-		TopicName:         topicName,
+		TopicName:         r.topicName,
 		LabelIds:          []string{"INBOX"},
 		LabelFilterAction: "include",
 	}
 
-	_, err = service.Users.Watch(user, watchRequest).Do()
+	resp, err := service.Users.Watch(user, watchRequest).Context(ctx).Do()
+	observeCall(span, "watch", err)
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch mailbox: %w", err)
+	}
+
+	return &mail_domain.Watch{
+		HistoryID:  resp.HistoryId,
+		Cursor:     strconv.FormatUint(resp.HistoryId, 10),
+		Expiration: time.UnixMilli(resp.Expiration),
+	}, nil
+}
+
+func (r *gmailRepo) StopWatch(ctx context.Context, token *oauth2.Token, watchID string) error {
+	ctx, span := tracing.Start(ctx, "gmail.StopWatch")
+	defer span.End()
+
+	service, err := r.getServiceWithToken(token)
+	if err != nil {
+		return err
+	}
+
+	user := "me"
+	err = service.Users.Stop(user).Context(ctx).Do()
+	observeCall(span, "stop", err)
 	if err != nil {
-		return fmt.Errorf("unable to watch mailbox: %w", err)
+		return fmt.Errorf("unable to stop mailbox watch: %w", err)
 	}
 
 	return nil
 }
 
+// RevokeToken revokes the grant behind token, which also invalidates the
+// refresh token.
+func (r *gmailRepo) RevokeToken(ctx context.Context, token *oauth2.Token) error {
+	ctx, span := tracing.Start(ctx, "gmail.RevokeToken")
+	defer span.End()
+
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return fmt.Errorf("unable to create revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := r.client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status %s", resp.Status)
+		}
+	}
+	observeCall(span, "revoke", err)
+	if err != nil {
+		return fmt.Errorf("unable to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// observeCall records the outcome of a Gmail API call on span and in metrics.
+func observeCall(span trace.Span, method string, err error) {
+	tracing.RecordError(span, err)
+	metrics.ObserveGmailCall(method, err)
+}
+
 func (r *gmailRepo) GetAuthURL(state string) string {
 	return r.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
 }
 
+func (r *gmailRepo) RefreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	_, span := tracing.Start(ctx, "gmail.RefreshToken")
+	defer span.End()
+
+	// Without an access token the source always asks for a new one.
+	refreshed, err := r.config.TokenSource(r.ctx, &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+	observeCall(span, "token.refresh", err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return refreshed, nil
+}
+
 func (r *gmailRepo) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
 	token, err := r.config.Exchange(ctx, code)
 	if err != nil {
@@ -196,7 +367,11 @@ func (r *gmailRepo) ExchangeCode(ctx context.Context, code string) (*oauth2.Toke
 	return token, nil
 }
 
-func (r *gmailRepo) GetHistoryMessages(ctx context.Context, token *oauth2.Token, startHistoryID uint64) ([]*gmail_repo.Message, error) {
+// GetChanges follows the mailbox history from the history ID in cursor.
+func (r *gmailRepo) GetChanges(ctx context.Context, token *oauth2.Token, cursor string) (*mail_domain.Changes, error) {
+	ctx, span := tracing.Start(ctx, "gmail.GetChanges")
+	defer span.End()
+
 	service, err := r.getServiceWithToken(token)
 	if err != nil {
 		return nil, err
@@ -204,40 +379,80 @@ func (r *gmailRepo) GetHistoryMessages(ctx context.Context, token *oauth2.Token,
 
 	user := "me"
 
-	// Get history since the given history ID
-	historyList, err := service.Users.History.List(user).StartHistoryId(startHistoryID).Do()
+	if cursor == "" {
+		profile, err := service.Users.GetProfile(user).Context(ctx).Do()
+		observeCall(span, "getProfile", err)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve profile: %w", err)
+		}
+		return &mail_domain.Changes{Cursor: strconv.FormatUint(profile.HistoryId, 10)}, nil
+	}
+
+	startHistoryID, err := strconv.ParseUint(cursor, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve history: %w", err)
+		return nil, fmt.Errorf("invalid history cursor %q: %w", cursor, err)
 	}
 
-	// Extract unique message IDs from history
+	// Collect the unread messages added since the start of the history
 	messageIDs := make(map[string]bool)
-	for _, history := range historyList.History {
-		// Check for messages added
-		for _, msgAdded := range history.MessagesAdded {
-			// Only include messages with UNREAD label
-			hasUnread := false
-			for _, labelID := range msgAdded.Message.LabelIds {
-				if labelID == "UNREAD" {
-					hasUnread = true
-					break
+	call := service.Users.History.List(user).StartHistoryId(startHistoryID).HistoryTypes("messageAdded").LabelId("INBOX")
+	historyID := startHistoryID
+	for {
+		historyList, err := call.Context(ctx).Do()
+		observeCall(span, "history.list", err)
+		// Gmail keeps the history for about a week.
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, fmt.Errorf("unable to retrieve history: %w", mail_domain.ErrCursorExpired)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve history: %w", err)
+		}
+		historyID = historyList.HistoryId
+
+		for _, history := range historyList.History {
+			for _, msgAdded := range history.MessagesAdded {
+				if slices.Contains(msgAdded.Message.LabelIds, "UNREAD") {
+					messageIDs[msgAdded.Message.Id] = true
 				}
 			}
-			if hasUnread {
-				messageIDs[msgAdded.Message.Id] = true
-			}
 		}
+
+		if historyList.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(historyList.NextPageToken)
 	}
 
 	// Fetch full message details
-	var messages []*gmail_repo.Message
+	changes := &mail_domain.Changes{Cursor: strconv.FormatUint(historyID, 10)}
 	for msgID := range messageIDs {
 		msg, err := r.GetMessage(ctx, token, msgID)
+		// Messages deleted since they arrived are skipped.
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			continue
+		}
 		if err != nil {
-			continue // Skip messages we can't retrieve
+			return nil, err
 		}
-		messages = append(messages, msg)
+		changes.Messages = append(changes.Messages, msg)
 	}
 
-	return messages, nil
+	return changes, nil
+}
+
+// isInsufficientScope reports whether err rejects a call the token has no
+// scope for.
+func isInsufficientScope(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "insufficientPermissions" {
+			return true
+		}
+	}
+	return strings.Contains(apiErr.Message, "insufficient authentication scopes")
 }
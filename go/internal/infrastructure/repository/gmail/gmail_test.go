@@ -0,0 +1,43 @@
+package gmail
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsInsufficientScope(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"other error", errors.New("boom"), false},
+		{"reason", &googleapi.Error{
+			Code:   http.StatusForbidden,
+			Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}},
+		}, true},
+		{"message", fmt.Errorf("wrapped: %w", &googleapi.Error{
+			Code:    http.StatusForbidden,
+			Message: "Request had insufficient authentication scopes.",
+		}), true},
+		{"other forbidden", &googleapi.Error{
+			Code:   http.StatusForbidden,
+			Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}},
+		}, false},
+		{"unauthorized", &googleapi.Error{
+			Code:   http.StatusUnauthorized,
+			Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isInsufficientScope(tt.err); got != tt.want {
+			t.Errorf("%s: isInsufficientScope = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package hook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	hook_domain "github.com/huavcjj/flux/internal/domain/hook"
+	"github.com/huavcjj/flux/internal/infrastructure/db"
+)
+
+type hookRepo struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+var _ hook_domain.HookRepo = (*hookRepo)(nil)
+
+func NewHookRepo(dbConn *sql.DB) hook_domain.HookRepo {
+	return &hookRepo{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *hookRepo) GetEndpointByUserID(ctx context.Context, userID string) (*hook_domain.Endpoint, error) {
+	dbEndpoint, err := r.queries.GetWebhookEndpointByUserID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return r.dbEndpointToDomain(dbEndpoint), nil
+}
+
+func (r *hookRepo) GetEndpoint(ctx context.Context, id uint64) (*hook_domain.Endpoint, error) {
+	dbEndpoint, err := r.queries.GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return r.dbEndpointToDomain(dbEndpoint), nil
+}
+
+func (r *hookRepo) SaveEndpoint(ctx context.Context, endpoint *hook_domain.Endpoint) error {
+	err := r.queries.UpsertWebhookEndpoint(ctx, db.UpsertWebhookEndpointParams{
+		UserID:    endpoint.UserID,
+		Url:       endpoint.URL,
+		Secret:    endpoint.Secret,
+		CreatedBy: endpoint.CreatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save webhook endpoint: %w", err)
+	}
+
+	saved, err := r.GetEndpointByUserID(ctx, endpoint.UserID)
+	if err != nil {
+		return err
+	}
+	*endpoint = *saved
+	return nil
+}
+
+func (r *hookRepo) DeleteEndpoint(ctx context.Context, userID string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	// The endpoint is the user's only one, so these are its deliveries.
+	if err := qtx.DeleteWebhookDeliveriesByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	n, err := qtx.DeleteWebhookEndpointByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit webhook endpoint deletion: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (r *hookRepo) CreateDelivery(ctx context.Context, delivery *hook_domain.Delivery) error {
+	return CreateDelivery(ctx, r.queries, delivery)
+}
+
+func (r *hookRepo) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]hook_domain.Delivery, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbDeliveries, err := qtx.GetDueWebhookDeliveriesForUpdate(ctx, db.GetDueWebhookDeliveriesForUpdateParams{
+		NextAttemptAt: now,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]hook_domain.Delivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		if err := qtx.LeaseWebhookDelivery(ctx, db.LeaseWebhookDeliveryParams{
+			NextAttemptAt: leaseUntil,
+			ID:            dbDelivery.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lease webhook delivery: %w", err)
+		}
+
+		delivery := r.dbDeliveryToDomain(dbDelivery)
+		delivery.Attempts++
+		delivery.NextAttemptAt = leaseUntil
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook delivery claim: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *hookRepo) MarkDelivered(ctx context.Context, id uint64, responseStatus int, deliveredAt time.Time) error {
+	err := r.queries.MarkWebhookDeliveryDelivered(ctx, db.MarkWebhookDeliveryDeliveredParams{
+		ResponseStatus: sql.NullInt32{Int32: int32(responseStatus), Valid: true},
+		DeliveredAt:    sql.NullTime{Time: deliveredAt, Valid: true},
+		ID:             id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *hookRepo) Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, responseStatus *int, lastError string) error {
+	err := r.queries.RescheduleWebhookDelivery(ctx, db.RescheduleWebhookDeliveryParams{
+		NextAttemptAt:  nextAttemptAt,
+		ResponseStatus: nullInt32(responseStatus),
+		LastError:      sql.NullString{String: lastError, Valid: true},
+		ID:             id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *hookRepo) MarkFailed(ctx context.Context, id uint64, responseStatus *int, lastError string) error {
+	err := r.queries.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+		ResponseStatus: nullInt32(responseStatus),
+		LastError:      sql.NullString{String: lastError, Valid: true},
+		ID:             id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *hookRepo) GetDelivery(ctx context.Context, userID string, id uint64) (*hook_domain.Delivery, error) {
+	dbDelivery, err := r.queries.GetWebhookDelivery(ctx, db.GetWebhookDeliveryParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return r.dbDeliveryToDomain(dbDelivery), nil
+}
+
+func (r *hookRepo) ListDeliveries(ctx context.Context, userID string, beforeID uint64, limit int) ([]hook_domain.Delivery, error) {
+	dbDeliveries, err := r.queries.ListWebhookDeliveriesByUserID(ctx, db.ListWebhookDeliveriesByUserIDParams{
+		UserID:   userID,
+		BeforeID: beforeID,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]hook_domain.Delivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, *r.dbDeliveryToDomain(dbDelivery))
+	}
+
+	return deliveries, nil
+}
+
+func (r *hookRepo) DeleteFinishedDeliveries(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteFinishedWebhookDeliveries(ctx, db.DeleteFinishedWebhookDeliveriesParams{
+		UserID:    userID,
+		CreatedAt: sql.NullTime{Time: before, Valid: true},
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *hookRepo) ClearDeliveredPayloads(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearDeliveredWebhookPayloads(ctx, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear delivered webhook payloads: %w", err)
+	}
+
+	return n, nil
+}
+
+// CreateDelivery stores delivery with q, which may be bound to the caller's
+// transaction.
+func CreateDelivery(ctx context.Context, q *db.Queries, delivery *hook_domain.Delivery) error {
+	var redeliveryOf sql.NullInt64
+	if delivery.RedeliveryOf != nil {
+		redeliveryOf = sql.NullInt64{Int64: int64(*delivery.RedeliveryOf), Valid: true}
+	}
+
+	result, err := q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		EndpointID:   delivery.EndpointID,
+		UserID:       delivery.UserID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Payload:      delivery.Payload,
+		RedeliveryOf: redeliveryOf,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery id: %w", err)
+	}
+	delivery.ID = uint64(id)
+	delivery.Status = hook_domain.StatusPending
+	return nil
+}
+
+func (r *hookRepo) dbEndpointToDomain(dbEndpoint db.WebhookEndpoint) *hook_domain.Endpoint {
+	return &hook_domain.Endpoint{
+		ID:        dbEndpoint.ID,
+		UserID:    dbEndpoint.UserID,
+		URL:       dbEndpoint.Url,
+		Secret:    dbEndpoint.Secret,
+		CreatedBy: dbEndpoint.CreatedBy,
+		CreatedAt: dbEndpoint.CreatedAt.Time,
+		UpdatedAt: dbEndpoint.UpdatedAt.Time,
+	}
+}
+
+func (r *hookRepo) dbDeliveryToDomain(dbDelivery db.WebhookDelivery) *hook_domain.Delivery {
+	delivery := &hook_domain.Delivery{
+		ID:            dbDelivery.ID,
+		EndpointID:    dbDelivery.EndpointID,
+		UserID:        dbDelivery.UserID,
+		EventID:       dbDelivery.EventID,
+		EventType:     dbDelivery.EventType,
+		Payload:       dbDelivery.Payload,
+		Status:        dbDelivery.Status,
+		Attempts:      int(dbDelivery.Attempts),
+		NextAttemptAt: dbDelivery.NextAttemptAt,
+		CreatedAt:     dbDelivery.CreatedAt.Time,
+		UpdatedAt:     dbDelivery.UpdatedAt.Time,
+	}
+
+	if dbDelivery.ResponseStatus.Valid {
+		status := int(dbDelivery.ResponseStatus.Int32)
+		delivery.ResponseStatus = &status
+	}
+	if dbDelivery.LastError.Valid {
+		delivery.LastError = &dbDelivery.LastError.String
+	}
+	if dbDelivery.DeliveredAt.Valid {
+		delivery.DeliveredAt = &dbDelivery.DeliveredAt.Time
+	}
+	if dbDelivery.RedeliveryOf.Valid {
+		redeliveryOf := uint64(dbDelivery.RedeliveryOf.Int64)
+		delivery.RedeliveryOf = &redeliveryOf
+	}
+
+	return delivery
+}
+
+func nullInt32(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
+}
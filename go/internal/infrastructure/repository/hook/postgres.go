@@ -0,0 +1,294 @@
+package hook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	hook_domain "github.com/huavcjj/flux/internal/domain/hook"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+)
+
+type postgresHookRepo struct {
+	db      *sql.DB
+	queries *pgdb.Queries
+}
+
+var _ hook_domain.HookRepo = (*postgresHookRepo)(nil)
+
+func NewPostgresHookRepo(dbConn *sql.DB) hook_domain.HookRepo {
+	return &postgresHookRepo{
+		db:      dbConn,
+		queries: pgdb.New(dbConn),
+	}
+}
+
+func (r *postgresHookRepo) GetEndpointByUserID(ctx context.Context, userID string) (*hook_domain.Endpoint, error) {
+	dbEndpoint, err := r.queries.GetWebhookEndpointByUserID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return r.dbEndpointToDomain(dbEndpoint), nil
+}
+
+func (r *postgresHookRepo) GetEndpoint(ctx context.Context, id uint64) (*hook_domain.Endpoint, error) {
+	dbEndpoint, err := r.queries.GetWebhookEndpoint(ctx, int64(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return r.dbEndpointToDomain(dbEndpoint), nil
+}
+
+func (r *postgresHookRepo) SaveEndpoint(ctx context.Context, endpoint *hook_domain.Endpoint) error {
+	err := r.queries.UpsertWebhookEndpoint(ctx, pgdb.UpsertWebhookEndpointParams{
+		UserID:    endpoint.UserID,
+		Url:       endpoint.URL,
+		Secret:    endpoint.Secret,
+		CreatedBy: endpoint.CreatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save webhook endpoint: %w", err)
+	}
+
+	saved, err := r.GetEndpointByUserID(ctx, endpoint.UserID)
+	if err != nil {
+		return err
+	}
+	*endpoint = *saved
+	return nil
+}
+
+func (r *postgresHookRepo) DeleteEndpoint(ctx context.Context, userID string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	// The endpoint is the user's only one, so these are its deliveries.
+	if err := qtx.DeleteWebhookDeliveriesByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	n, err := qtx.DeleteWebhookEndpointByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit webhook endpoint deletion: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (r *postgresHookRepo) CreateDelivery(ctx context.Context, delivery *hook_domain.Delivery) error {
+	return CreatePostgresDelivery(ctx, r.queries, delivery)
+}
+
+func (r *postgresHookRepo) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]hook_domain.Delivery, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbDeliveries, err := qtx.GetDueWebhookDeliveriesForUpdate(ctx, pgdb.GetDueWebhookDeliveriesForUpdateParams{
+		NextAttemptAt: now,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]hook_domain.Delivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		if err := qtx.LeaseWebhookDelivery(ctx, pgdb.LeaseWebhookDeliveryParams{
+			NextAttemptAt: leaseUntil,
+			ID:            dbDelivery.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lease webhook delivery: %w", err)
+		}
+
+		delivery := r.dbDeliveryToDomain(dbDelivery)
+		delivery.Attempts++
+		delivery.NextAttemptAt = leaseUntil
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook delivery claim: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *postgresHookRepo) MarkDelivered(ctx context.Context, id uint64, responseStatus int, deliveredAt time.Time) error {
+	err := r.queries.MarkWebhookDeliveryDelivered(ctx, pgdb.MarkWebhookDeliveryDeliveredParams{
+		ResponseStatus: sql.NullInt32{Int32: int32(responseStatus), Valid: true},
+		DeliveredAt:    sql.NullTime{Time: deliveredAt, Valid: true},
+		ID:             int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresHookRepo) Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, responseStatus *int, lastError string) error {
+	err := r.queries.RescheduleWebhookDelivery(ctx, pgdb.RescheduleWebhookDeliveryParams{
+		NextAttemptAt:  nextAttemptAt,
+		ResponseStatus: nullInt32(responseStatus),
+		LastError:      sql.NullString{String: lastError, Valid: true},
+		ID:             int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresHookRepo) MarkFailed(ctx context.Context, id uint64, responseStatus *int, lastError string) error {
+	err := r.queries.MarkWebhookDeliveryFailed(ctx, pgdb.MarkWebhookDeliveryFailedParams{
+		ResponseStatus: nullInt32(responseStatus),
+		LastError:      sql.NullString{String: lastError, Valid: true},
+		ID:             int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresHookRepo) GetDelivery(ctx context.Context, userID string, id uint64) (*hook_domain.Delivery, error) {
+	dbDelivery, err := r.queries.GetWebhookDelivery(ctx, pgdb.GetWebhookDeliveryParams{
+		ID:     int64(id),
+		UserID: userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return r.dbDeliveryToDomain(dbDelivery), nil
+}
+
+func (r *postgresHookRepo) ListDeliveries(ctx context.Context, userID string, beforeID uint64, limit int) ([]hook_domain.Delivery, error) {
+	dbDeliveries, err := r.queries.ListWebhookDeliveriesByUserID(ctx, pgdb.ListWebhookDeliveriesByUserIDParams{
+		UserID:   userID,
+		BeforeID: int64(beforeID),
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]hook_domain.Delivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, *r.dbDeliveryToDomain(dbDelivery))
+	}
+
+	return deliveries, nil
+}
+
+func (r *postgresHookRepo) DeleteFinishedDeliveries(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteFinishedWebhookDeliveries(ctx, pgdb.DeleteFinishedWebhookDeliveriesParams{
+		UserID:    userID,
+		CreatedAt: before,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *postgresHookRepo) ClearDeliveredPayloads(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearDeliveredWebhookPayloads(ctx, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear delivered webhook payloads: %w", err)
+	}
+
+	return n, nil
+}
+
+// CreatePostgresDelivery is CreateDelivery for Postgres queries.
+func CreatePostgresDelivery(ctx context.Context, q *pgdb.Queries, delivery *hook_domain.Delivery) error {
+	var redeliveryOf sql.NullInt64
+	if delivery.RedeliveryOf != nil {
+		redeliveryOf = sql.NullInt64{Int64: int64(*delivery.RedeliveryOf), Valid: true}
+	}
+
+	id, err := q.CreateWebhookDelivery(ctx, pgdb.CreateWebhookDeliveryParams{
+		EndpointID:   int64(delivery.EndpointID),
+		UserID:       delivery.UserID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Payload:      delivery.Payload,
+		RedeliveryOf: redeliveryOf,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	delivery.ID = uint64(id)
+	delivery.Status = hook_domain.StatusPending
+	return nil
+}
+
+func (r *postgresHookRepo) dbEndpointToDomain(dbEndpoint pgdb.WebhookEndpoint) *hook_domain.Endpoint {
+	return &hook_domain.Endpoint{
+		ID:        uint64(dbEndpoint.ID),
+		UserID:    dbEndpoint.UserID,
+		URL:       dbEndpoint.Url,
+		Secret:    dbEndpoint.Secret,
+		CreatedBy: dbEndpoint.CreatedBy,
+		CreatedAt: dbEndpoint.CreatedAt,
+		UpdatedAt: dbEndpoint.UpdatedAt,
+	}
+}
+
+func (r *postgresHookRepo) dbDeliveryToDomain(dbDelivery pgdb.WebhookDelivery) *hook_domain.Delivery {
+	delivery := &hook_domain.Delivery{
+		ID:            uint64(dbDelivery.ID),
+		EndpointID:    uint64(dbDelivery.EndpointID),
+		UserID:        dbDelivery.UserID,
+		EventID:       dbDelivery.EventID,
+		EventType:     dbDelivery.EventType,
+		Payload:       dbDelivery.Payload,
+		Status:        dbDelivery.Status,
+		Attempts:      int(dbDelivery.Attempts),
+		NextAttemptAt: dbDelivery.NextAttemptAt,
+		CreatedAt:     dbDelivery.CreatedAt,
+		UpdatedAt:     dbDelivery.UpdatedAt,
+	}
+
+	if dbDelivery.ResponseStatus.Valid {
+		status := int(dbDelivery.ResponseStatus.Int32)
+		delivery.ResponseStatus = &status
+	}
+	if dbDelivery.LastError.Valid {
+		delivery.LastError = &dbDelivery.LastError.String
+	}
+	if dbDelivery.DeliveredAt.Valid {
+		delivery.DeliveredAt = &dbDelivery.DeliveredAt.Time
+	}
+	if dbDelivery.RedeliveryOf.Valid {
+		redeliveryOf := uint64(dbDelivery.RedeliveryOf.Int64)
+		delivery.RedeliveryOf = &redeliveryOf
+	}
+
+	return delivery
+}
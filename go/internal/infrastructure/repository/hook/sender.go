@@ -0,0 +1,176 @@
+package hook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	hook_domain "github.com/huavcjj/flux/internal/domain/hook"
+	"github.com/huavcjj/flux/internal/infrastructure/httpclient"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+// Headers of webhook requests. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the endpoint secret, prefixed "sha256=".
+const (
+	HeaderEvent     = "X-Flux-Event"
+	HeaderEventID   = "X-Flux-Event-Id"
+	HeaderDelivery  = "X-Flux-Delivery"
+	HeaderTimestamp = "X-Flux-Timestamp"
+	HeaderSignature = "X-Flux-Signature"
+)
+
+const (
+	userAgent      = "flux-webhook/1"
+	maxURLLength   = 2048
+	requestTimeout = 10 * time.Second
+)
+
+var errPrivateAddress = errors.New("endpoint resolves to a private address")
+
+type sender struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+var _ hook_domain.Sender = (*sender)(nil)
+
+// NewSender returns a sender for HTTPS endpoints. Unless allowPrivate is set,
+// endpoints resolving to loopback, private, link-local or carrier-grade NAT
+// addresses are refused, so users cannot reach internal services through
+// flux, and plain HTTP is refused.
+func NewSender(allowPrivate bool) hook_domain.Sender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublic(addrPort.Addr()) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	// Endpoint URLs may carry credentials. A proxy would bypass the address
+	// check.
+	client := httpclient.New(requestTimeout)
+	client.Transport = &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	// A redirect would be followed without the checks of ValidateURL;
+	// receivers must answer directly.
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &sender{
+		client:       client,
+		allowPrivate: allowPrivate,
+	}
+}
+
+func (s *sender) ValidateURL(rawURL string) error {
+	if len(rawURL) > maxURLLength {
+		return hook_domain.ErrInvalidURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || u.User != nil || u.Fragment != "" {
+		return hook_domain.ErrInvalidURL
+	}
+	if s.allowPrivate {
+		// Local receivers rarely have certificates.
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return hook_domain.ErrInvalidURL
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return hook_domain.ErrInvalidURL
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !isPublic(addr) {
+		return hook_domain.ErrInvalidURL
+	}
+	if host := strings.ToLower(u.Hostname()); host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return hook_domain.ErrInvalidURL
+	}
+	return nil
+}
+
+func (s *sender) Send(ctx context.Context, endpoint *hook_domain.Endpoint, delivery *hook_domain.Delivery) (int, error) {
+	ctx, span := tracing.Start(ctx, "hook.Send", tracing.UserID(delivery.UserID))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, tracing.Error(span, fmt.Errorf("failed to create webhook request: %w", httpclient.Redact(err)))
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, tracing.Error(span, fmt.Errorf("failed to post webhook: %w", httpclient.Redact(err)))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return resp.StatusCode, tracing.Error(span, fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(b))))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value of body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// nonPublicPrefixes are the special-purpose ranges netip does not classify,
+// most notably the carrier-grade NAT space some clouds use internally.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package hook
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// openssl dgst -sha256 -hmac whsec_test of the timestamp, a dot and the
+	// body.
+	const want = "sha256=35e13ceac6595d90368de5724a4762c8c713cf8ed893d2b2d8301af331ae6ce0"
+
+	got := Sign("whsec_test", "1700000000", []byte(`{"event":"email.received"}`))
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"0.0.0.0", false},
+		{"198.18.0.1", false},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:100.64.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package hook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	hook_domain "github.com/huavcjj/flux/internal/domain/hook"
+	"github.com/huavcjj/flux/internal/infrastructure/sqlitedb"
+)
+
+// sqliteHookRepo relies on SQLite serializing writers instead of row locks,
+// so the database must be opened with a single connection.
+type sqliteHookRepo struct {
+	db      *sql.DB
+	queries *sqlitedb.Queries
+}
+
+var _ hook_domain.HookRepo = (*sqliteHookRepo)(nil)
+
+func NewSQLiteHookRepo(dbConn *sql.DB) hook_domain.HookRepo {
+	return &sqliteHookRepo{
+		db:      dbConn,
+		queries: sqlitedb.New(dbConn),
+	}
+}
+
+func (r *sqliteHookRepo) GetEndpointByUserID(ctx context.Context, userID string) (*hook_domain.Endpoint, error) {
+	dbEndpoint, err := r.queries.GetWebhookEndpointByUserID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return r.dbEndpointToDomain(dbEndpoint), nil
+}
+
+func (r *sqliteHookRepo) GetEndpoint(ctx context.Context, id uint64) (*hook_domain.Endpoint, error) {
+	dbEndpoint, err := r.queries.GetWebhookEndpoint(ctx, int64(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return r.dbEndpointToDomain(dbEndpoint), nil
+}
+
+func (r *sqliteHookRepo) SaveEndpoint(ctx context.Context, endpoint *hook_domain.Endpoint) error {
+	err := r.queries.UpsertWebhookEndpoint(ctx, sqlitedb.UpsertWebhookEndpointParams{
+		UserID:    endpoint.UserID,
+		Url:       endpoint.URL,
+		Secret:    endpoint.Secret,
+		CreatedBy: endpoint.CreatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save webhook endpoint: %w", err)
+	}
+
+	saved, err := r.GetEndpointByUserID(ctx, endpoint.UserID)
+	if err != nil {
+		return err
+	}
+	*endpoint = *saved
+	return nil
+}
+
+func (r *sqliteHookRepo) DeleteEndpoint(ctx context.Context, userID string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	// The endpoint is the user's only one, so these are its deliveries.
+	if err := qtx.DeleteWebhookDeliveriesByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	n, err := qtx.DeleteWebhookEndpointByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit webhook endpoint deletion: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (r *sqliteHookRepo) CreateDelivery(ctx context.Context, delivery *hook_domain.Delivery) error {
+	return CreateSQLiteDelivery(ctx, r.queries, delivery)
+}
+
+func (r *sqliteHookRepo) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]hook_domain.Delivery, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbDeliveries, err := qtx.GetDueWebhookDeliveries(ctx, sqlitedb.GetDueWebhookDeliveriesParams{
+		NextAttemptAt: now.UTC(),
+		Limit:         int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]hook_domain.Delivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		if err := qtx.LeaseWebhookDelivery(ctx, sqlitedb.LeaseWebhookDeliveryParams{
+			NextAttemptAt: leaseUntil.UTC(),
+			ID:            dbDelivery.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lease webhook delivery: %w", err)
+		}
+
+		delivery := r.dbDeliveryToDomain(dbDelivery)
+		delivery.Attempts++
+		delivery.NextAttemptAt = leaseUntil
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook delivery claim: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *sqliteHookRepo) MarkDelivered(ctx context.Context, id uint64, responseStatus int, deliveredAt time.Time) error {
+	err := r.queries.MarkWebhookDeliveryDelivered(ctx, sqlitedb.MarkWebhookDeliveryDeliveredParams{
+		ResponseStatus: sql.NullInt64{Int64: int64(responseStatus), Valid: true},
+		DeliveredAt:    sql.NullTime{Time: deliveredAt.UTC(), Valid: true},
+		ID:             int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteHookRepo) Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, responseStatus *int, lastError string) error {
+	err := r.queries.RescheduleWebhookDelivery(ctx, sqlitedb.RescheduleWebhookDeliveryParams{
+		NextAttemptAt:  nextAttemptAt.UTC(),
+		ResponseStatus: nullInt64(responseStatus),
+		LastError:      sql.NullString{String: lastError, Valid: true},
+		ID:             int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteHookRepo) MarkFailed(ctx context.Context, id uint64, responseStatus *int, lastError string) error {
+	err := r.queries.MarkWebhookDeliveryFailed(ctx, sqlitedb.MarkWebhookDeliveryFailedParams{
+		ResponseStatus: nullInt64(responseStatus),
+		LastError:      sql.NullString{String: lastError, Valid: true},
+		ID:             int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteHookRepo) GetDelivery(ctx context.Context, userID string, id uint64) (*hook_domain.Delivery, error) {
+	dbDelivery, err := r.queries.GetWebhookDelivery(ctx, sqlitedb.GetWebhookDeliveryParams{
+		ID:     int64(id),
+		UserID: userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return r.dbDeliveryToDomain(dbDelivery), nil
+}
+
+func (r *sqliteHookRepo) ListDeliveries(ctx context.Context, userID string, beforeID uint64, limit int) ([]hook_domain.Delivery, error) {
+	dbDeliveries, err := r.queries.ListWebhookDeliveriesByUserID(ctx, sqlitedb.ListWebhookDeliveriesByUserIDParams{
+		UserID:   userID,
+		BeforeID: int64(beforeID),
+		Limit:    int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]hook_domain.Delivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, *r.dbDeliveryToDomain(dbDelivery))
+	}
+
+	return deliveries, nil
+}
+
+func (r *sqliteHookRepo) DeleteFinishedDeliveries(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteFinishedWebhookDeliveries(ctx, sqlitedb.DeleteFinishedWebhookDeliveriesParams{
+		UserID:    userID,
+		CreatedAt: before.UTC(),
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *sqliteHookRepo) ClearDeliveredPayloads(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearDeliveredWebhookPayloads(ctx, int64(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear delivered webhook payloads: %w", err)
+	}
+
+	return n, nil
+}
+
+// CreateSQLiteDelivery is CreateDelivery for SQLite queries.
+func CreateSQLiteDelivery(ctx context.Context, q *sqlitedb.Queries, delivery *hook_domain.Delivery) error {
+	var redeliveryOf sql.NullInt64
+	if delivery.RedeliveryOf != nil {
+		redeliveryOf = sql.NullInt64{Int64: int64(*delivery.RedeliveryOf), Valid: true}
+	}
+
+	result, err := q.CreateWebhookDelivery(ctx, sqlitedb.CreateWebhookDeliveryParams{
+		EndpointID:   int64(delivery.EndpointID),
+		UserID:       delivery.UserID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Payload:      delivery.Payload,
+		RedeliveryOf: redeliveryOf,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery id: %w", err)
+	}
+	delivery.ID = uint64(id)
+	delivery.Status = hook_domain.StatusPending
+	return nil
+}
+
+func (r *sqliteHookRepo) dbEndpointToDomain(dbEndpoint sqlitedb.WebhookEndpoint) *hook_domain.Endpoint {
+	return &hook_domain.Endpoint{
+		ID:        uint64(dbEndpoint.ID),
+		UserID:    dbEndpoint.UserID,
+		URL:       dbEndpoint.Url,
+		Secret:    dbEndpoint.Secret,
+		CreatedBy: dbEndpoint.CreatedBy,
+		CreatedAt: dbEndpoint.CreatedAt,
+		UpdatedAt: dbEndpoint.UpdatedAt,
+	}
+}
+
+func (r *sqliteHookRepo) dbDeliveryToDomain(dbDelivery sqlitedb.WebhookDelivery) *hook_domain.Delivery {
+	delivery := &hook_domain.Delivery{
+		ID:            uint64(dbDelivery.ID),
+		EndpointID:    uint64(dbDelivery.EndpointID),
+		UserID:        dbDelivery.UserID,
+		EventID:       dbDelivery.EventID,
+		EventType:     dbDelivery.EventType,
+		Payload:       dbDelivery.Payload,
+		Status:        dbDelivery.Status,
+		Attempts:      int(dbDelivery.Attempts),
+		NextAttemptAt: dbDelivery.NextAttemptAt,
+		CreatedAt:     dbDelivery.CreatedAt,
+		UpdatedAt:     dbDelivery.UpdatedAt,
+	}
+
+	if dbDelivery.ResponseStatus.Valid {
+		status := int(dbDelivery.ResponseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	if dbDelivery.LastError.Valid {
+		delivery.LastError = &dbDelivery.LastError.String
+	}
+	if dbDelivery.DeliveredAt.Valid {
+		delivery.DeliveredAt = &dbDelivery.DeliveredAt.Time
+	}
+	if dbDelivery.RedeliveryOf.Valid {
+		redeliveryOf := uint64(dbDelivery.RedeliveryOf.Int64)
+		delivery.RedeliveryOf = &redeliveryOf
+	}
+
+	return delivery
+}
+
+func nullInt64(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
@@ -0,0 +1,243 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	job_domain "github.com/huavcjj/flux/internal/domain/job"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+)
+
+type postgresJobRepo struct {
+	db      *sql.DB
+	queries *pgdb.Queries
+}
+
+var _ job_domain.JobRepo = (*postgresJobRepo)(nil)
+
+func NewPostgresJobRepo(dbConn *sql.DB) job_domain.JobRepo {
+	return &postgresJobRepo{
+		db:      dbConn,
+		queries: pgdb.New(dbConn),
+	}
+}
+
+func (r *postgresJobRepo) CreateJob(ctx context.Context, job *job_domain.Job) error {
+	var dedupKey sql.NullString
+	if job.DedupKey != nil {
+		dedupKey = sql.NullString{String: *job.DedupKey, Valid: true}
+	}
+
+	var traceParent sql.NullString
+	if job.TraceParent != nil {
+		traceParent = sql.NullString{String: *job.TraceParent, Valid: true}
+	}
+
+	runAt := job.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	_, err := r.queries.CreateJob(ctx, pgdb.CreateJobParams{
+		Type:        job.Type,
+		DedupKey:    dedupKey,
+		Payload:     job.Payload,
+		MaxAttempts: int32(job.MaxAttempts),
+		RunAt:       runAt,
+		TraceParent: traceParent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresJobRepo) ClaimJob(ctx context.Context, now time.Time) (*job_domain.Job, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbJob, err := qtx.GetNextPendingJobForUpdate(ctx, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get next pending job: %w", err)
+	}
+
+	if err := qtx.MarkJobRunning(ctx, pgdb.MarkJobRunningParams{
+		LockedAt: sql.NullTime{Time: now, Valid: true},
+		ID:       dbJob.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to mark job running: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit job claim: %w", err)
+	}
+
+	job := r.dbJobToDomain(dbJob)
+	job.Status = job_domain.StatusRunning
+	job.Attempts++
+	job.LockedAt = &now
+
+	return job, nil
+}
+
+func (r *postgresJobRepo) CompleteJob(ctx context.Context, id uint64) error {
+	if err := r.queries.MarkJobDone(ctx, int64(id)); err != nil {
+		return fmt.Errorf("failed to mark job done: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresJobRepo) RetryJob(ctx context.Context, id uint64, runAt time.Time, lastError string) error {
+	err := r.queries.RescheduleJob(ctx, pgdb.RescheduleJobParams{
+		RunAt:     runAt,
+		LastError: sql.NullString{String: lastError, Valid: true},
+		ID:        int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresJobRepo) DeadLetterJob(ctx context.Context, id uint64, lastError string) error {
+	err := r.queries.MarkJobDead(ctx, pgdb.MarkJobDeadParams{
+		LastError: sql.NullString{String: lastError, Valid: true},
+		ID:        int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter job: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresJobRepo) RequeueStaleJobs(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	result, err := r.queries.RequeueStaleJobs(ctx, sql.NullTime{Time: lockedBefore, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected, nil
+}
+
+func (r *postgresJobRepo) DeadLetterStaleJobs(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	result, err := r.queries.DeadLetterStaleJobs(ctx, sql.NullTime{Time: lockedBefore, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to dead-letter stale jobs: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected, nil
+}
+
+func (r *postgresJobRepo) DeleteDoneJobs(ctx context.Context, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteDoneJobsBefore(ctx, pgdb.DeleteDoneJobsBeforeParams{
+		UpdatedAt: before,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete done jobs: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *postgresJobRepo) CountJobsByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.queries.CountJobsByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs by status: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (r *postgresJobRepo) GetOldestPendingRunAt(ctx context.Context) (*time.Time, error) {
+	runAt, err := r.queries.GetOldestPendingJobRunAt(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get oldest pending job: %w", err)
+	}
+
+	return &runAt, nil
+}
+
+func (r *postgresJobRepo) ListJobsByStatus(ctx context.Context, status string, limit int) ([]job_domain.Job, error) {
+	dbJobs, err := r.queries.ListJobsByStatus(ctx, pgdb.ListJobsByStatusParams{
+		Status: status,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	jobs := make([]job_domain.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		jobs = append(jobs, *r.dbJobToDomain(dbJob))
+	}
+
+	return jobs, nil
+}
+
+func (r *postgresJobRepo) ReplayDeadJob(ctx context.Context, id uint64) (bool, error) {
+	n, err := r.queries.ReplayDeadJob(ctx, int64(id))
+	if err != nil {
+		return false, fmt.Errorf("failed to replay job: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (r *postgresJobRepo) dbJobToDomain(dbJob pgdb.Job) *job_domain.Job {
+	job := &job_domain.Job{
+		ID:          uint64(dbJob.ID),
+		Type:        dbJob.Type,
+		Payload:     dbJob.Payload,
+		Status:      dbJob.Status,
+		Attempts:    int(dbJob.Attempts),
+		MaxAttempts: int(dbJob.MaxAttempts),
+		RunAt:       dbJob.RunAt,
+		CreatedAt:   dbJob.CreatedAt,
+		UpdatedAt:   dbJob.UpdatedAt,
+	}
+
+	if dbJob.DedupKey.Valid {
+		job.DedupKey = &dbJob.DedupKey.String
+	}
+	if dbJob.LockedAt.Valid {
+		job.LockedAt = &dbJob.LockedAt.Time
+	}
+	if dbJob.LastError.Valid {
+		job.LastError = &dbJob.LastError.String
+	}
+	if dbJob.TraceParent.Valid {
+		job.TraceParent = &dbJob.TraceParent.String
+	}
+
+	return job
+}
@@ -3,13 +3,22 @@ package line
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	line_repo "github.com/huavcjj/flux/internal/domain/line"
+	"github.com/huavcjj/flux/internal/metrics"
+	"github.com/huavcjj/flux/internal/tracing"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// maxMessages is the number of messages the Messaging API accepts per request.
+const maxMessages = 5
+
 type lineRepo struct {
-	bot *messaging_api.MessagingApiAPI
+	bot  *messaging_api.MessagingApiAPI
+	blob *messaging_api.MessagingApiBlobAPI
 }
 
 var _ line_repo.LineRepo = (*lineRepo)(nil)
@@ -24,17 +33,27 @@ func NewLineRepo(channelToken string) (line_repo.LineRepo, error) {
 		return nil, fmt.Errorf("failed to create messaging API: %w", err)
 	}
 
+	blob, err := messaging_api.NewMessagingApiBlobAPI(channelToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create messaging blob API: %w", err)
+	}
+
 	return &lineRepo{
-		bot: bot,
+		bot:  bot,
+		blob: blob,
 	}, nil
 }
 
 func (r *lineRepo) SendTextMessage(ctx context.Context, userID, message string) error {
+	_, span := tracing.Start(ctx, "line.SendTextMessage", tracing.UserID(userID))
+	defer span.End()
+
 	if userID == "" {
 		return fmt.Errorf("user ID is empty")
 	}
 
-	_, err := r.bot.PushMessage(
+	_, err := r.push(
+		span,
 		&messaging_api.PushMessageRequest{
 			To: userID,
 			Messages: []messaging_api.MessageInterface{
@@ -56,12 +75,117 @@ func (r *lineRepo) PushMessage(ctx context.Context, userID, message string) erro
 	return r.SendTextMessage(ctx, userID, message)
 }
 
+func (r *lineRepo) PushMessages(ctx context.Context, userID string, messages ...line_repo.Message) error {
+	_, span := tracing.Start(ctx, "line.PushMessages", tracing.UserID(userID), attribute.Int("line.message_count", len(messages)))
+	defer span.End()
+
+	if userID == "" {
+		return fmt.Errorf("user ID is empty")
+	}
+
+	lineMessages, err := toMessagingMessages(messages)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.push(
+		span,
+		&messaging_api.PushMessageRequest{
+			To:       userID,
+			Messages: lineMessages,
+		},
+		"",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to push messages: %w", err)
+	}
+
+	return nil
+}
+
+func (r *lineRepo) PushMessagesWithRetryKey(ctx context.Context, userID, retryKey string, messages ...line_repo.Message) error {
+	_, span := tracing.Start(ctx, "line.PushMessagesWithRetryKey", tracing.UserID(userID), attribute.Int("line.message_count", len(messages)))
+	defer span.End()
+
+	if userID == "" {
+		return fmt.Errorf("user ID is empty")
+	}
+
+	lineMessages, err := toMessagingMessages(messages)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.push(
+		span,
+		&messaging_api.PushMessageRequest{
+			To:       userID,
+			Messages: lineMessages,
+		},
+		retryKey,
+	)
+	if err != nil {
+		// 409 means a request with this retry key was already accepted.
+		if res != nil && res.StatusCode == http.StatusConflict {
+			return nil
+		}
+		return fmt.Errorf("failed to push messages: %w", err)
+	}
+
+	return nil
+}
+
+// push sends a push message and records the response status code.
+func (r *lineRepo) push(span trace.Span, req *messaging_api.PushMessageRequest, retryKey string) (*http.Response, error) {
+	res, _, err := r.bot.PushMessageWithHttpInfo(req, retryKey)
+
+	statusCode := 0
+	if res != nil {
+		statusCode = res.StatusCode
+	}
+	metrics.ObserveLinePush(statusCode)
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	tracing.RecordError(span, err)
+
+	return res, err
+}
+
+func (r *lineRepo) ReplyMessage(ctx context.Context, replyToken string, messages ...line_repo.Message) error {
+	_, span := tracing.Start(ctx, "line.ReplyMessage", attribute.Int("line.message_count", len(messages)))
+	defer span.End()
+
+	if replyToken == "" {
+		return fmt.Errorf("reply token is empty")
+	}
+
+	lineMessages, err := toMessagingMessages(messages)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.bot.ReplyMessage(
+		&messaging_api.ReplyMessageRequest{
+			ReplyToken: replyToken,
+			Messages:   lineMessages,
+		},
+	)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to reply message: %w", err))
+	}
+
+	return nil
+}
+
 func (r *lineRepo) SendButtonMessage(ctx context.Context, userID, text, buttonText, buttonURL string) error {
+	_, span := tracing.Start(ctx, "line.SendButtonMessage", tracing.UserID(userID))
+	defer span.End()
+
 	if userID == "" {
 		return fmt.Errorf("user ID is empty")
 	}
 
-	_, err := r.bot.PushMessage(
+	_, err := r.push(
+		span,
 		&messaging_api.PushMessageRequest{
 			To: userID,
 			Messages: []messaging_api.MessageInterface{
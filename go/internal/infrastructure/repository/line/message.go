@@ -0,0 +1,66 @@
+package line
+
+import (
+	"fmt"
+
+	line_repo "github.com/huavcjj/flux/internal/domain/line"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func toMessagingMessages(messages []line_repo.Message) ([]messaging_api.MessageInterface, error) {
+	if len(messages) == 0 || len(messages) > maxMessages {
+		return nil, fmt.Errorf("request must contain 1 to %d messages, got %d", maxMessages, len(messages))
+	}
+
+	lineMessages := make([]messaging_api.MessageInterface, 0, len(messages))
+	for _, message := range messages {
+		textMessage := messaging_api.TextMessage{
+			Text: message.Text,
+		}
+
+		if len(message.QuickReply) > 0 {
+			quickReply, err := toQuickReply(message.QuickReply)
+			if err != nil {
+				return nil, err
+			}
+			textMessage.QuickReply = quickReply
+		}
+
+		lineMessages = append(lineMessages, textMessage)
+	}
+
+	return lineMessages, nil
+}
+
+func toQuickReply(actions []line_repo.Action) (*messaging_api.QuickReply, error) {
+	if len(actions) > line_repo.MaxQuickReplyItems {
+		actions = actions[:line_repo.MaxQuickReplyItems]
+	}
+
+	items := make([]messaging_api.QuickReplyItem, 0, len(actions))
+	for _, action := range actions {
+		lineAction, err := toMessagingAction(action)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, messaging_api.QuickReplyItem{
+			Type:   "action",
+			Action: lineAction,
+		})
+	}
+
+	return &messaging_api.QuickReply{Items: items}, nil
+}
+
+func toMessagingAction(action line_repo.Action) (messaging_api.ActionInterface, error) {
+	switch action.Type {
+	case line_repo.ActionTypeMessage:
+		return &messaging_api.MessageAction{Label: action.Label, Text: action.Text}, nil
+	case line_repo.ActionTypePostback:
+		return &messaging_api.PostbackAction{Label: action.Label, Data: action.Data, DisplayText: action.Text}, nil
+	case line_repo.ActionTypeURI:
+		return &messaging_api.UriAction{Label: action.Label, Uri: action.URI}, nil
+	default:
+		return nil, fmt.Errorf("unsupported action type: %q", action.Type)
+	}
+}
@@ -0,0 +1,29 @@
+package line
+
+import (
+	"context"
+
+	line_repo "github.com/huavcjj/flux/internal/domain/line"
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+)
+
+type lineNotifier struct {
+	repo line_repo.LineRepo
+}
+
+// NewNotifier delivers notifications as LINE text messages. Recipients are
+// LINE user IDs.
+func NewNotifier(repo line_repo.LineRepo) notifier_domain.Notifier {
+	return &lineNotifier{repo: repo}
+}
+
+func (n *lineNotifier) Send(ctx context.Context, recipient string, notification notifier_domain.Notification) error {
+	return n.repo.PushMessagesWithRetryKey(ctx, recipient, notification.RetryKey, line_repo.NewTextMessage(notification.Text))
+}
+
+func (n *lineNotifier) ValidateRecipient(recipient string) error {
+	if recipient == "" {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	return nil
+}
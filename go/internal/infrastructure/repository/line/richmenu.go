@@ -0,0 +1,124 @@
+package line
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	line_repo "github.com/huavcjj/flux/internal/domain/line"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func (r *lineRepo) CreateRichMenu(ctx context.Context, menu *line_repo.RichMenu) (string, error) {
+	areas := make([]messaging_api.RichMenuArea, 0, len(menu.Areas))
+	for _, area := range menu.Areas {
+		action, err := toMessagingAction(area.Action)
+		if err != nil {
+			return "", err
+		}
+		areas = append(areas, messaging_api.RichMenuArea{
+			Bounds: &messaging_api.RichMenuBounds{
+				X:      area.X,
+				Y:      area.Y,
+				Width:  area.Width,
+				Height: area.Height,
+			},
+			Action: action,
+		})
+	}
+
+	res, err := r.bot.CreateRichMenu(&messaging_api.RichMenuRequest{
+		Size: &messaging_api.RichMenuSize{
+			Width:  menu.Width,
+			Height: menu.Height,
+		},
+		Selected:    menu.Selected,
+		Name:        menu.Name,
+		ChatBarText: menu.ChatBarText,
+		Areas:       areas,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create rich menu: %w", err)
+	}
+
+	return res.RichMenuId, nil
+}
+
+func (r *lineRepo) UploadRichMenuImage(ctx context.Context, richMenuID, contentType string, image io.Reader) error {
+	if _, err := r.blob.SetRichMenuImage(richMenuID, contentType, image); err != nil {
+		return fmt.Errorf("failed to upload rich menu image: %w", err)
+	}
+	return nil
+}
+
+func (r *lineRepo) DeleteRichMenu(ctx context.Context, richMenuID string) error {
+	if _, err := r.bot.DeleteRichMenu(richMenuID); err != nil {
+		return fmt.Errorf("failed to delete rich menu: %w", err)
+	}
+	return nil
+}
+
+func (r *lineRepo) SetDefaultRichMenu(ctx context.Context, richMenuID string) error {
+	if _, err := r.bot.SetDefaultRichMenu(richMenuID); err != nil {
+		return fmt.Errorf("failed to set default rich menu: %w", err)
+	}
+	return nil
+}
+
+func (r *lineRepo) GetRichMenuIDByAlias(ctx context.Context, aliasID string) (string, error) {
+	res, alias, err := r.bot.GetRichMenuAliasWithHttpInfo(aliasID)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get rich menu alias: %w", err)
+	}
+
+	return alias.RichMenuId, nil
+}
+
+func (r *lineRepo) SetRichMenuAlias(ctx context.Context, aliasID, richMenuID string) error {
+	current, err := r.GetRichMenuIDByAlias(ctx, aliasID)
+	if err != nil {
+		return err
+	}
+
+	if current == "" {
+		_, err = r.bot.CreateRichMenuAlias(&messaging_api.CreateRichMenuAliasRequest{
+			RichMenuAliasId: aliasID,
+			RichMenuId:      richMenuID,
+		})
+	} else {
+		_, err = r.bot.UpdateRichMenuAlias(aliasID, &messaging_api.UpdateRichMenuAliasRequest{
+			RichMenuId: richMenuID,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set rich menu alias: %w", err)
+	}
+
+	return nil
+}
+
+func (r *lineRepo) LinkRichMenu(ctx context.Context, userID, richMenuID string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID is empty")
+	}
+
+	if _, err := r.bot.LinkRichMenuIdToUser(userID, richMenuID); err != nil {
+		return fmt.Errorf("failed to link rich menu: %w", err)
+	}
+	return nil
+}
+
+func (r *lineRepo) UnlinkRichMenu(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID is empty")
+	}
+
+	if _, err := r.bot.UnlinkRichMenuIdFromUser(userID); err != nil {
+		return fmt.Errorf("failed to unlink rich menu: %w", err)
+	}
+	return nil
+}
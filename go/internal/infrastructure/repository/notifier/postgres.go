@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+)
+
+type postgresChannelRepo struct {
+	queries *pgdb.Queries
+}
+
+var _ notifier_domain.ChannelRepo = (*postgresChannelRepo)(nil)
+
+func NewPostgresChannelRepo(dbConn *sql.DB) notifier_domain.ChannelRepo {
+	return &postgresChannelRepo{
+		queries: pgdb.New(dbConn),
+	}
+}
+
+func (r *postgresChannelRepo) ListChannels(ctx context.Context, userID string) ([]notifier_domain.Channel, error) {
+	dbChannels, err := r.queries.ListNotificationChannelsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification channels: %w", err)
+	}
+
+	channels := make([]notifier_domain.Channel, 0, len(dbChannels))
+	for _, dbChannel := range dbChannels {
+		channels = append(channels, notifier_domain.Channel{
+			UserID:    dbChannel.UserID,
+			Channel:   dbChannel.Channel,
+			Target:    dbChannel.Target,
+			CreatedAt: dbChannel.CreatedAt,
+			UpdatedAt: dbChannel.UpdatedAt,
+		})
+	}
+
+	return channels, nil
+}
+
+func (r *postgresChannelRepo) GetChannelByTarget(ctx context.Context, channel, target string) (*notifier_domain.Channel, error) {
+	dbChannel, err := r.queries.GetNotificationChannelByTarget(ctx, pgdb.GetNotificationChannelByTargetParams{
+		Channel: channel,
+		Target:  target,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification channel: %w", err)
+	}
+	return &notifier_domain.Channel{
+		UserID:    dbChannel.UserID,
+		Channel:   dbChannel.Channel,
+		Target:    dbChannel.Target,
+		CreatedAt: dbChannel.CreatedAt,
+		UpdatedAt: dbChannel.UpdatedAt,
+	}, nil
+}
+
+func (r *postgresChannelRepo) SaveChannel(ctx context.Context, channel *notifier_domain.Channel) error {
+	err := r.queries.UpsertNotificationChannel(ctx, pgdb.UpsertNotificationChannelParams{
+		UserID:  channel.UserID,
+		Channel: channel.Channel,
+		Target:  channel.Target,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save notification channel: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresChannelRepo) DeleteChannel(ctx context.Context, userID, channel string) (bool, error) {
+	rows, err := r.queries.DeleteNotificationChannel(ctx, pgdb.DeleteNotificationChannelParams{
+		UserID:  userID,
+		Channel: channel,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification channel: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *postgresChannelRepo) CreateLinkCode(ctx context.Context, code *notifier_domain.LinkCode) error {
+	if err := r.queries.DeleteExpiredChannelLinkCodes(ctx, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired link codes: %w", err)
+	}
+
+	err := r.queries.CreateChannelLinkCode(ctx, pgdb.CreateChannelLinkCodeParams{
+		Code:      code.Code,
+		Channel:   code.Channel,
+		UserID:    code.UserID,
+		ExpiresAt: code.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create link code: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresChannelRepo) TakeLinkCode(ctx context.Context, channel, code string, now time.Time) (string, error) {
+	dbCode, err := r.queries.GetChannelLinkCode(ctx, pgdb.GetChannelLinkCodeParams{
+		Code:    code,
+		Channel: channel,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get link code: %w", err)
+	}
+
+	// Only the request that deletes the code may use it.
+	rows, err := r.queries.DeleteChannelLinkCode(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete link code: %w", err)
+	}
+	if rows == 0 || !now.Before(dbCode.ExpiresAt) {
+		return "", nil
+	}
+	return dbCode.UserID, nil
+}
@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	flux_db "github.com/huavcjj/flux/db"
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+	"github.com/huavcjj/flux/internal/migrate"
+	_ "modernc.org/sqlite"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)&_time_format=sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrate.New(db, "sqlite", flux_db.Migrations, flux_db.MigrationsDir("sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`INSERT INTO users (id, line_user_id) VALUES ('user-1', 'U1')`); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSQLiteLinkCodes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSQLiteChannelRepo(openSQLite(t))
+	now := time.Now()
+
+	for _, code := range []notifier_domain.LinkCode{
+		{Code: "VALID", Channel: "telegram", UserID: "user-1", ExpiresAt: now.Add(10 * time.Minute)},
+		{Code: "EXPIRED", Channel: "telegram", UserID: "user-1", ExpiresAt: now.Add(-time.Minute)},
+	} {
+		if err := repo.CreateLinkCode(ctx, &code); err != nil {
+			t.Fatalf("CreateLinkCode(%s): %v", code.Code, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		code    string
+		want    string
+	}{
+		{"other channel", "discord", "VALID", ""},
+		{"valid", "telegram", "VALID", "user-1"},
+		{"used twice", "telegram", "VALID", ""},
+		{"expired", "telegram", "EXPIRED", ""},
+		{"unknown", "telegram", "UNKNOWN", ""},
+	}
+	for _, tt := range tests {
+		got, err := repo.TakeLinkCode(ctx, tt.channel, tt.code, now)
+		if err != nil {
+			t.Fatalf("%s: TakeLinkCode: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: TakeLinkCode = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteCreateLinkCodeDropsExpired(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repo := NewSQLiteChannelRepo(db)
+
+	expired := notifier_domain.LinkCode{Code: "OLD", Channel: "telegram", UserID: "user-1", ExpiresAt: time.Now().Add(-time.Minute)}
+	fresh := notifier_domain.LinkCode{Code: "NEW", Channel: "telegram", UserID: "user-1", ExpiresAt: time.Now().Add(time.Minute)}
+	for _, code := range []*notifier_domain.LinkCode{&expired, &fresh} {
+		if err := repo.CreateLinkCode(ctx, code); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var codes []string
+	rows, err := db.Query(`SELECT code FROM channel_link_codes ORDER BY code`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) != 1 || codes[0] != "NEW" {
+		t.Errorf("stored codes = %v, want [NEW]", codes)
+	}
+}
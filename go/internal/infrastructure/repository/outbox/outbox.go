@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	outbox_domain "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/infrastructure/db"
+)
+
+type outboxRepo struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+var _ outbox_domain.OutboxRepo = (*outboxRepo)(nil)
+
+func NewOutboxRepo(dbConn *sql.DB) outbox_domain.OutboxRepo {
+	return &outboxRepo{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *outboxRepo) CreateEntry(ctx context.Context, entry *outbox_domain.Entry) error {
+	return CreateEntry(ctx, r.queries, entry)
+}
+
+func (r *outboxRepo) ClaimDueEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]outbox_domain.Entry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbEntries, err := qtx.GetDueOutboxEntriesForUpdate(ctx, db.GetDueOutboxEntriesForUpdateParams{
+		NextAttemptAt: now,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due outbox entries: %w", err)
+	}
+
+	entries := make([]outbox_domain.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		if err := qtx.LeaseOutboxEntry(ctx, db.LeaseOutboxEntryParams{
+			NextAttemptAt: leaseUntil,
+			ID:            dbEntry.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lease outbox entry: %w", err)
+		}
+
+		entry := r.dbEntryToDomain(dbEntry)
+		entry.Attempts++
+		entry.NextAttemptAt = leaseUntil
+		entries = append(entries, *entry)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *outboxRepo) MarkSent(ctx context.Context, entry *outbox_domain.Entry, sentAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	if err := qtx.MarkOutboxEntrySent(ctx, db.MarkOutboxEntrySentParams{
+		SentAt: sql.NullTime{Time: sentAt, Valid: true},
+		ID:     entry.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to mark outbox entry sent: %w", err)
+	}
+
+	if entry.EmailID != nil {
+		if err := qtx.MarkEmailAsNotifiedByID(ctx, *entry.EmailID); err != nil {
+			return fmt.Errorf("failed to mark email as notified: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error {
+	err := r.queries.RescheduleOutboxEntry(ctx, db.RescheduleOutboxEntryParams{
+		NextAttemptAt: nextAttemptAt,
+		LastError:     sql.NullString{String: lastError, Valid: true},
+		ID:            id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id uint64, lastError string) error {
+	err := r.queries.MarkOutboxEntryFailed(ctx, db.MarkOutboxEntryFailedParams{
+		LastError: sql.NullString{String: lastError, Valid: true},
+		ID:        id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox entry failed: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepo) CountEntriesByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.queries.CountOutboxEntriesByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox entries by status: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (r *outboxRepo) ListRecentEntries(ctx context.Context, status string, limit int) ([]outbox_domain.Entry, error) {
+	dbEntries, err := r.queries.ListRecentOutboxEntries(ctx, db.ListRecentOutboxEntriesParams{
+		Status: status,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox entries: %w", err)
+	}
+
+	entries := make([]outbox_domain.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		entries = append(entries, *r.dbEntryToDomain(dbEntry))
+	}
+
+	return entries, nil
+}
+
+func (r *outboxRepo) DeleteFinishedEntries(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteFinishedOutboxEntries(ctx, db.DeleteFinishedOutboxEntriesParams{
+		UserID:    userID,
+		CreatedAt: sql.NullTime{Time: before, Valid: true},
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished outbox entries: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *outboxRepo) ClearSentMessages(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearSentOutboxMessages(ctx, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear sent outbox messages: %w", err)
+	}
+
+	return n, nil
+}
+
+// CreateEntry inserts an entry using q, which may be bound to a transaction
+// owned by another repository. Entries with an existing idempotency key are ignored.
+func CreateEntry(ctx context.Context, q *db.Queries, entry *outbox_domain.Entry) error {
+	var emailID sql.NullInt64
+	if entry.EmailID != nil {
+		emailID = sql.NullInt64{Int64: int64(*entry.EmailID), Valid: true}
+	}
+
+	var traceParent sql.NullString
+	if entry.TraceParent != nil {
+		traceParent = sql.NullString{String: *entry.TraceParent, Valid: true}
+	}
+
+	_, err := q.CreateOutboxEntry(ctx, db.CreateOutboxEntryParams{
+		IdempotencyKey: entry.IdempotencyKey,
+		RetryKey:       entry.RetryKey,
+		UserID:         entry.UserID,
+		EmailID:        emailID,
+		Channel:        entry.Channel,
+		Recipient:      entry.Recipient,
+		Message:        entry.Message,
+		TraceParent:    traceParent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) dbEntryToDomain(dbEntry db.NotificationOutbox) *outbox_domain.Entry {
+	entry := &outbox_domain.Entry{
+		ID:             dbEntry.ID,
+		IdempotencyKey: dbEntry.IdempotencyKey,
+		RetryKey:       dbEntry.RetryKey,
+		UserID:         dbEntry.UserID,
+		Channel:        dbEntry.Channel,
+		Recipient:      dbEntry.Recipient,
+		Message:        dbEntry.Message,
+		Status:         dbEntry.Status,
+		Attempts:       int(dbEntry.Attempts),
+		NextAttemptAt:  dbEntry.NextAttemptAt,
+	}
+
+	if dbEntry.EmailID.Valid {
+		emailID := uint64(dbEntry.EmailID.Int64)
+		entry.EmailID = &emailID
+	}
+	if dbEntry.LastError.Valid {
+		entry.LastError = &dbEntry.LastError.String
+	}
+	if dbEntry.TraceParent.Valid {
+		entry.TraceParent = &dbEntry.TraceParent.String
+	}
+	if dbEntry.SentAt.Valid {
+		entry.SentAt = &dbEntry.SentAt.Time
+	}
+	if dbEntry.CreatedAt.Valid {
+		entry.CreatedAt = dbEntry.CreatedAt.Time
+	}
+	if dbEntry.UpdatedAt.Valid {
+		entry.UpdatedAt = dbEntry.UpdatedAt.Time
+	}
+
+	return entry
+}
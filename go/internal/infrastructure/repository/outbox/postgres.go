@@ -0,0 +1,235 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	outbox_domain "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+)
+
+type postgresOutboxRepo struct {
+	db      *sql.DB
+	queries *pgdb.Queries
+}
+
+var _ outbox_domain.OutboxRepo = (*postgresOutboxRepo)(nil)
+
+func NewPostgresOutboxRepo(dbConn *sql.DB) outbox_domain.OutboxRepo {
+	return &postgresOutboxRepo{
+		db:      dbConn,
+		queries: pgdb.New(dbConn),
+	}
+}
+
+func (r *postgresOutboxRepo) CreateEntry(ctx context.Context, entry *outbox_domain.Entry) error {
+	return CreatePostgresEntry(ctx, r.queries, entry)
+}
+
+func (r *postgresOutboxRepo) ClaimDueEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]outbox_domain.Entry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbEntries, err := qtx.GetDueOutboxEntriesForUpdate(ctx, pgdb.GetDueOutboxEntriesForUpdateParams{
+		NextAttemptAt: now,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due outbox entries: %w", err)
+	}
+
+	entries := make([]outbox_domain.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		if err := qtx.LeaseOutboxEntry(ctx, pgdb.LeaseOutboxEntryParams{
+			NextAttemptAt: leaseUntil,
+			ID:            dbEntry.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lease outbox entry: %w", err)
+		}
+
+		entry := r.dbEntryToDomain(dbEntry)
+		entry.Attempts++
+		entry.NextAttemptAt = leaseUntil
+		entries = append(entries, *entry)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *postgresOutboxRepo) MarkSent(ctx context.Context, entry *outbox_domain.Entry, sentAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	if err := qtx.MarkOutboxEntrySent(ctx, pgdb.MarkOutboxEntrySentParams{
+		SentAt: sql.NullTime{Time: sentAt, Valid: true},
+		ID:     int64(entry.ID),
+	}); err != nil {
+		return fmt.Errorf("failed to mark outbox entry sent: %w", err)
+	}
+
+	if entry.EmailID != nil {
+		if err := qtx.MarkEmailAsNotifiedByID(ctx, int64(*entry.EmailID)); err != nil {
+			return fmt.Errorf("failed to mark email as notified: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresOutboxRepo) Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error {
+	err := r.queries.RescheduleOutboxEntry(ctx, pgdb.RescheduleOutboxEntryParams{
+		NextAttemptAt: nextAttemptAt,
+		LastError:     sql.NullString{String: lastError, Valid: true},
+		ID:            int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOutboxRepo) MarkFailed(ctx context.Context, id uint64, lastError string) error {
+	err := r.queries.MarkOutboxEntryFailed(ctx, pgdb.MarkOutboxEntryFailedParams{
+		LastError: sql.NullString{String: lastError, Valid: true},
+		ID:        int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox entry failed: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOutboxRepo) CountEntriesByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.queries.CountOutboxEntriesByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox entries by status: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (r *postgresOutboxRepo) ListRecentEntries(ctx context.Context, status string, limit int) ([]outbox_domain.Entry, error) {
+	dbEntries, err := r.queries.ListRecentOutboxEntries(ctx, pgdb.ListRecentOutboxEntriesParams{
+		Status:   status,
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox entries: %w", err)
+	}
+
+	entries := make([]outbox_domain.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		entries = append(entries, *r.dbEntryToDomain(dbEntry))
+	}
+
+	return entries, nil
+}
+
+func (r *postgresOutboxRepo) DeleteFinishedEntries(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteFinishedOutboxEntries(ctx, pgdb.DeleteFinishedOutboxEntriesParams{
+		UserID:    userID,
+		CreatedAt: before,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished outbox entries: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *postgresOutboxRepo) ClearSentMessages(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearSentOutboxMessages(ctx, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear sent outbox messages: %w", err)
+	}
+
+	return n, nil
+}
+
+// CreatePostgresEntry is CreateEntry for Postgres queries.
+func CreatePostgresEntry(ctx context.Context, q *pgdb.Queries, entry *outbox_domain.Entry) error {
+	var emailID sql.NullInt64
+	if entry.EmailID != nil {
+		emailID = sql.NullInt64{Int64: int64(*entry.EmailID), Valid: true}
+	}
+
+	var traceParent sql.NullString
+	if entry.TraceParent != nil {
+		traceParent = sql.NullString{String: *entry.TraceParent, Valid: true}
+	}
+
+	_, err := q.CreateOutboxEntry(ctx, pgdb.CreateOutboxEntryParams{
+		IdempotencyKey: entry.IdempotencyKey,
+		RetryKey:       entry.RetryKey,
+		UserID:         entry.UserID,
+		EmailID:        emailID,
+		Channel:        entry.Channel,
+		Recipient:      entry.Recipient,
+		Message:        entry.Message,
+		TraceParent:    traceParent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresOutboxRepo) dbEntryToDomain(dbEntry pgdb.NotificationOutbox) *outbox_domain.Entry {
+	entry := &outbox_domain.Entry{
+		ID:             uint64(dbEntry.ID),
+		IdempotencyKey: dbEntry.IdempotencyKey,
+		RetryKey:       dbEntry.RetryKey,
+		UserID:         dbEntry.UserID,
+		Channel:        dbEntry.Channel,
+		Recipient:      dbEntry.Recipient,
+		Message:        dbEntry.Message,
+		Status:         dbEntry.Status,
+		Attempts:       int(dbEntry.Attempts),
+		NextAttemptAt:  dbEntry.NextAttemptAt,
+		CreatedAt:      dbEntry.CreatedAt,
+		UpdatedAt:      dbEntry.UpdatedAt,
+	}
+
+	if dbEntry.EmailID.Valid {
+		emailID := uint64(dbEntry.EmailID.Int64)
+		entry.EmailID = &emailID
+	}
+	if dbEntry.LastError.Valid {
+		entry.LastError = &dbEntry.LastError.String
+	}
+	if dbEntry.TraceParent.Valid {
+		entry.TraceParent = &dbEntry.TraceParent.String
+	}
+	if dbEntry.SentAt.Valid {
+		entry.SentAt = &dbEntry.SentAt.Time
+	}
+
+	return entry
+}
@@ -0,0 +1,237 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	outbox_domain "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/infrastructure/sqlitedb"
+)
+
+// sqliteOutboxRepo relies on SQLite serializing writers instead of row locks,
+// so the database must be opened with a single connection.
+type sqliteOutboxRepo struct {
+	db      *sql.DB
+	queries *sqlitedb.Queries
+}
+
+var _ outbox_domain.OutboxRepo = (*sqliteOutboxRepo)(nil)
+
+func NewSQLiteOutboxRepo(dbConn *sql.DB) outbox_domain.OutboxRepo {
+	return &sqliteOutboxRepo{
+		db:      dbConn,
+		queries: sqlitedb.New(dbConn),
+	}
+}
+
+func (r *sqliteOutboxRepo) CreateEntry(ctx context.Context, entry *outbox_domain.Entry) error {
+	return CreateSQLiteEntry(ctx, r.queries, entry)
+}
+
+func (r *sqliteOutboxRepo) ClaimDueEntries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]outbox_domain.Entry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	dbEntries, err := qtx.GetDueOutboxEntries(ctx, sqlitedb.GetDueOutboxEntriesParams{
+		NextAttemptAt: now.UTC(),
+		Limit:         int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due outbox entries: %w", err)
+	}
+
+	entries := make([]outbox_domain.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		if err := qtx.LeaseOutboxEntry(ctx, sqlitedb.LeaseOutboxEntryParams{
+			NextAttemptAt: leaseUntil.UTC(),
+			ID:            dbEntry.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lease outbox entry: %w", err)
+		}
+
+		entry := r.dbEntryToDomain(dbEntry)
+		entry.Attempts++
+		entry.NextAttemptAt = leaseUntil
+		entries = append(entries, *entry)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *sqliteOutboxRepo) MarkSent(ctx context.Context, entry *outbox_domain.Entry, sentAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	if err := qtx.MarkOutboxEntrySent(ctx, sqlitedb.MarkOutboxEntrySentParams{
+		SentAt: sql.NullTime{Time: sentAt.UTC(), Valid: true},
+		ID:     int64(entry.ID),
+	}); err != nil {
+		return fmt.Errorf("failed to mark outbox entry sent: %w", err)
+	}
+
+	if entry.EmailID != nil {
+		if err := qtx.MarkEmailAsNotifiedByID(ctx, int64(*entry.EmailID)); err != nil {
+			return fmt.Errorf("failed to mark email as notified: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteOutboxRepo) Reschedule(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error {
+	err := r.queries.RescheduleOutboxEntry(ctx, sqlitedb.RescheduleOutboxEntryParams{
+		NextAttemptAt: nextAttemptAt.UTC(),
+		LastError:     sql.NullString{String: lastError, Valid: true},
+		ID:            int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteOutboxRepo) MarkFailed(ctx context.Context, id uint64, lastError string) error {
+	err := r.queries.MarkOutboxEntryFailed(ctx, sqlitedb.MarkOutboxEntryFailedParams{
+		LastError: sql.NullString{String: lastError, Valid: true},
+		ID:        int64(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox entry failed: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteOutboxRepo) CountEntriesByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.queries.CountOutboxEntriesByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox entries by status: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (r *sqliteOutboxRepo) ListRecentEntries(ctx context.Context, status string, limit int) ([]outbox_domain.Entry, error) {
+	dbEntries, err := r.queries.ListRecentOutboxEntries(ctx, sqlitedb.ListRecentOutboxEntriesParams{
+		Status: status,
+		Limit:  int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox entries: %w", err)
+	}
+
+	entries := make([]outbox_domain.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		entries = append(entries, *r.dbEntryToDomain(dbEntry))
+	}
+
+	return entries, nil
+}
+
+func (r *sqliteOutboxRepo) DeleteFinishedEntries(ctx context.Context, userID string, before time.Time, limit int) (int64, error) {
+	n, err := r.queries.DeleteFinishedOutboxEntries(ctx, sqlitedb.DeleteFinishedOutboxEntriesParams{
+		UserID:    userID,
+		CreatedAt: before.UTC(),
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished outbox entries: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *sqliteOutboxRepo) ClearSentMessages(ctx context.Context, limit int) (int64, error) {
+	n, err := r.queries.ClearSentOutboxMessages(ctx, int64(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear sent outbox messages: %w", err)
+	}
+
+	return n, nil
+}
+
+// CreateSQLiteEntry is CreateEntry for SQLite queries.
+func CreateSQLiteEntry(ctx context.Context, q *sqlitedb.Queries, entry *outbox_domain.Entry) error {
+	var emailID sql.NullInt64
+	if entry.EmailID != nil {
+		emailID = sql.NullInt64{Int64: int64(*entry.EmailID), Valid: true}
+	}
+
+	var traceParent sql.NullString
+	if entry.TraceParent != nil {
+		traceParent = sql.NullString{String: *entry.TraceParent, Valid: true}
+	}
+
+	_, err := q.CreateOutboxEntry(ctx, sqlitedb.CreateOutboxEntryParams{
+		IdempotencyKey: entry.IdempotencyKey,
+		RetryKey:       entry.RetryKey,
+		UserID:         entry.UserID,
+		EmailID:        emailID,
+		Channel:        entry.Channel,
+		Recipient:      entry.Recipient,
+		Message:        entry.Message,
+		TraceParent:    traceParent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteOutboxRepo) dbEntryToDomain(dbEntry sqlitedb.NotificationOutbox) *outbox_domain.Entry {
+	entry := &outbox_domain.Entry{
+		ID:             uint64(dbEntry.ID),
+		IdempotencyKey: dbEntry.IdempotencyKey,
+		RetryKey:       dbEntry.RetryKey,
+		UserID:         dbEntry.UserID,
+		Channel:        dbEntry.Channel,
+		Recipient:      dbEntry.Recipient,
+		Message:        dbEntry.Message,
+		Status:         dbEntry.Status,
+		Attempts:       int(dbEntry.Attempts),
+		NextAttemptAt:  dbEntry.NextAttemptAt,
+		CreatedAt:      dbEntry.CreatedAt,
+		UpdatedAt:      dbEntry.UpdatedAt,
+	}
+
+	if dbEntry.EmailID.Valid {
+		emailID := uint64(dbEntry.EmailID.Int64)
+		entry.EmailID = &emailID
+	}
+	if dbEntry.LastError.Valid {
+		entry.LastError = &dbEntry.LastError.String
+	}
+	if dbEntry.TraceParent.Valid {
+		entry.TraceParent = &dbEntry.TraceParent.String
+	}
+	if dbEntry.SentAt.Valid {
+		entry.SentAt = &dbEntry.SentAt.Time
+	}
+
+	return entry
+}
@@ -0,0 +1,488 @@
+package outlook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mail_domain "github.com/huavcjj/flux/internal/domain/mail"
+	"github.com/huavcjj/flux/internal/metrics"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// DefaultBaseURL serves Microsoft Graph.
+const DefaultBaseURL = "https://graph.microsoft.com/v1.0"
+
+const (
+	// inboxMessages is read for lists, notifications and syncs, like the
+	// INBOX label of Gmail watches.
+	inboxMessages = "me/mailFolders('inbox')/messages"
+	messageFields = "id,conversationId,from,toRecipients,subject,bodyPreview,receivedDateTime,webLink,isRead"
+
+	// subscriptionTTL stays below the 4230 minutes Graph allows for
+	// message subscriptions.
+	subscriptionTTL = 70 * time.Hour
+	deltaPageSize   = 50
+	maxSnippet      = 100
+	maxErrorBody    = 64 << 10
+
+	// immutableIDs keeps message IDs stable when messages move between
+	// folders, so stored IDs keep deduplicating.
+	immutableIDs = `IdType="ImmutableId"`
+)
+
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	// TenantID selects who can sign in: "common", "organizations",
+	// "consumers" or the ID of one tenant.
+	TenantID    string
+	RedirectURL string
+	// NotificationURL receives the change notifications of subscriptions.
+	NotificationURL string
+	// ClientState is sent back with every change notification, which
+	// proves that it comes from a subscription of flux.
+	ClientState string
+	BaseURL     string
+}
+
+type outlookRepo struct {
+	config          *oauth2.Config
+	ctx             context.Context
+	baseURL         string
+	notificationURL string
+	clientState     string
+}
+
+var _ mail_domain.MailProvider = (*outlookRepo)(nil)
+
+func NewOutlookRepo(ctx context.Context, cfg Config) mail_domain.MailProvider {
+	// Mail.ReadWrite is needed to mark messages as read from LINE.
+	config := &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Endpoint:     microsoft.AzureADEndpoint(cfg.TenantID),
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       []string{"offline_access", "Mail.ReadWrite"},
+	}
+
+	// Requests are traced as children of the span in their request context.
+	client := &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+
+	return &outlookRepo{
+		config:          config,
+		ctx:             context.WithValue(ctx, oauth2.HTTPClient, client),
+		baseURL:         strings.TrimSuffix(cfg.BaseURL, "/"),
+		notificationURL: cfg.NotificationURL,
+		clientState:     cfg.ClientState,
+	}
+}
+
+type graphRecipient struct {
+	EmailAddress struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	} `json:"emailAddress"`
+}
+
+// String formats the recipient like a From header.
+func (r graphRecipient) String() string {
+	if r.EmailAddress.Name == "" || r.EmailAddress.Name == r.EmailAddress.Address {
+		return r.EmailAddress.Address
+	}
+	return fmt.Sprintf("%s <%s>", r.EmailAddress.Name, r.EmailAddress.Address)
+}
+
+type graphMessage struct {
+	ID               string           `json:"id"`
+	ConversationID   string           `json:"conversationId"`
+	From             *graphRecipient  `json:"from"`
+	ToRecipients     []graphRecipient `json:"toRecipients"`
+	Subject          string           `json:"subject"`
+	BodyPreview      string           `json:"bodyPreview"`
+	ReceivedDateTime time.Time        `json:"receivedDateTime"`
+	WebLink          string           `json:"webLink"`
+	IsRead           bool             `json:"isRead"`
+	// Removed is set on delta entries of deleted messages.
+	Removed *struct {
+		Reason string `json:"reason"`
+	} `json:"@removed"`
+}
+
+func (m *graphMessage) toDomain() *mail_domain.Message {
+	var from string
+	if m.From != nil {
+		from = m.From.String()
+	}
+	to := make([]string, 0, len(m.ToRecipients))
+	for _, r := range m.ToRecipients {
+		to = append(to, r.String())
+	}
+
+	snippet := m.BodyPreview
+	if r := []rune(snippet); len(r) > maxSnippet {
+		snippet = string(r[:maxSnippet]) + "..."
+	}
+
+	return &mail_domain.Message{
+		ID:       m.ID,
+		ThreadID: m.ConversationID,
+		From:     from,
+		To:       strings.Join(to, ", "),
+		Subject:  m.Subject,
+		Snippet:  snippet,
+		Date:     m.ReceivedDateTime,
+		Link:     m.WebLink,
+	}
+}
+
+type messagePage struct {
+	Value     []graphMessage `json:"value"`
+	NextLink  string         `json:"@odata.nextLink"`
+	DeltaLink string         `json:"@odata.deltaLink"`
+}
+
+type subscription struct {
+	ID                       string    `json:"id,omitempty"`
+	ChangeType               string    `json:"changeType,omitempty"`
+	NotificationURL          string    `json:"notificationUrl,omitempty"`
+	LifecycleNotificationURL string    `json:"lifecycleNotificationUrl,omitempty"`
+	Resource                 string    `json:"resource,omitempty"`
+	ExpirationDateTime       time.Time `json:"expirationDateTime"`
+	ClientState              string    `json:"clientState,omitempty"`
+}
+
+// graphError is a failed Graph response.
+type graphError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *graphError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("graph returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("graph returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func isStatus(err error, statusCode int) bool {
+	var graphErr *graphError
+	return errors.As(err, &graphErr) && graphErr.StatusCode == statusCode
+}
+
+func (r *outlookRepo) GetUnreadMessages(ctx context.Context, token *oauth2.Token, maxResults int64) ([]*mail_domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "outlook.GetUnreadMessages")
+	defer span.End()
+
+	list, err := r.ListMessages(ctx, token, mail_domain.ListOptions{MaxResults: maxResults, UnreadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return list.Messages, nil
+}
+
+// ListMessages lists the inbox, newest first. Page tokens are the $skip of
+// the next page, which keeps them short enough for postback data.
+func (r *outlookRepo) ListMessages(ctx context.Context, token *oauth2.Token, opts mail_domain.ListOptions) (*mail_domain.MessageList, error) {
+	ctx, span := tracing.Start(ctx, "outlook.ListMessages", attribute.Bool("outlook.unread_only", opts.UnreadOnly))
+	defer span.End()
+
+	q := url.Values{}
+	q.Set("$top", strconv.FormatInt(opts.MaxResults, 10))
+	q.Set("$select", messageFields)
+	q.Set("$orderby", "receivedDateTime desc")
+
+	// Graph rejects filters that do not start with the property the list
+	// is ordered by.
+	var filters []string
+	if !opts.After.IsZero() {
+		filters = append(filters, "receivedDateTime gt "+opts.After.UTC().Format(time.RFC3339))
+	}
+	if opts.UnreadOnly {
+		if len(filters) == 0 {
+			filters = append(filters, "receivedDateTime ge 1900-01-01T00:00:00Z")
+		}
+		filters = append(filters, "isRead eq false")
+	}
+	if len(filters) > 0 {
+		q.Set("$filter", strings.Join(filters, " and "))
+	}
+	if opts.PageToken != "" {
+		if _, err := strconv.ParseUint(opts.PageToken, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid page token %q", opts.PageToken)
+		}
+		q.Set("$skip", opts.PageToken)
+	}
+
+	var page messagePage
+	if err := r.do(ctx, token, http.MethodGet, "messages.list", r.baseURL+"/"+inboxMessages+"?"+q.Encode(), nil, &page); err != nil {
+		return nil, fmt.Errorf("unable to retrieve messages: %w", err)
+	}
+
+	list := &mail_domain.MessageList{}
+	for i := range page.Value {
+		list.Messages = append(list.Messages, page.Value[i].toDomain())
+	}
+	if page.NextLink != "" {
+		if next, err := url.Parse(page.NextLink); err == nil {
+			list.NextPageToken = next.Query().Get("$skip")
+		}
+	}
+
+	return list, nil
+}
+
+func (r *outlookRepo) GetMessage(ctx context.Context, token *oauth2.Token, messageID string) (*mail_domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "outlook.GetMessage", tracing.MessageID(messageID))
+	defer span.End()
+
+	var msg graphMessage
+	rawURL := r.baseURL + "/me/messages/" + url.PathEscape(messageID) + "?" + url.Values{"$select": {messageFields}}.Encode()
+	if err := r.do(ctx, token, http.MethodGet, "messages.get", rawURL, nil, &msg); err != nil {
+		return nil, fmt.Errorf("unable to retrieve message: %w", err)
+	}
+
+	m := msg.toDomain()
+	span.SetAttributes(tracing.SenderDomain(m.From))
+	return m, nil
+}
+
+func (r *outlookRepo) MarkAsRead(ctx context.Context, token *oauth2.Token, messageIDs []string) error {
+	ctx, span := tracing.Start(ctx, "outlook.MarkAsRead", tracing.MessageCount(len(messageIDs)))
+	defer span.End()
+
+	for _, id := range messageIDs {
+		body := map[string]bool{"isRead": true}
+		if err := r.do(ctx, token, http.MethodPatch, "messages.update", r.baseURL+"/me/messages/"+url.PathEscape(id), body, nil); err != nil {
+			return fmt.Errorf("unable to mark messages as read: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WatchMailbox extends the subscription watchID, or creates a subscription
+// to new inbox messages if there is none. A new subscription starts a new
+// sync, whose cursor is returned with it.
+func (r *outlookRepo) WatchMailbox(ctx context.Context, token *oauth2.Token, watchID string) (*mail_domain.Watch, error) {
+	ctx, span := tracing.Start(ctx, "outlook.WatchMailbox", attribute.Bool("outlook.renew", watchID != ""))
+	defer span.End()
+
+	expiration := time.Now().Add(subscriptionTTL).UTC()
+
+	if watchID != "" {
+		var sub subscription
+		err := r.do(ctx, token, http.MethodPatch, "subscriptions.update", r.baseURL+"/subscriptions/"+url.PathEscape(watchID),
+			subscription{ExpirationDateTime: expiration}, &sub)
+		if err == nil {
+			return &mail_domain.Watch{ID: sub.ID, Expiration: sub.ExpirationDateTime}, nil
+		}
+		if !isStatus(err, http.StatusNotFound) {
+			return nil, fmt.Errorf("unable to renew subscription: %w", err)
+		}
+	}
+
+	// The cursor is taken first, so messages arriving while the
+	// subscription is created are still synced.
+	changes, err := r.GetChanges(ctx, token, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Lifecycle notifications go to the same endpoint, so a subscription
+	// Graph removes or wants reauthorized is renewed before it lapses.
+	var sub subscription
+	err = r.do(ctx, token, http.MethodPost, "subscriptions.create", r.baseURL+"/subscriptions", subscription{
+		ChangeType:               "created",
+		NotificationURL:          r.notificationURL,
+		LifecycleNotificationURL: r.notificationURL,
+		Resource:                 inboxMessages,
+		ExpirationDateTime:       expiration,
+		ClientState:              r.clientState,
+	}, &sub)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create subscription: %w", err)
+	}
+
+	return &mail_domain.Watch{
+		ID:         sub.ID,
+		Cursor:     changes.Cursor,
+		Expiration: sub.ExpirationDateTime,
+	}, nil
+}
+
+func (r *outlookRepo) StopWatch(ctx context.Context, token *oauth2.Token, watchID string) error {
+	ctx, span := tracing.Start(ctx, "outlook.StopWatch")
+	defer span.End()
+
+	if watchID == "" {
+		return nil
+	}
+
+	err := r.do(ctx, token, http.MethodDelete, "subscriptions.delete", r.baseURL+"/subscriptions/"+url.PathEscape(watchID), nil, nil)
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("unable to delete subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetChanges runs a delta query of the inbox. The cursor is the delta link
+// of the previous run; a new sync only covers messages received from now on.
+func (r *outlookRepo) GetChanges(ctx context.Context, token *oauth2.Token, cursor string) (*mail_domain.Changes, error) {
+	ctx, span := tracing.Start(ctx, "outlook.GetChanges")
+	defer span.End()
+
+	next := cursor
+	if next == "" {
+		q := url.Values{}
+		q.Set("$select", messageFields)
+		q.Set("$filter", "receivedDateTime ge "+time.Now().UTC().Format(time.RFC3339))
+		next = r.baseURL + "/" + inboxMessages + "/delta?" + q.Encode()
+	} else if !strings.HasPrefix(next, r.baseURL+"/") {
+		// The token would be sent wherever the cursor points.
+		return nil, fmt.Errorf("sync cursor is not a Graph URL")
+	}
+
+	changes := &mail_domain.Changes{}
+	for {
+		var page messagePage
+		err := r.do(ctx, token, http.MethodGet, "messages.delta", next, nil, &page)
+		if isStatus(err, http.StatusGone) {
+			return nil, fmt.Errorf("unable to sync messages: %w", mail_domain.ErrCursorExpired)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to sync messages: %w", err)
+		}
+
+		for i := range page.Value {
+			m := &page.Value[i]
+			if m.Removed != nil || m.IsRead || m.ReceivedDateTime.IsZero() {
+				continue
+			}
+			changes.Messages = append(changes.Messages, m.toDomain())
+		}
+
+		switch {
+		case page.DeltaLink != "":
+			changes.Cursor = page.DeltaLink
+			span.SetAttributes(tracing.MessageCount(len(changes.Messages)))
+			return changes, nil
+		case page.NextLink != "":
+			next = page.NextLink
+		default:
+			return nil, errors.New("delta response has neither a next nor a delta link")
+		}
+	}
+}
+
+// RevokeToken is not supported: the Microsoft identity platform can only
+// revoke every session of a user, not the grant of one app.
+func (r *outlookRepo) RevokeToken(ctx context.Context, token *oauth2.Token) error {
+	return mail_domain.ErrRevokeUnsupported
+}
+
+func (r *outlookRepo) RefreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	ctx, span := tracing.Start(ctx, "outlook.RefreshToken")
+	defer span.End()
+
+	// Without an access token the source always asks for a new one.
+	refreshed, err := r.config.TokenSource(r.ctx, &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+	observeCall(ctx, "token.refresh", 0, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return refreshed, nil
+}
+
+func (r *outlookRepo) GetAuthURL(state string) string {
+	return r.config.AuthCodeURL(state)
+}
+
+func (r *outlookRepo) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+	token, err := r.config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return token, nil
+}
+
+// do sends a Graph request with the user's token and decodes the response
+// into out, if given.
+func (r *outlookRepo) do(ctx context.Context, token *oauth2.Token, method, operation, rawURL string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	prefer := immutableIDs
+	if operation == "messages.delta" {
+		prefer += ", odata.maxpagesize=" + strconv.Itoa(deltaPageSize)
+	}
+	req.Header.Set("Prefer", prefer)
+
+	// The token is used as is; the caller refreshes it through RefreshToken
+	// so that the rotated refresh token is saved.
+	resp, err := oauth2.NewClient(r.ctx, oauth2.StaticTokenSource(token)).Do(req)
+	if err != nil {
+		observeCall(ctx, operation, 0, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var errBody struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&errBody)
+		err := &graphError{StatusCode: resp.StatusCode, Code: errBody.Error.Code, Message: errBody.Error.Message}
+		observeCall(ctx, operation, resp.StatusCode, err)
+		return err
+	}
+	observeCall(ctx, operation, resp.StatusCode, nil)
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// observeCall records the outcome of a Graph call on the current span and
+// in metrics.
+func observeCall(ctx context.Context, operation string, statusCode int, err error) {
+	tracing.RecordError(trace.SpanFromContext(ctx), err)
+	metrics.ObserveGraphCall(operation, statusCode, err)
+}
@@ -0,0 +1,167 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+	"github.com/huavcjj/flux/internal/infrastructure/httpclient"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+// DefaultBaseURL serves Slack incoming webhooks.
+const DefaultBaseURL = "https://hooks.slack.com"
+
+const (
+	webhookPath = "/services/"
+
+	labelSender   = "差出人"
+	labelSubject  = "件名"
+	labelOpenMail = "メールを開く"
+	noSubject     = "(件名なし)"
+
+	maxHeaderText  = 150
+	maxFieldText   = 2000
+	maxSectionText = 3000
+
+	requestTimeout = 10 * time.Second
+)
+
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type slackRepo struct {
+	baseURL string
+	client  *http.Client
+}
+
+var _ notifier_domain.Notifier = (*slackRepo)(nil)
+
+// NewSlackRepo returns a notifier posting to incoming webhooks under
+// baseURL. Recipients are webhook URLs.
+func NewSlackRepo(baseURL string) notifier_domain.Notifier {
+	return &slackRepo{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpclient.New(requestTimeout),
+	}
+}
+
+// ValidateRecipient accepts incoming webhook URLs under the base URL.
+func (r *slackRepo) ValidateRecipient(recipient string) error {
+	prefix := r.baseURL + webhookPath
+	if !strings.HasPrefix(recipient, prefix) || len(recipient) == len(prefix) {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	if u, err := url.Parse(recipient); err != nil || u.RawQuery != "" || u.Fragment != "" {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	return nil
+}
+
+func (r *slackRepo) Send(ctx context.Context, recipient string, n notifier_domain.Notification) error {
+	ctx, span := tracing.Start(ctx, "slack.Send")
+	defer span.End()
+
+	if err := r.ValidateRecipient(recipient); err != nil {
+		return tracing.Error(span, fmt.Errorf("recipient is not a webhook under %s: %w", r.baseURL, err))
+	}
+
+	body, err := json.Marshal(render(n))
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to encode Slack message: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient, bytes.NewReader(body))
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to create Slack request: %w", httpclient.Redact(err)))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to post Slack message: %w", httpclient.Redact(err)))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := time.Second
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			retryAfter = time.Duration(s) * time.Second
+		}
+		return tracing.Error(span, fmt.Errorf("slack webhook returned %s: %w", resp.Status, &notifier_domain.RateLimitError{RetryAfter: retryAfter}))
+	}
+	if resp.StatusCode != http.StatusOK {
+		// The body is a short error code such as no_service.
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return tracing.Error(span, fmt.Errorf("slack webhook returned %s: %s", resp.Status, strings.TrimSpace(string(b))))
+	}
+
+	return nil
+}
+
+type message struct {
+	Text   string  `json:"text"`
+	Blocks []block `json:"blocks"`
+}
+
+type block struct {
+	Type     string   `json:"type"`
+	Text     *text    `json:"text,omitempty"`
+	Fields   []text   `json:"fields,omitempty"`
+	Elements []button `json:"elements,omitempty"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type button struct {
+	Type string `json:"type"`
+	Text text   `json:"text"`
+	URL  string `json:"url"`
+}
+
+// render builds the Block Kit message of n. Text stays the fallback shown
+// in notifications.
+func render(n notifier_domain.Notification) message {
+	subject := n.Subject
+	if subject == "" {
+		subject = noSubject
+	}
+
+	msg := message{
+		Text: n.Text,
+		Blocks: []block{
+			{Type: "header", Text: &text{Type: "plain_text", Text: truncate(n.Title, maxHeaderText)}},
+			{Type: "section", Fields: []text{
+				{Type: "mrkdwn", Text: truncate("*"+labelSender+"*\n"+mrkdwnEscaper.Replace(n.Sender), maxFieldText)},
+				{Type: "mrkdwn", Text: truncate("*"+labelSubject+"*\n"+mrkdwnEscaper.Replace(subject), maxFieldText)},
+			}},
+		},
+	}
+	if n.Snippet != "" {
+		msg.Blocks = append(msg.Blocks, block{Type: "section", Text: &text{Type: "mrkdwn", Text: truncate(mrkdwnEscaper.Replace(n.Snippet), maxSectionText)}})
+	}
+	if n.Link != "" {
+		msg.Blocks = append(msg.Blocks, block{Type: "actions", Elements: []button{
+			{Type: "button", Text: text{Type: "plain_text", Text: labelOpenMail}, URL: n.Link},
+		}})
+	}
+	return msg
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,114 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+)
+
+func TestSend(t *testing.T) {
+	var got message
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/T000/B000/XXX" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	repo := NewSlackRepo(srv.URL)
+	err := repo.Send(context.Background(), srv.URL+"/services/T000/B000/XXX", notifier_domain.Notification{
+		Title:   "新着メール",
+		Sender:  "Alice <alice@example.com>",
+		Snippet: "a < b & c",
+		Link:    "https://mail.google.com/mail/#inbox/1",
+		Text:    "新着メール: Alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	if got.Text != "新着メール: Alice" {
+		t.Errorf("text = %q", got.Text)
+	}
+	if len(got.Blocks) != 4 {
+		t.Fatalf("got %d blocks, want header, fields, snippet and actions", len(got.Blocks))
+	}
+	if b := got.Blocks[0]; b.Type != "header" || b.Text.Text != "新着メール" {
+		t.Errorf("header = %+v", b)
+	}
+	fields := got.Blocks[1].Fields
+	if len(fields) != 2 || fields[0].Text != "*差出人*\nAlice &lt;alice@example.com&gt;" || fields[1].Text != "*件名*\n(件名なし)" {
+		t.Errorf("fields = %+v", fields)
+	}
+	if b := got.Blocks[2]; b.Text == nil || b.Text.Text != "a &lt; b &amp; c" {
+		t.Errorf("snippet = %+v", b)
+	}
+	if b := got.Blocks[3]; len(b.Elements) != 1 || b.Elements[0].URL != "https://mail.google.com/mail/#inbox/1" {
+		t.Errorf("actions = %+v", b)
+	}
+}
+
+func TestSendRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	err := NewSlackRepo(srv.URL).Send(context.Background(), srv.URL+"/services/T000/B000/XXX", notifier_domain.Notification{Text: "x"})
+	var rateLimit *notifier_domain.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("Send = %v, want a RateLimitError", err)
+	}
+	if rateLimit.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %s, want 30s", rateLimit.RetryAfter)
+	}
+}
+
+func TestSendErrorHidesURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	recipient := srv.URL + "/services/T000/B000/SECRET"
+	srv.Close()
+
+	err := NewSlackRepo(srv.URL).Send(context.Background(), recipient, notifier_domain.Notification{Text: "x"})
+	if err == nil {
+		t.Fatal("Send to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("error %q contains the webhook URL", err)
+	}
+}
+
+func TestValidateRecipient(t *testing.T) {
+	repo := NewSlackRepo(DefaultBaseURL)
+	tests := []struct {
+		recipient string
+		valid     bool
+	}{
+		{"https://hooks.slack.com/services/T000/B000/XXX", true},
+		{"https://hooks.slack.com/services/", false},
+		{"https://hooks.slack.com/services/T000?x=1", false},
+		{"https://example.com/services/T000/B000/XXX", false},
+	}
+	for _, tt := range tests {
+		if err := repo.ValidateRecipient(tt.recipient); (err == nil) != tt.valid {
+			t.Errorf("ValidateRecipient(%q) = %v, want valid %v", tt.recipient, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	line_domain "github.com/huavcjj/flux/internal/domain/line"
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+	telegram_domain "github.com/huavcjj/flux/internal/domain/telegram"
+	"github.com/huavcjj/flux/internal/infrastructure/httpclient"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+// DefaultBaseURL serves the Bot API.
+const DefaultBaseURL = "https://api.telegram.org"
+
+const (
+	labelSender   = "差出人"
+	labelSubject  = "件名"
+	labelOpenMail = "メールを開く"
+	noSubject     = "(件名なし)"
+
+	maxText         = 4096
+	maxField        = 256
+	maxSnippet      = 3000
+	maxCallbackData = 64
+
+	requestTimeout = 10 * time.Second
+)
+
+type telegramRepo struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+var _ telegram_domain.TelegramRepo = (*telegramRepo)(nil)
+
+// NewTelegramRepo returns a Bot API client of the bot with token.
+func NewTelegramRepo(baseURL, token string) telegram_domain.TelegramRepo {
+	return &telegramRepo{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  httpclient.New(requestTimeout),
+	}
+}
+
+// ValidateRecipient accepts chat IDs.
+func (r *telegramRepo) ValidateRecipient(recipient string) error {
+	if _, err := strconv.ParseInt(recipient, 10, 64); err != nil {
+		return notifier_domain.ErrInvalidRecipient
+	}
+	return nil
+}
+
+func (r *telegramRepo) Send(ctx context.Context, recipient string, n notifier_domain.Notification) error {
+	ctx, span := tracing.Start(ctx, "telegram.Send")
+	defer span.End()
+
+	if err := r.ValidateRecipient(recipient); err != nil {
+		return tracing.Error(span, fmt.Errorf("recipient is not a chat ID: %w", err))
+	}
+
+	msg := render(n)
+	msg.ChatID = recipient
+	return tracing.Error(span, r.call(ctx, "sendMessage", msg))
+}
+
+// Respond sends messages as plain text, with their quick replies as an
+// inline keyboard. Buttons whose callback data would not fit are left out.
+func (r *telegramRepo) Respond(ctx context.Context, recipient string, messages ...line_domain.Message) error {
+	ctx, span := tracing.Start(ctx, "telegram.Respond")
+	defer span.End()
+
+	for _, m := range messages {
+		msg := sendMessage{
+			ChatID:      recipient,
+			Text:        truncate(m.Text, maxText),
+			ReplyMarkup: keyboard(m.QuickReply),
+		}
+		if err := r.call(ctx, "sendMessage", msg); err != nil {
+			return tracing.Error(span, err)
+		}
+	}
+	return nil
+}
+
+func (r *telegramRepo) AnswerCallbackQuery(ctx context.Context, callbackQueryID string) error {
+	return r.call(ctx, "answerCallbackQuery", map[string]string{"callback_query_id": callbackQueryID})
+}
+
+// call invokes a Bot API method. A 429 is returned as a RateLimitError.
+func (r *telegramRepo) call(ctx context.Context, method string, params any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode Telegram %s: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/bot"+r.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Telegram request: %w", httpclient.Redact(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Telegram %s: %w", method, httpclient.Redact(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("failed to decode Telegram %s response: %w", method, err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := time.Duration(result.Parameters.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		return fmt.Errorf("telegram %s returned %s: %w", method, resp.Status, &notifier_domain.RateLimitError{RetryAfter: retryAfter})
+	}
+	if resp.StatusCode != http.StatusOK || !result.OK {
+		return fmt.Errorf("telegram %s returned %s: %s", method, resp.Status, result.Description)
+	}
+
+	return nil
+}
+
+type sendMessage struct {
+	ChatID             string              `json:"chat_id"`
+	Text               string              `json:"text"`
+	ParseMode          string              `json:"parse_mode,omitempty"`
+	LinkPreviewOptions *linkPreviewOptions `json:"link_preview_options,omitempty"`
+	ReplyMarkup        *inlineKeyboard     `json:"reply_markup,omitempty"`
+}
+
+type linkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+type inlineKeyboard struct {
+	InlineKeyboard [][]inlineButton `json:"inline_keyboard"`
+}
+
+type inlineButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// render formats n as HTML: the title, sender and subject, then the
+// snippet, with a button opening the message.
+func render(n notifier_domain.Notification) sendMessage {
+	subject := n.Subject
+	if subject == "" {
+		subject = noSubject
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(truncate(n.Title, maxField)))
+	if n.Sender != "" {
+		fmt.Fprintf(&b, "%s: %s\n", labelSender, html.EscapeString(truncate(n.Sender, maxField)))
+	}
+	fmt.Fprintf(&b, "%s: <b>%s</b>", labelSubject, html.EscapeString(truncate(subject, maxField)))
+	if n.Snippet != "" {
+		fmt.Fprintf(&b, "\n\n%s", html.EscapeString(truncate(n.Snippet, maxSnippet)))
+	}
+
+	msg := sendMessage{
+		Text:               b.String(),
+		ParseMode:          "HTML",
+		LinkPreviewOptions: &linkPreviewOptions{IsDisabled: true},
+	}
+	if n.Link != "" {
+		msg.ReplyMarkup = &inlineKeyboard{InlineKeyboard: [][]inlineButton{{{Text: labelOpenMail, URL: n.Link}}}}
+	}
+	return msg
+}
+
+// keyboard renders quick reply actions as an inline keyboard, one button
+// per row.
+func keyboard(actions []line_domain.Action) *inlineKeyboard {
+	var rows [][]inlineButton
+	for _, a := range actions {
+		button := inlineButton{Text: a.Label}
+		switch a.Type {
+		case line_domain.ActionTypeURI:
+			button.URL = a.URI
+		case line_domain.ActionTypeMessage:
+			button.CallbackData = telegram_domain.CallbackMessagePrefix + a.Text
+		case line_domain.ActionTypePostback:
+			button.CallbackData = telegram_domain.CallbackPostbackPrefix + a.Data
+		}
+		if button.URL == "" && (button.CallbackData == "" || len(button.CallbackData) > maxCallbackData) {
+			continue
+		}
+		rows = append(rows, []inlineButton{button})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &inlineKeyboard{InlineKeyboard: rows}
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,144 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	line_domain "github.com/huavcjj/flux/internal/domain/line"
+	notifier_domain "github.com/huavcjj/flux/internal/domain/notifier"
+)
+
+const token = "123456:SECRET"
+
+// stub serves the Bot API and records the requests it receives.
+type stub struct {
+	paths    []string
+	messages []sendMessage
+	status   int
+	response string
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.paths = append(s.paths, r.URL.Path)
+	var msg sendMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err == nil {
+		s.messages = append(s.messages, msg)
+	}
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+	if s.response == "" {
+		s.response = `{"ok": true, "result": {}}`
+	}
+	w.Write([]byte(s.response))
+}
+
+func TestSend(t *testing.T) {
+	s := &stub{}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	err := NewTelegramRepo(srv.URL, token).Send(context.Background(), "-100123", notifier_domain.Notification{
+		Title:   "新着メール",
+		Sender:  "Alice <alice@example.com>",
+		Subject: "Q&A",
+		Snippet: "1 < 2",
+		Link:    "https://mail.google.com/mail/#inbox/1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.paths) != 1 || s.paths[0] != "/bot"+token+"/sendMessage" {
+		t.Fatalf("paths = %v", s.paths)
+	}
+	msg := s.messages[0]
+	if msg.ChatID != "-100123" || msg.ParseMode != "HTML" {
+		t.Errorf("chat_id = %q, parse_mode = %q", msg.ChatID, msg.ParseMode)
+	}
+	want := "<b>新着メール</b>\n差出人: Alice &lt;alice@example.com&gt;\n件名: <b>Q&amp;A</b>\n\n1 &lt; 2"
+	if msg.Text != want {
+		t.Errorf("text = %q\nwant %q", msg.Text, want)
+	}
+	if msg.LinkPreviewOptions == nil || !msg.LinkPreviewOptions.IsDisabled {
+		t.Error("link previews are not disabled")
+	}
+	if msg.ReplyMarkup == nil || msg.ReplyMarkup.InlineKeyboard[0][0].URL != "https://mail.google.com/mail/#inbox/1" {
+		t.Errorf("reply_markup = %+v", msg.ReplyMarkup)
+	}
+}
+
+func TestRespondKeyboard(t *testing.T) {
+	s := &stub{}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	m := line_domain.NewTextMessage("done").WithQuickReply(
+		line_domain.MessageAction("未読", "未読mail"),
+		line_domain.PostbackAction("次へ", "action=next", "次へ"),
+		line_domain.PostbackAction("長すぎ", strings.Repeat("x", maxCallbackData), ""),
+	)
+	if err := NewTelegramRepo(srv.URL, token).Respond(context.Background(), "42", m); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := s.messages[0].ReplyMarkup.InlineKeyboard
+	if len(rows) != 2 {
+		t.Fatalf("got %d buttons, want 2 without the one whose data does not fit", len(rows))
+	}
+	if rows[0][0].CallbackData == "" || rows[1][0].CallbackData == "" {
+		t.Errorf("buttons = %+v", rows)
+	}
+}
+
+func TestSendRateLimited(t *testing.T) {
+	s := &stub{
+		status:   http.StatusTooManyRequests,
+		response: `{"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 7", "parameters": {"retry_after": 7}}`,
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	err := NewTelegramRepo(srv.URL, token).Send(context.Background(), "42", notifier_domain.Notification{Title: "x"})
+	var rateLimit *notifier_domain.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("Send = %v, want a RateLimitError", err)
+	}
+	if rateLimit.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %s, want 7s", rateLimit.RetryAfter)
+	}
+}
+
+func TestCallFailed(t *testing.T) {
+	s := &stub{
+		status:   http.StatusBadRequest,
+		response: `{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`,
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	err := NewTelegramRepo(srv.URL, token).Send(context.Background(), "42", notifier_domain.Notification{Title: "x"})
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("Send = %v, want the API description", err)
+	}
+}
+
+func TestCallErrorHidesToken(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	err := NewTelegramRepo(url, token).Send(context.Background(), "42", notifier_domain.Notification{Title: "x"})
+	if err == nil {
+		t.Fatal("Send to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("error %q contains the bot token", err)
+	}
+}
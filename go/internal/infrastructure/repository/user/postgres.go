@@ -0,0 +1,338 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	mail_domain "github.com/huavcjj/flux/internal/domain/mail"
+	user_domain "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/infrastructure/pgdb"
+	"golang.org/x/oauth2"
+)
+
+type postgresUserRepo struct {
+	db      *sql.DB
+	queries *pgdb.Queries
+}
+
+var _ user_domain.UserRepo = (*postgresUserRepo)(nil)
+
+func NewPostgresUserRepo(dbConn *sql.DB) user_domain.UserRepo {
+	return &postgresUserRepo{
+		db:      dbConn,
+		queries: pgdb.New(dbConn),
+	}
+}
+
+func (r *postgresUserRepo) CreateUser(ctx context.Context, user *user_domain.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+
+	var gmailAccessToken, gmailRefreshToken sql.NullString
+	var gmailTokenExpiresAt sql.NullInt64
+
+	if user.MailAccessToken != nil {
+		gmailAccessToken = sql.NullString{String: *user.MailAccessToken, Valid: true}
+	}
+	if user.MailRefreshToken != nil {
+		gmailRefreshToken = sql.NullString{String: *user.MailRefreshToken, Valid: true}
+	}
+	if user.MailTokenExpiresAt != nil {
+		gmailTokenExpiresAt = sql.NullInt64{Int64: *user.MailTokenExpiresAt, Valid: true}
+	}
+
+	_, err := r.queries.CreateUser(ctx, pgdb.CreateUserParams{
+		ID:                 user.ID,
+		LineUserID:         user.LineUserID,
+		MailAccessToken:    gmailAccessToken,
+		MailRefreshToken:   gmailRefreshToken,
+		MailTokenExpiresAt: gmailTokenExpiresAt,
+		IsActive:           true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepo) GetUserByLineUserID(ctx context.Context, lineUserID string) (*user_domain.User, error) {
+	dbUser, err := r.queries.GetUserByLineUserID(ctx, lineUserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by line user id: %w", err)
+	}
+
+	return r.dbUserToDomain(dbUser), nil
+}
+
+func (r *postgresUserRepo) GetUserByID(ctx context.Context, userID string) (*user_domain.User, error) {
+	dbUser, err := r.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	return r.dbUserToDomain(dbUser), nil
+}
+
+func (r *postgresUserRepo) GetUserByMailWatchID(ctx context.Context, watchID string) (*user_domain.User, error) {
+	dbUser, err := r.queries.GetUserByMailWatchID(ctx, sql.NullString{String: watchID, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by mail watch id: %w", err)
+	}
+
+	return r.dbUserToDomain(dbUser), nil
+}
+
+func (r *postgresUserRepo) UpdateMailTokens(ctx context.Context, lineUserID, provider string, token *oauth2.Token) error {
+	accessToken, refreshToken, expiresAt := tokenColumns(token)
+
+	err := r.queries.UpdateUserMailTokens(ctx, pgdb.UpdateUserMailTokensParams{
+		MailProvider:       provider,
+		MailAccessToken:    accessToken,
+		MailRefreshToken:   refreshToken,
+		MailTokenExpiresAt: expiresAt,
+		LineUserID:         lineUserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update mail tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepo) UpdateRefreshedMailTokens(ctx context.Context, userID, provider string, token *oauth2.Token) error {
+	accessToken, refreshToken, expiresAt := tokenColumns(token)
+
+	err := r.queries.UpdateUserRefreshedMailTokens(ctx, pgdb.UpdateUserRefreshedMailTokensParams{
+		MailAccessToken:    accessToken,
+		MailRefreshToken:   refreshToken,
+		MailTokenExpiresAt: expiresAt,
+		ID:                 userID,
+		MailProvider:       provider,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update refreshed mail tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepo) UpdateMailWatch(ctx context.Context, lineUserID string, watch *mail_domain.Watch) error {
+	err := r.queries.UpdateUserMailWatch(ctx, pgdb.UpdateUserMailWatchParams{
+		GmailHistoryID:     sql.NullInt64{Int64: int64(watch.HistoryID), Valid: watch.HistoryID != 0},
+		MailWatchExpiresAt: sql.NullInt64{Int64: watch.Expiration.Unix(), Valid: !watch.Expiration.IsZero()},
+		MailWatchID:        sql.NullString{String: watch.ID, Valid: watch.ID != ""},
+		MailSyncCursor:     sql.NullString{String: watch.Cursor, Valid: watch.Cursor != ""},
+		LineUserID:         lineUserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update mail watch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepo) UpdateMailSyncCursor(ctx context.Context, userID, cursor string) error {
+	err := r.queries.UpdateUserMailSyncCursor(ctx, pgdb.UpdateUserMailSyncCursorParams{
+		MailSyncCursor: sql.NullString{String: cursor, Valid: cursor != ""},
+		ID:             userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update mail sync cursor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepo) CountWatchesExpiringBetween(ctx context.Context, from, to time.Time) (int64, error) {
+	count, err := r.queries.CountUsersWithWatchExpiringBetween(ctx, pgdb.CountUsersWithWatchExpiringBetweenParams{
+		FromUnix: sql.NullInt64{Int64: from.Unix(), Valid: true},
+		ToUnix:   sql.NullInt64{Int64: to.Unix(), Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expiring watches: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *postgresUserRepo) GetAllActiveUsers(ctx context.Context) ([]user_domain.User, error) {
+	dbUsers, err := r.queries.GetAllActiveUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all active users: %w", err)
+	}
+
+	users := make([]user_domain.User, 0, len(dbUsers))
+	for _, dbUser := range dbUsers {
+		users = append(users, *r.dbUserToDomain(dbUser))
+	}
+
+	return users, nil
+}
+
+func (r *postgresUserRepo) GetUsersWithWatchExpiringBefore(ctx context.Context, before time.Time) ([]user_domain.User, error) {
+	dbUsers, err := r.queries.GetUsersWithWatchExpiringBefore(ctx, sql.NullInt64{Int64: before.Unix(), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with expiring watches: %w", err)
+	}
+
+	users := make([]user_domain.User, 0, len(dbUsers))
+	for _, dbUser := range dbUsers {
+		users = append(users, *r.dbUserToDomain(dbUser))
+	}
+
+	return users, nil
+}
+
+func (r *postgresUserRepo) ListUsers(ctx context.Context, filter user_domain.ListFilter) ([]user_domain.User, error) {
+	dbUsers, err := r.queries.ListUsers(ctx, pgdb.ListUsersParams{
+		Query:     filter.Query,
+		Pattern:   "%" + filter.Query + "%",
+		RowLimit:  int32(filter.Limit),
+		RowOffset: int32(filter.Offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users := make([]user_domain.User, 0, len(dbUsers))
+	for _, dbUser := range dbUsers {
+		users = append(users, *r.dbUserToDomain(dbUser))
+	}
+
+	return users, nil
+}
+
+func (r *postgresUserRepo) DeactivateUser(ctx context.Context, userID string) (bool, error) {
+	n, err := r.queries.DeactivateUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (r *postgresUserRepo) UpdateEmailRetention(ctx context.Context, lineUserID string, days *int) error {
+	var retentionDays sql.NullInt32
+	if days != nil {
+		retentionDays = sql.NullInt32{Int32: int32(*days), Valid: true}
+	}
+
+	err := r.queries.UpdateUserEmailRetention(ctx, pgdb.UpdateUserEmailRetentionParams{
+		EmailRetentionDays: retentionDays,
+		LineUserID:         lineUserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update email retention: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepo) dbUserToDomain(dbUser pgdb.User) *user_domain.User {
+	user := &user_domain.User{
+		ID:           dbUser.ID,
+		LineUserID:   dbUser.LineUserID,
+		MailProvider: dbUser.MailProvider,
+		IsActive:     dbUser.IsActive,
+		CreatedAt:    dbUser.CreatedAt,
+		UpdatedAt:    dbUser.UpdatedAt,
+	}
+
+	if dbUser.MailAccessToken.Valid {
+		token := dbUser.MailAccessToken.String
+		user.MailAccessToken = &token
+	}
+	if dbUser.MailRefreshToken.Valid {
+		token := dbUser.MailRefreshToken.String
+		user.MailRefreshToken = &token
+	}
+	if dbUser.MailTokenExpiresAt.Valid {
+		expiresAt := dbUser.MailTokenExpiresAt.Int64
+		user.MailTokenExpiresAt = &expiresAt
+	}
+	if dbUser.GmailHistoryID.Valid {
+		historyID := uint64(dbUser.GmailHistoryID.Int64)
+		user.GmailHistoryID = &historyID
+	}
+	if dbUser.MailWatchExpiresAt.Valid {
+		watchExpiresAt := dbUser.MailWatchExpiresAt.Int64
+		user.MailWatchExpiresAt = &watchExpiresAt
+	}
+	if dbUser.MailWatchID.Valid {
+		watchID := dbUser.MailWatchID.String
+		user.MailWatchID = &watchID
+	}
+	if dbUser.MailSyncCursor.Valid {
+		cursor := dbUser.MailSyncCursor.String
+		user.MailSyncCursor = &cursor
+	}
+	if dbUser.EmailRetentionDays.Valid {
+		days := int(dbUser.EmailRetentionDays.Int32)
+		user.EmailRetentionDays = &days
+	}
+
+	return user
+}
+
+func (r *postgresUserRepo) EraseUser(ctx context.Context, userID string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	if err := qtx.DeleteOutboxEntriesByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	if err := qtx.DeleteEmailsByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete emails: %w", err)
+	}
+	if err := qtx.DeleteNotificationChannelsByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete notification channels: %w", err)
+	}
+	if err := qtx.DeleteChannelLinkCodesByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete link codes: %w", err)
+	}
+	if err := qtx.DeleteWebhookDeliveriesByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	if _, err := qtx.DeleteWebhookEndpointByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	// The trail itself stays, but without the client details of the person.
+	if err := qtx.ClearAuditEventClientInfo(ctx, sql.NullString{String: userID, Valid: true}); err != nil {
+		return false, fmt.Errorf("failed to clear audit client info: %w", err)
+	}
+	// Queued, failed and dead jobs carry the IDs of the user in their
+	// payloads; they go before the user row they are matched against.
+	if err := qtx.DeleteJobsByUserID(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete jobs: %w", err)
+	}
+
+	n, err := qtx.DeleteUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit user erasure: %w", err)
+	}
+
+	return n > 0, nil
+}
@@ -0,0 +1,898 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+func New(db DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
+	q := Queries{db: db}
+	var err error
+	if q.clearAuditEventClientInfoStmt, err = db.PrepareContext(ctx, clearAuditEventClientInfo); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearAuditEventClientInfo: %w", err)
+	}
+	if q.clearDeliveredWebhookPayloadsStmt, err = db.PrepareContext(ctx, clearDeliveredWebhookPayloads); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearDeliveredWebhookPayloads: %w", err)
+	}
+	if q.clearNotifiedEmailContentStmt, err = db.PrepareContext(ctx, clearNotifiedEmailContent); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearNotifiedEmailContent: %w", err)
+	}
+	if q.clearSentOutboxMessagesStmt, err = db.PrepareContext(ctx, clearSentOutboxMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearSentOutboxMessages: %w", err)
+	}
+	if q.countJobsByStatusStmt, err = db.PrepareContext(ctx, countJobsByStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query CountJobsByStatus: %w", err)
+	}
+	if q.countOutboxEntriesByStatusStmt, err = db.PrepareContext(ctx, countOutboxEntriesByStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query CountOutboxEntriesByStatus: %w", err)
+	}
+	if q.countUsersWithWatchExpiringBetweenStmt, err = db.PrepareContext(ctx, countUsersWithWatchExpiringBetween); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUsersWithWatchExpiringBetween: %w", err)
+	}
+	if q.createAuditEventStmt, err = db.PrepareContext(ctx, createAuditEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAuditEvent: %w", err)
+	}
+	if q.createChannelLinkCodeStmt, err = db.PrepareContext(ctx, createChannelLinkCode); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateChannelLinkCode: %w", err)
+	}
+	if q.createEmailStmt, err = db.PrepareContext(ctx, createEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateEmail: %w", err)
+	}
+	if q.createJobStmt, err = db.PrepareContext(ctx, createJob); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateJob: %w", err)
+	}
+	if q.createOutboxEntryStmt, err = db.PrepareContext(ctx, createOutboxEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateOutboxEntry: %w", err)
+	}
+	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
+	}
+	if q.createWebhookDeliveryStmt, err = db.PrepareContext(ctx, createWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhookDelivery: %w", err)
+	}
+	if q.createWebhookEventStmt, err = db.PrepareContext(ctx, createWebhookEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhookEvent: %w", err)
+	}
+	if q.deactivateUserStmt, err = db.PrepareContext(ctx, deactivateUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeactivateUser: %w", err)
+	}
+	if q.deadLetterStaleJobsStmt, err = db.PrepareContext(ctx, deadLetterStaleJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query DeadLetterStaleJobs: %w", err)
+	}
+	if q.deleteChannelLinkCodeStmt, err = db.PrepareContext(ctx, deleteChannelLinkCode); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteChannelLinkCode: %w", err)
+	}
+	if q.deleteChannelLinkCodesByUserIDStmt, err = db.PrepareContext(ctx, deleteChannelLinkCodesByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteChannelLinkCodesByUserID: %w", err)
+	}
+	if q.deleteDoneJobsBeforeStmt, err = db.PrepareContext(ctx, deleteDoneJobsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteDoneJobsBefore: %w", err)
+	}
+	if q.deleteEmailsByUserIDStmt, err = db.PrepareContext(ctx, deleteEmailsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteEmailsByUserID: %w", err)
+	}
+	if q.deleteEmailsOlderThanStmt, err = db.PrepareContext(ctx, deleteEmailsOlderThan); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteEmailsOlderThan: %w", err)
+	}
+	if q.deleteExpiredChannelLinkCodesStmt, err = db.PrepareContext(ctx, deleteExpiredChannelLinkCodes); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredChannelLinkCodes: %w", err)
+	}
+	if q.deleteExpiredWebhookEventsStmt, err = db.PrepareContext(ctx, deleteExpiredWebhookEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredWebhookEvents: %w", err)
+	}
+	if q.deleteFinishedOutboxEntriesStmt, err = db.PrepareContext(ctx, deleteFinishedOutboxEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFinishedOutboxEntries: %w", err)
+	}
+	if q.deleteFinishedWebhookDeliveriesStmt, err = db.PrepareContext(ctx, deleteFinishedWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFinishedWebhookDeliveries: %w", err)
+	}
+	if q.deleteJobsByUserIDStmt, err = db.PrepareContext(ctx, deleteJobsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteJobsByUserID: %w", err)
+	}
+	if q.deleteNotificationChannelStmt, err = db.PrepareContext(ctx, deleteNotificationChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteNotificationChannel: %w", err)
+	}
+	if q.deleteNotificationChannelsByUserIDStmt, err = db.PrepareContext(ctx, deleteNotificationChannelsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteNotificationChannelsByUserID: %w", err)
+	}
+	if q.deleteOutboxEntriesByUserIDStmt, err = db.PrepareContext(ctx, deleteOutboxEntriesByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteOutboxEntriesByUserID: %w", err)
+	}
+	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
+	}
+	if q.deleteWebhookDeliveriesByUserIDStmt, err = db.PrepareContext(ctx, deleteWebhookDeliveriesByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebhookDeliveriesByUserID: %w", err)
+	}
+	if q.deleteWebhookEndpointByUserIDStmt, err = db.PrepareContext(ctx, deleteWebhookEndpointByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebhookEndpointByUserID: %w", err)
+	}
+	if q.getAllActiveUsersStmt, err = db.PrepareContext(ctx, getAllActiveUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAllActiveUsers: %w", err)
+	}
+	if q.getChannelLinkCodeStmt, err = db.PrepareContext(ctx, getChannelLinkCode); err != nil {
+		return nil, fmt.Errorf("error preparing query GetChannelLinkCode: %w", err)
+	}
+	if q.getDueOutboxEntriesStmt, err = db.PrepareContext(ctx, getDueOutboxEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDueOutboxEntries: %w", err)
+	}
+	if q.getDueWebhookDeliveriesStmt, err = db.PrepareContext(ctx, getDueWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDueWebhookDeliveries: %w", err)
+	}
+	if q.getEmailByGmailMessageIDStmt, err = db.PrepareContext(ctx, getEmailByGmailMessageID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetEmailByGmailMessageID: %w", err)
+	}
+	if q.getEmailsByUserIDStmt, err = db.PrepareContext(ctx, getEmailsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetEmailsByUserID: %w", err)
+	}
+	if q.getNextPendingJobStmt, err = db.PrepareContext(ctx, getNextPendingJob); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNextPendingJob: %w", err)
+	}
+	if q.getNotificationChannelByTargetStmt, err = db.PrepareContext(ctx, getNotificationChannelByTarget); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotificationChannelByTarget: %w", err)
+	}
+	if q.getOldestPendingJobRunAtStmt, err = db.PrepareContext(ctx, getOldestPendingJobRunAt); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOldestPendingJobRunAt: %w", err)
+	}
+	if q.getRecentEmailsStmt, err = db.PrepareContext(ctx, getRecentEmails); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRecentEmails: %w", err)
+	}
+	if q.getUnnotifiedEmailsByUserIDStmt, err = db.PrepareContext(ctx, getUnnotifiedEmailsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUnnotifiedEmailsByUserID: %w", err)
+	}
+	if q.getUserByIDStmt, err = db.PrepareContext(ctx, getUserByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByID: %w", err)
+	}
+	if q.getUserByLineUserIDStmt, err = db.PrepareContext(ctx, getUserByLineUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByLineUserID: %w", err)
+	}
+	if q.getUserByMailWatchIDStmt, err = db.PrepareContext(ctx, getUserByMailWatchID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByMailWatchID: %w", err)
+	}
+	if q.getUsersWithWatchExpiringBeforeStmt, err = db.PrepareContext(ctx, getUsersWithWatchExpiringBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUsersWithWatchExpiringBefore: %w", err)
+	}
+	if q.getWebhookDeliveryStmt, err = db.PrepareContext(ctx, getWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhookDelivery: %w", err)
+	}
+	if q.getWebhookEndpointStmt, err = db.PrepareContext(ctx, getWebhookEndpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhookEndpoint: %w", err)
+	}
+	if q.getWebhookEndpointByUserIDStmt, err = db.PrepareContext(ctx, getWebhookEndpointByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhookEndpointByUserID: %w", err)
+	}
+	if q.leaseOutboxEntryStmt, err = db.PrepareContext(ctx, leaseOutboxEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query LeaseOutboxEntry: %w", err)
+	}
+	if q.leaseWebhookDeliveryStmt, err = db.PrepareContext(ctx, leaseWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query LeaseWebhookDelivery: %w", err)
+	}
+	if q.listAuditEventsBySubjectStmt, err = db.PrepareContext(ctx, listAuditEventsBySubject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditEventsBySubject: %w", err)
+	}
+	if q.listJobsByStatusStmt, err = db.PrepareContext(ctx, listJobsByStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobsByStatus: %w", err)
+	}
+	if q.listNotificationChannelsByUserIDStmt, err = db.PrepareContext(ctx, listNotificationChannelsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query ListNotificationChannelsByUserID: %w", err)
+	}
+	if q.listRecentOutboxEntriesStmt, err = db.PrepareContext(ctx, listRecentOutboxEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRecentOutboxEntries: %w", err)
+	}
+	if q.listUsersStmt, err = db.PrepareContext(ctx, listUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsers: %w", err)
+	}
+	if q.listWebhookDeliveriesByUserIDStmt, err = db.PrepareContext(ctx, listWebhookDeliveriesByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhookDeliveriesByUserID: %w", err)
+	}
+	if q.markEmailAsNotifiedStmt, err = db.PrepareContext(ctx, markEmailAsNotified); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailAsNotified: %w", err)
+	}
+	if q.markEmailAsNotifiedByIDStmt, err = db.PrepareContext(ctx, markEmailAsNotifiedByID); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailAsNotifiedByID: %w", err)
+	}
+	if q.markJobDeadStmt, err = db.PrepareContext(ctx, markJobDead); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkJobDead: %w", err)
+	}
+	if q.markJobDoneStmt, err = db.PrepareContext(ctx, markJobDone); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkJobDone: %w", err)
+	}
+	if q.markJobRunningStmt, err = db.PrepareContext(ctx, markJobRunning); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkJobRunning: %w", err)
+	}
+	if q.markOutboxEntryFailedStmt, err = db.PrepareContext(ctx, markOutboxEntryFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxEntryFailed: %w", err)
+	}
+	if q.markOutboxEntrySentStmt, err = db.PrepareContext(ctx, markOutboxEntrySent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxEntrySent: %w", err)
+	}
+	if q.markWebhookDeliveryDeliveredStmt, err = db.PrepareContext(ctx, markWebhookDeliveryDelivered); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkWebhookDeliveryDelivered: %w", err)
+	}
+	if q.markWebhookDeliveryFailedStmt, err = db.PrepareContext(ctx, markWebhookDeliveryFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkWebhookDeliveryFailed: %w", err)
+	}
+	if q.replayDeadJobStmt, err = db.PrepareContext(ctx, replayDeadJob); err != nil {
+		return nil, fmt.Errorf("error preparing query ReplayDeadJob: %w", err)
+	}
+	if q.requeueStaleJobsStmt, err = db.PrepareContext(ctx, requeueStaleJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query RequeueStaleJobs: %w", err)
+	}
+	if q.rescheduleJobStmt, err = db.PrepareContext(ctx, rescheduleJob); err != nil {
+		return nil, fmt.Errorf("error preparing query RescheduleJob: %w", err)
+	}
+	if q.rescheduleOutboxEntryStmt, err = db.PrepareContext(ctx, rescheduleOutboxEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query RescheduleOutboxEntry: %w", err)
+	}
+	if q.rescheduleWebhookDeliveryStmt, err = db.PrepareContext(ctx, rescheduleWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query RescheduleWebhookDelivery: %w", err)
+	}
+	if q.searchEmailsStmt, err = db.PrepareContext(ctx, searchEmails); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchEmails: %w", err)
+	}
+	if q.updateEmailNotifiedStmt, err = db.PrepareContext(ctx, updateEmailNotified); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateEmailNotified: %w", err)
+	}
+	if q.updateUserEmailRetentionStmt, err = db.PrepareContext(ctx, updateUserEmailRetention); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserEmailRetention: %w", err)
+	}
+	if q.updateUserMailSyncCursorStmt, err = db.PrepareContext(ctx, updateUserMailSyncCursor); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserMailSyncCursor: %w", err)
+	}
+	if q.updateUserMailTokensStmt, err = db.PrepareContext(ctx, updateUserMailTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserMailTokens: %w", err)
+	}
+	if q.updateUserMailWatchStmt, err = db.PrepareContext(ctx, updateUserMailWatch); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserMailWatch: %w", err)
+	}
+	if q.updateUserRefreshedMailTokensStmt, err = db.PrepareContext(ctx, updateUserRefreshedMailTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserRefreshedMailTokens: %w", err)
+	}
+	if q.upsertNotificationChannelStmt, err = db.PrepareContext(ctx, upsertNotificationChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertNotificationChannel: %w", err)
+	}
+	if q.upsertWebhookEndpointStmt, err = db.PrepareContext(ctx, upsertWebhookEndpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertWebhookEndpoint: %w", err)
+	}
+	return &q, nil
+}
+
+func (q *Queries) Close() error {
+	var err error
+	if q.clearAuditEventClientInfoStmt != nil {
+		if cerr := q.clearAuditEventClientInfoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearAuditEventClientInfoStmt: %w", cerr)
+		}
+	}
+	if q.clearDeliveredWebhookPayloadsStmt != nil {
+		if cerr := q.clearDeliveredWebhookPayloadsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearDeliveredWebhookPayloadsStmt: %w", cerr)
+		}
+	}
+	if q.clearNotifiedEmailContentStmt != nil {
+		if cerr := q.clearNotifiedEmailContentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearNotifiedEmailContentStmt: %w", cerr)
+		}
+	}
+	if q.clearSentOutboxMessagesStmt != nil {
+		if cerr := q.clearSentOutboxMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearSentOutboxMessagesStmt: %w", cerr)
+		}
+	}
+	if q.countJobsByStatusStmt != nil {
+		if cerr := q.countJobsByStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countJobsByStatusStmt: %w", cerr)
+		}
+	}
+	if q.countOutboxEntriesByStatusStmt != nil {
+		if cerr := q.countOutboxEntriesByStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countOutboxEntriesByStatusStmt: %w", cerr)
+		}
+	}
+	if q.countUsersWithWatchExpiringBetweenStmt != nil {
+		if cerr := q.countUsersWithWatchExpiringBetweenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUsersWithWatchExpiringBetweenStmt: %w", cerr)
+		}
+	}
+	if q.createAuditEventStmt != nil {
+		if cerr := q.createAuditEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAuditEventStmt: %w", cerr)
+		}
+	}
+	if q.createChannelLinkCodeStmt != nil {
+		if cerr := q.createChannelLinkCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createChannelLinkCodeStmt: %w", cerr)
+		}
+	}
+	if q.createEmailStmt != nil {
+		if cerr := q.createEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createEmailStmt: %w", cerr)
+		}
+	}
+	if q.createJobStmt != nil {
+		if cerr := q.createJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createJobStmt: %w", cerr)
+		}
+	}
+	if q.createOutboxEntryStmt != nil {
+		if cerr := q.createOutboxEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createOutboxEntryStmt: %w", cerr)
+		}
+	}
+	if q.createUserStmt != nil {
+		if cerr := q.createUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
+		}
+	}
+	if q.createWebhookDeliveryStmt != nil {
+		if cerr := q.createWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.createWebhookEventStmt != nil {
+		if cerr := q.createWebhookEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookEventStmt: %w", cerr)
+		}
+	}
+	if q.deactivateUserStmt != nil {
+		if cerr := q.deactivateUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deactivateUserStmt: %w", cerr)
+		}
+	}
+	if q.deadLetterStaleJobsStmt != nil {
+		if cerr := q.deadLetterStaleJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deadLetterStaleJobsStmt: %w", cerr)
+		}
+	}
+	if q.deleteChannelLinkCodeStmt != nil {
+		if cerr := q.deleteChannelLinkCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteChannelLinkCodeStmt: %w", cerr)
+		}
+	}
+	if q.deleteChannelLinkCodesByUserIDStmt != nil {
+		if cerr := q.deleteChannelLinkCodesByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteChannelLinkCodesByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteDoneJobsBeforeStmt != nil {
+		if cerr := q.deleteDoneJobsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteDoneJobsBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteEmailsByUserIDStmt != nil {
+		if cerr := q.deleteEmailsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteEmailsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteEmailsOlderThanStmt != nil {
+		if cerr := q.deleteEmailsOlderThanStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteEmailsOlderThanStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredChannelLinkCodesStmt != nil {
+		if cerr := q.deleteExpiredChannelLinkCodesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredChannelLinkCodesStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredWebhookEventsStmt != nil {
+		if cerr := q.deleteExpiredWebhookEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredWebhookEventsStmt: %w", cerr)
+		}
+	}
+	if q.deleteFinishedOutboxEntriesStmt != nil {
+		if cerr := q.deleteFinishedOutboxEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFinishedOutboxEntriesStmt: %w", cerr)
+		}
+	}
+	if q.deleteFinishedWebhookDeliveriesStmt != nil {
+		if cerr := q.deleteFinishedWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFinishedWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.deleteJobsByUserIDStmt != nil {
+		if cerr := q.deleteJobsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteJobsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteNotificationChannelStmt != nil {
+		if cerr := q.deleteNotificationChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteNotificationChannelStmt: %w", cerr)
+		}
+	}
+	if q.deleteNotificationChannelsByUserIDStmt != nil {
+		if cerr := q.deleteNotificationChannelsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteNotificationChannelsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteOutboxEntriesByUserIDStmt != nil {
+		if cerr := q.deleteOutboxEntriesByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteOutboxEntriesByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserStmt != nil {
+		if cerr := q.deleteUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
+		}
+	}
+	if q.deleteWebhookDeliveriesByUserIDStmt != nil {
+		if cerr := q.deleteWebhookDeliveriesByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebhookDeliveriesByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteWebhookEndpointByUserIDStmt != nil {
+		if cerr := q.deleteWebhookEndpointByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebhookEndpointByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.getAllActiveUsersStmt != nil {
+		if cerr := q.getAllActiveUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAllActiveUsersStmt: %w", cerr)
+		}
+	}
+	if q.getChannelLinkCodeStmt != nil {
+		if cerr := q.getChannelLinkCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getChannelLinkCodeStmt: %w", cerr)
+		}
+	}
+	if q.getDueOutboxEntriesStmt != nil {
+		if cerr := q.getDueOutboxEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDueOutboxEntriesStmt: %w", cerr)
+		}
+	}
+	if q.getDueWebhookDeliveriesStmt != nil {
+		if cerr := q.getDueWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDueWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.getEmailByGmailMessageIDStmt != nil {
+		if cerr := q.getEmailByGmailMessageIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getEmailByGmailMessageIDStmt: %w", cerr)
+		}
+	}
+	if q.getEmailsByUserIDStmt != nil {
+		if cerr := q.getEmailsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getEmailsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.getNextPendingJobStmt != nil {
+		if cerr := q.getNextPendingJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNextPendingJobStmt: %w", cerr)
+		}
+	}
+	if q.getNotificationChannelByTargetStmt != nil {
+		if cerr := q.getNotificationChannelByTargetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotificationChannelByTargetStmt: %w", cerr)
+		}
+	}
+	if q.getOldestPendingJobRunAtStmt != nil {
+		if cerr := q.getOldestPendingJobRunAtStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOldestPendingJobRunAtStmt: %w", cerr)
+		}
+	}
+	if q.getRecentEmailsStmt != nil {
+		if cerr := q.getRecentEmailsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRecentEmailsStmt: %w", cerr)
+		}
+	}
+	if q.getUnnotifiedEmailsByUserIDStmt != nil {
+		if cerr := q.getUnnotifiedEmailsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUnnotifiedEmailsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.getUserByIDStmt != nil {
+		if cerr := q.getUserByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByIDStmt: %w", cerr)
+		}
+	}
+	if q.getUserByLineUserIDStmt != nil {
+		if cerr := q.getUserByLineUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByLineUserIDStmt: %w", cerr)
+		}
+	}
+	if q.getUserByMailWatchIDStmt != nil {
+		if cerr := q.getUserByMailWatchIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByMailWatchIDStmt: %w", cerr)
+		}
+	}
+	if q.getUsersWithWatchExpiringBeforeStmt != nil {
+		if cerr := q.getUsersWithWatchExpiringBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUsersWithWatchExpiringBeforeStmt: %w", cerr)
+		}
+	}
+	if q.getWebhookDeliveryStmt != nil {
+		if cerr := q.getWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.getWebhookEndpointStmt != nil {
+		if cerr := q.getWebhookEndpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookEndpointStmt: %w", cerr)
+		}
+	}
+	if q.getWebhookEndpointByUserIDStmt != nil {
+		if cerr := q.getWebhookEndpointByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookEndpointByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.leaseOutboxEntryStmt != nil {
+		if cerr := q.leaseOutboxEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing leaseOutboxEntryStmt: %w", cerr)
+		}
+	}
+	if q.leaseWebhookDeliveryStmt != nil {
+		if cerr := q.leaseWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing leaseWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.listAuditEventsBySubjectStmt != nil {
+		if cerr := q.listAuditEventsBySubjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditEventsBySubjectStmt: %w", cerr)
+		}
+	}
+	if q.listJobsByStatusStmt != nil {
+		if cerr := q.listJobsByStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobsByStatusStmt: %w", cerr)
+		}
+	}
+	if q.listNotificationChannelsByUserIDStmt != nil {
+		if cerr := q.listNotificationChannelsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listNotificationChannelsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.listRecentOutboxEntriesStmt != nil {
+		if cerr := q.listRecentOutboxEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRecentOutboxEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listUsersStmt != nil {
+		if cerr := q.listUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsersStmt: %w", cerr)
+		}
+	}
+	if q.listWebhookDeliveriesByUserIDStmt != nil {
+		if cerr := q.listWebhookDeliveriesByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhookDeliveriesByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.markEmailAsNotifiedStmt != nil {
+		if cerr := q.markEmailAsNotifiedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailAsNotifiedStmt: %w", cerr)
+		}
+	}
+	if q.markEmailAsNotifiedByIDStmt != nil {
+		if cerr := q.markEmailAsNotifiedByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailAsNotifiedByIDStmt: %w", cerr)
+		}
+	}
+	if q.markJobDeadStmt != nil {
+		if cerr := q.markJobDeadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markJobDeadStmt: %w", cerr)
+		}
+	}
+	if q.markJobDoneStmt != nil {
+		if cerr := q.markJobDoneStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markJobDoneStmt: %w", cerr)
+		}
+	}
+	if q.markJobRunningStmt != nil {
+		if cerr := q.markJobRunningStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markJobRunningStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxEntryFailedStmt != nil {
+		if cerr := q.markOutboxEntryFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxEntryFailedStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxEntrySentStmt != nil {
+		if cerr := q.markOutboxEntrySentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxEntrySentStmt: %w", cerr)
+		}
+	}
+	if q.markWebhookDeliveryDeliveredStmt != nil {
+		if cerr := q.markWebhookDeliveryDeliveredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markWebhookDeliveryDeliveredStmt: %w", cerr)
+		}
+	}
+	if q.markWebhookDeliveryFailedStmt != nil {
+		if cerr := q.markWebhookDeliveryFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markWebhookDeliveryFailedStmt: %w", cerr)
+		}
+	}
+	if q.replayDeadJobStmt != nil {
+		if cerr := q.replayDeadJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing replayDeadJobStmt: %w", cerr)
+		}
+	}
+	if q.requeueStaleJobsStmt != nil {
+		if cerr := q.requeueStaleJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requeueStaleJobsStmt: %w", cerr)
+		}
+	}
+	if q.rescheduleJobStmt != nil {
+		if cerr := q.rescheduleJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rescheduleJobStmt: %w", cerr)
+		}
+	}
+	if q.rescheduleOutboxEntryStmt != nil {
+		if cerr := q.rescheduleOutboxEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rescheduleOutboxEntryStmt: %w", cerr)
+		}
+	}
+	if q.rescheduleWebhookDeliveryStmt != nil {
+		if cerr := q.rescheduleWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rescheduleWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.searchEmailsStmt != nil {
+		if cerr := q.searchEmailsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchEmailsStmt: %w", cerr)
+		}
+	}
+	if q.updateEmailNotifiedStmt != nil {
+		if cerr := q.updateEmailNotifiedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateEmailNotifiedStmt: %w", cerr)
+		}
+	}
+	if q.updateUserEmailRetentionStmt != nil {
+		if cerr := q.updateUserEmailRetentionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserEmailRetentionStmt: %w", cerr)
+		}
+	}
+	if q.updateUserMailSyncCursorStmt != nil {
+		if cerr := q.updateUserMailSyncCursorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserMailSyncCursorStmt: %w", cerr)
+		}
+	}
+	if q.updateUserMailTokensStmt != nil {
+		if cerr := q.updateUserMailTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserMailTokensStmt: %w", cerr)
+		}
+	}
+	if q.updateUserMailWatchStmt != nil {
+		if cerr := q.updateUserMailWatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserMailWatchStmt: %w", cerr)
+		}
+	}
+	if q.updateUserRefreshedMailTokensStmt != nil {
+		if cerr := q.updateUserRefreshedMailTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserRefreshedMailTokensStmt: %w", cerr)
+		}
+	}
+	if q.upsertNotificationChannelStmt != nil {
+		if cerr := q.upsertNotificationChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertNotificationChannelStmt: %w", cerr)
+		}
+	}
+	if q.upsertWebhookEndpointStmt != nil {
+		if cerr := q.upsertWebhookEndpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertWebhookEndpointStmt: %w", cerr)
+		}
+	}
+	return err
+}
+
+func (q *Queries) exec(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (sql.Result, error) {
+	switch {
+	case stmt != nil && q.tx != nil:
+		return q.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+	case stmt != nil:
+		return stmt.ExecContext(ctx, args...)
+	default:
+		return q.db.ExecContext(ctx, query, args...)
+	}
+}
+
+func (q *Queries) query(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
+	switch {
+	case stmt != nil && q.tx != nil:
+		return q.tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+	case stmt != nil:
+		return stmt.QueryContext(ctx, args...)
+	default:
+		return q.db.QueryContext(ctx, query, args...)
+	}
+}
+
+func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) *sql.Row {
+	switch {
+	case stmt != nil && q.tx != nil:
+		return q.tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
+	case stmt != nil:
+		return stmt.QueryRowContext(ctx, args...)
+	default:
+		return q.db.QueryRowContext(ctx, query, args...)
+	}
+}
+
+type Queries struct {
+	db                                     DBTX
+	tx                                     *sql.Tx
+	clearAuditEventClientInfoStmt          *sql.Stmt
+	clearDeliveredWebhookPayloadsStmt      *sql.Stmt
+	clearNotifiedEmailContentStmt          *sql.Stmt
+	clearSentOutboxMessagesStmt            *sql.Stmt
+	countJobsByStatusStmt                  *sql.Stmt
+	countOutboxEntriesByStatusStmt         *sql.Stmt
+	countUsersWithWatchExpiringBetweenStmt *sql.Stmt
+	createAuditEventStmt                   *sql.Stmt
+	createChannelLinkCodeStmt              *sql.Stmt
+	createEmailStmt                        *sql.Stmt
+	createJobStmt                          *sql.Stmt
+	createOutboxEntryStmt                  *sql.Stmt
+	createUserStmt                         *sql.Stmt
+	createWebhookDeliveryStmt              *sql.Stmt
+	createWebhookEventStmt                 *sql.Stmt
+	deactivateUserStmt                     *sql.Stmt
+	deadLetterStaleJobsStmt                *sql.Stmt
+	deleteChannelLinkCodeStmt              *sql.Stmt
+	deleteChannelLinkCodesByUserIDStmt     *sql.Stmt
+	deleteDoneJobsBeforeStmt               *sql.Stmt
+	deleteEmailsByUserIDStmt               *sql.Stmt
+	deleteEmailsOlderThanStmt              *sql.Stmt
+	deleteExpiredChannelLinkCodesStmt      *sql.Stmt
+	deleteExpiredWebhookEventsStmt         *sql.Stmt
+	deleteFinishedOutboxEntriesStmt        *sql.Stmt
+	deleteFinishedWebhookDeliveriesStmt    *sql.Stmt
+	deleteJobsByUserIDStmt                 *sql.Stmt
+	deleteNotificationChannelStmt          *sql.Stmt
+	deleteNotificationChannelsByUserIDStmt *sql.Stmt
+	deleteOutboxEntriesByUserIDStmt        *sql.Stmt
+	deleteUserStmt                         *sql.Stmt
+	deleteWebhookDeliveriesByUserIDStmt    *sql.Stmt
+	deleteWebhookEndpointByUserIDStmt      *sql.Stmt
+	getAllActiveUsersStmt                  *sql.Stmt
+	getChannelLinkCodeStmt                 *sql.Stmt
+	getDueOutboxEntriesStmt                *sql.Stmt
+	getDueWebhookDeliveriesStmt            *sql.Stmt
+	getEmailByGmailMessageIDStmt           *sql.Stmt
+	getEmailsByUserIDStmt                  *sql.Stmt
+	getNextPendingJobStmt                  *sql.Stmt
+	getNotificationChannelByTargetStmt     *sql.Stmt
+	getOldestPendingJobRunAtStmt           *sql.Stmt
+	getRecentEmailsStmt                    *sql.Stmt
+	getUnnotifiedEmailsByUserIDStmt        *sql.Stmt
+	getUserByIDStmt                        *sql.Stmt
+	getUserByLineUserIDStmt                *sql.Stmt
+	getUserByMailWatchIDStmt               *sql.Stmt
+	getUsersWithWatchExpiringBeforeStmt    *sql.Stmt
+	getWebhookDeliveryStmt                 *sql.Stmt
+	getWebhookEndpointStmt                 *sql.Stmt
+	getWebhookEndpointByUserIDStmt         *sql.Stmt
+	leaseOutboxEntryStmt                   *sql.Stmt
+	leaseWebhookDeliveryStmt               *sql.Stmt
+	listAuditEventsBySubjectStmt           *sql.Stmt
+	listJobsByStatusStmt                   *sql.Stmt
+	listNotificationChannelsByUserIDStmt   *sql.Stmt
+	listRecentOutboxEntriesStmt            *sql.Stmt
+	listUsersStmt                          *sql.Stmt
+	listWebhookDeliveriesByUserIDStmt      *sql.Stmt
+	markEmailAsNotifiedStmt                *sql.Stmt
+	markEmailAsNotifiedByIDStmt            *sql.Stmt
+	markJobDeadStmt                        *sql.Stmt
+	markJobDoneStmt                        *sql.Stmt
+	markJobRunningStmt                     *sql.Stmt
+	markOutboxEntryFailedStmt              *sql.Stmt
+	markOutboxEntrySentStmt                *sql.Stmt
+	markWebhookDeliveryDeliveredStmt       *sql.Stmt
+	markWebhookDeliveryFailedStmt          *sql.Stmt
+	replayDeadJobStmt                      *sql.Stmt
+	requeueStaleJobsStmt                   *sql.Stmt
+	rescheduleJobStmt                      *sql.Stmt
+	rescheduleOutboxEntryStmt              *sql.Stmt
+	rescheduleWebhookDeliveryStmt          *sql.Stmt
+	searchEmailsStmt                       *sql.Stmt
+	updateEmailNotifiedStmt                *sql.Stmt
+	updateUserEmailRetentionStmt           *sql.Stmt
+	updateUserMailSyncCursorStmt           *sql.Stmt
+	updateUserMailTokensStmt               *sql.Stmt
+	updateUserMailWatchStmt                *sql.Stmt
+	updateUserRefreshedMailTokensStmt      *sql.Stmt
+	upsertNotificationChannelStmt          *sql.Stmt
+	upsertWebhookEndpointStmt              *sql.Stmt
+}
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db:                                     tx,
+		tx:                                     tx,
+		clearAuditEventClientInfoStmt:          q.clearAuditEventClientInfoStmt,
+		clearDeliveredWebhookPayloadsStmt:      q.clearDeliveredWebhookPayloadsStmt,
+		clearNotifiedEmailContentStmt:          q.clearNotifiedEmailContentStmt,
+		clearSentOutboxMessagesStmt:            q.clearSentOutboxMessagesStmt,
+		countJobsByStatusStmt:                  q.countJobsByStatusStmt,
+		countOutboxEntriesByStatusStmt:         q.countOutboxEntriesByStatusStmt,
+		countUsersWithWatchExpiringBetweenStmt: q.countUsersWithWatchExpiringBetweenStmt,
+		createAuditEventStmt:                   q.createAuditEventStmt,
+		createChannelLinkCodeStmt:              q.createChannelLinkCodeStmt,
+		createEmailStmt:                        q.createEmailStmt,
+		createJobStmt:                          q.createJobStmt,
+		createOutboxEntryStmt:                  q.createOutboxEntryStmt,
+		createUserStmt:                         q.createUserStmt,
+		createWebhookDeliveryStmt:              q.createWebhookDeliveryStmt,
+		createWebhookEventStmt:                 q.createWebhookEventStmt,
+		deactivateUserStmt:                     q.deactivateUserStmt,
+		deadLetterStaleJobsStmt:                q.deadLetterStaleJobsStmt,
+		deleteChannelLinkCodeStmt:              q.deleteChannelLinkCodeStmt,
+		deleteChannelLinkCodesByUserIDStmt:     q.deleteChannelLinkCodesByUserIDStmt,
+		deleteDoneJobsBeforeStmt:               q.deleteDoneJobsBeforeStmt,
+		deleteEmailsByUserIDStmt:               q.deleteEmailsByUserIDStmt,
+		deleteEmailsOlderThanStmt:              q.deleteEmailsOlderThanStmt,
+		deleteExpiredChannelLinkCodesStmt:      q.deleteExpiredChannelLinkCodesStmt,
+		deleteExpiredWebhookEventsStmt:         q.deleteExpiredWebhookEventsStmt,
+		deleteFinishedOutboxEntriesStmt:        q.deleteFinishedOutboxEntriesStmt,
+		deleteFinishedWebhookDeliveriesStmt:    q.deleteFinishedWebhookDeliveriesStmt,
+		deleteJobsByUserIDStmt:                 q.deleteJobsByUserIDStmt,
+		deleteNotificationChannelStmt:          q.deleteNotificationChannelStmt,
+		deleteNotificationChannelsByUserIDStmt: q.deleteNotificationChannelsByUserIDStmt,
+		deleteOutboxEntriesByUserIDStmt:        q.deleteOutboxEntriesByUserIDStmt,
+		deleteUserStmt:                         q.deleteUserStmt,
+		deleteWebhookDeliveriesByUserIDStmt:    q.deleteWebhookDeliveriesByUserIDStmt,
+		deleteWebhookEndpointByUserIDStmt:      q.deleteWebhookEndpointByUserIDStmt,
+		getAllActiveUsersStmt:                  q.getAllActiveUsersStmt,
+		getChannelLinkCodeStmt:                 q.getChannelLinkCodeStmt,
+		getDueOutboxEntriesStmt:                q.getDueOutboxEntriesStmt,
+		getDueWebhookDeliveriesStmt:            q.getDueWebhookDeliveriesStmt,
+		getEmailByGmailMessageIDStmt:           q.getEmailByGmailMessageIDStmt,
+		getEmailsByUserIDStmt:                  q.getEmailsByUserIDStmt,
+		getNextPendingJobStmt:                  q.getNextPendingJobStmt,
+		getNotificationChannelByTargetStmt:     q.getNotificationChannelByTargetStmt,
+		getOldestPendingJobRunAtStmt:           q.getOldestPendingJobRunAtStmt,
+		getRecentEmailsStmt:                    q.getRecentEmailsStmt,
+		getUnnotifiedEmailsByUserIDStmt:        q.getUnnotifiedEmailsByUserIDStmt,
+		getUserByIDStmt:                        q.getUserByIDStmt,
+		getUserByLineUserIDStmt:                q.getUserByLineUserIDStmt,
+		getUserByMailWatchIDStmt:               q.getUserByMailWatchIDStmt,
+		getUsersWithWatchExpiringBeforeStmt:    q.getUsersWithWatchExpiringBeforeStmt,
+		getWebhookDeliveryStmt:                 q.getWebhookDeliveryStmt,
+		getWebhookEndpointStmt:                 q.getWebhookEndpointStmt,
+		getWebhookEndpointByUserIDStmt:         q.getWebhookEndpointByUserIDStmt,
+		leaseOutboxEntryStmt:                   q.leaseOutboxEntryStmt,
+		leaseWebhookDeliveryStmt:               q.leaseWebhookDeliveryStmt,
+		listAuditEventsBySubjectStmt:           q.listAuditEventsBySubjectStmt,
+		listJobsByStatusStmt:                   q.listJobsByStatusStmt,
+		listNotificationChannelsByUserIDStmt:   q.listNotificationChannelsByUserIDStmt,
+		listRecentOutboxEntriesStmt:            q.listRecentOutboxEntriesStmt,
+		listUsersStmt:                          q.listUsersStmt,
+		listWebhookDeliveriesByUserIDStmt:      q.listWebhookDeliveriesByUserIDStmt,
+		markEmailAsNotifiedStmt:                q.markEmailAsNotifiedStmt,
+		markEmailAsNotifiedByIDStmt:            q.markEmailAsNotifiedByIDStmt,
+		markJobDeadStmt:                        q.markJobDeadStmt,
+		markJobDoneStmt:                        q.markJobDoneStmt,
+		markJobRunningStmt:                     q.markJobRunningStmt,
+		markOutboxEntryFailedStmt:              q.markOutboxEntryFailedStmt,
+		markOutboxEntrySentStmt:                q.markOutboxEntrySentStmt,
+		markWebhookDeliveryDeliveredStmt:       q.markWebhookDeliveryDeliveredStmt,
+		markWebhookDeliveryFailedStmt:          q.markWebhookDeliveryFailedStmt,
+		replayDeadJobStmt:                      q.replayDeadJobStmt,
+		requeueStaleJobsStmt:                   q.requeueStaleJobsStmt,
+		rescheduleJobStmt:                      q.rescheduleJobStmt,
+		rescheduleOutboxEntryStmt:              q.rescheduleOutboxEntryStmt,
+		rescheduleWebhookDeliveryStmt:          q.rescheduleWebhookDeliveryStmt,
+		searchEmailsStmt:                       q.searchEmailsStmt,
+		updateEmailNotifiedStmt:                q.updateEmailNotifiedStmt,
+		updateUserEmailRetentionStmt:           q.updateUserEmailRetentionStmt,
+		updateUserMailSyncCursorStmt:           q.updateUserMailSyncCursorStmt,
+		updateUserMailTokensStmt:               q.updateUserMailTokensStmt,
+		updateUserMailWatchStmt:                q.updateUserMailWatchStmt,
+		updateUserRefreshedMailTokensStmt:      q.updateUserRefreshedMailTokensStmt,
+		upsertNotificationChannelStmt:          q.upsertNotificationChannelStmt,
+		upsertWebhookEndpointStmt:              q.upsertWebhookEndpointStmt,
+	}
+}
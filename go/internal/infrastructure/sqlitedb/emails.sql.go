@@ -0,0 +1,398 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: emails.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const clearNotifiedEmailContent = `-- name: ClearNotifiedEmailContent :execrows
+UPDATE emails
+SET sender_email = '',
+    subject = NULL,
+    body_preview = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT e.id FROM emails e
+    WHERE e.is_notified = true
+      AND (e.sender_email <> '' OR e.subject IS NOT NULL OR e.body_preview IS NOT NULL)
+    LIMIT ?
+)
+`
+
+func (q *Queries) ClearNotifiedEmailContent(ctx context.Context, limit int64) (int64, error) {
+	result, err := q.exec(ctx, q.clearNotifiedEmailContentStmt, clearNotifiedEmailContent, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createEmail = `-- name: CreateEmail :execresult
+INSERT INTO emails (
+    user_id,
+    gmail_message_id,
+    sender_email,
+    subject,
+    body_preview,
+    received_at,
+    is_notified
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateEmailParams struct {
+	UserID         string         `db:"user_id" json:"user_id"`
+	GmailMessageID string         `db:"gmail_message_id" json:"gmail_message_id"`
+	SenderEmail    string         `db:"sender_email" json:"sender_email"`
+	Subject        sql.NullString `db:"subject" json:"subject"`
+	BodyPreview    sql.NullString `db:"body_preview" json:"body_preview"`
+	ReceivedAt     time.Time      `db:"received_at" json:"received_at"`
+	IsNotified     bool           `db:"is_notified" json:"is_notified"`
+}
+
+func (q *Queries) CreateEmail(ctx context.Context, arg CreateEmailParams) (sql.Result, error) {
+	return q.exec(ctx, q.createEmailStmt, createEmail,
+		arg.UserID,
+		arg.GmailMessageID,
+		arg.SenderEmail,
+		arg.Subject,
+		arg.BodyPreview,
+		arg.ReceivedAt,
+		arg.IsNotified,
+	)
+}
+
+const deleteEmailsByUserID = `-- name: DeleteEmailsByUserID :exec
+DELETE FROM emails
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteEmailsByUserID(ctx context.Context, userID string) error {
+	_, err := q.exec(ctx, q.deleteEmailsByUserIDStmt, deleteEmailsByUserID, userID)
+	return err
+}
+
+const deleteEmailsOlderThan = `-- name: DeleteEmailsOlderThan :execrows
+DELETE FROM emails
+WHERE id IN (
+    SELECT e.id FROM emails e
+    WHERE e.user_id = ? AND e.received_at < ?
+    ORDER BY e.received_at
+    LIMIT ?
+)
+`
+
+type DeleteEmailsOlderThanParams struct {
+	UserID     string    `db:"user_id" json:"user_id"`
+	ReceivedAt time.Time `db:"received_at" json:"received_at"`
+	Limit      int64     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) DeleteEmailsOlderThan(ctx context.Context, arg DeleteEmailsOlderThanParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteEmailsOlderThanStmt, deleteEmailsOlderThan, arg.UserID, arg.ReceivedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getEmailByGmailMessageID = `-- name: GetEmailByGmailMessageID :one
+SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
+WHERE gmail_message_id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetEmailByGmailMessageID(ctx context.Context, gmailMessageID string) (Email, error) {
+	row := q.queryRow(ctx, q.getEmailByGmailMessageIDStmt, getEmailByGmailMessageID, gmailMessageID)
+	var i Email
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.GmailMessageID,
+		&i.SenderEmail,
+		&i.Subject,
+		&i.BodyPreview,
+		&i.ReceivedAt,
+		&i.IsNotified,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEmailsByUserID = `-- name: GetEmailsByUserID :many
+SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
+WHERE user_id = ?1
+  AND (CAST(?2 AS INTEGER) = 0
+       OR received_at < ?3
+       OR (received_at = ?3 AND id < ?2))
+ORDER BY received_at DESC, id DESC
+LIMIT ?4
+`
+
+type GetEmailsByUserIDParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	CursorID         int64     `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	RowLimit         int64     `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) GetEmailsByUserID(ctx context.Context, arg GetEmailsByUserIDParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.getEmailsByUserIDStmt, getEmailsByUserID,
+		arg.UserID,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Email{}
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.GmailMessageID,
+			&i.SenderEmail,
+			&i.Subject,
+			&i.BodyPreview,
+			&i.ReceivedAt,
+			&i.IsNotified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentEmails = `-- name: GetRecentEmails :many
+SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
+WHERE user_id = ?1 AND received_at >= ?2
+  AND (CAST(?3 AS INTEGER) = 0
+       OR received_at < ?4
+       OR (received_at = ?4 AND id < ?3))
+ORDER BY received_at DESC, id DESC
+LIMIT ?5
+`
+
+type GetRecentEmailsParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	Since            time.Time `db:"since" json:"since"`
+	CursorID         int64     `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	RowLimit         int64     `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) GetRecentEmails(ctx context.Context, arg GetRecentEmailsParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.getRecentEmailsStmt, getRecentEmails,
+		arg.UserID,
+		arg.Since,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Email{}
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.GmailMessageID,
+			&i.SenderEmail,
+			&i.Subject,
+			&i.BodyPreview,
+			&i.ReceivedAt,
+			&i.IsNotified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnnotifiedEmailsByUserID = `-- name: GetUnnotifiedEmailsByUserID :many
+SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
+WHERE user_id = ?1 AND is_notified = false
+  AND (CAST(?2 AS INTEGER) = 0
+       OR received_at < ?3
+       OR (received_at = ?3 AND id < ?2))
+ORDER BY received_at DESC, id DESC
+LIMIT ?4
+`
+
+type GetUnnotifiedEmailsByUserIDParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	CursorID         int64     `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	RowLimit         int64     `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) GetUnnotifiedEmailsByUserID(ctx context.Context, arg GetUnnotifiedEmailsByUserIDParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.getUnnotifiedEmailsByUserIDStmt, getUnnotifiedEmailsByUserID,
+		arg.UserID,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Email{}
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.GmailMessageID,
+			&i.SenderEmail,
+			&i.Subject,
+			&i.BodyPreview,
+			&i.ReceivedAt,
+			&i.IsNotified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailAsNotified = `-- name: MarkEmailAsNotified :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE gmail_message_id = ?
+`
+
+func (q *Queries) MarkEmailAsNotified(ctx context.Context, gmailMessageID string) error {
+	_, err := q.exec(ctx, q.markEmailAsNotifiedStmt, markEmailAsNotified, gmailMessageID)
+	return err
+}
+
+const markEmailAsNotifiedByID = `-- name: MarkEmailAsNotifiedByID :exec
+UPDATE emails
+SET is_notified = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) MarkEmailAsNotifiedByID(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.markEmailAsNotifiedByIDStmt, markEmailAsNotifiedByID, id)
+	return err
+}
+
+const searchEmails = `-- name: SearchEmails :many
+SELECT id, user_id, gmail_message_id, sender_email, subject, body_preview, received_at, is_notified, created_at, updated_at FROM emails
+WHERE user_id = ?1
+  AND instr(lower(sender_email || ' ' || COALESCE(subject, '') || ' ' || COALESCE(body_preview, '')), lower(CAST(?2 AS TEXT))) > 0
+  AND (CAST(?3 AS INTEGER) = 0
+       OR received_at < ?4
+       OR (received_at = ?4 AND id < ?3))
+ORDER BY received_at DESC, id DESC
+LIMIT ?5
+`
+
+type SearchEmailsParams struct {
+	UserID           string    `db:"user_id" json:"user_id"`
+	Query            string    `db:"query" json:"query"`
+	CursorID         int64     `db:"cursor_id" json:"cursor_id"`
+	CursorReceivedAt time.Time `db:"cursor_received_at" json:"cursor_received_at"`
+	RowLimit         int64     `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) SearchEmails(ctx context.Context, arg SearchEmailsParams) ([]Email, error) {
+	rows, err := q.query(ctx, q.searchEmailsStmt, searchEmails,
+		arg.UserID,
+		arg.Query,
+		arg.CursorID,
+		arg.CursorReceivedAt,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Email{}
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.GmailMessageID,
+			&i.SenderEmail,
+			&i.Subject,
+			&i.BodyPreview,
+			&i.ReceivedAt,
+			&i.IsNotified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateEmailNotified = `-- name: UpdateEmailNotified :exec
+UPDATE emails
+SET is_notified = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateEmailNotifiedParams struct {
+	IsNotified bool  `db:"is_notified" json:"is_notified"`
+	ID         int64 `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateEmailNotified(ctx context.Context, arg UpdateEmailNotifiedParams) error {
+	_, err := q.exec(ctx, q.updateEmailNotifiedStmt, updateEmailNotified, arg.IsNotified, arg.ID)
+	return err
+}
@@ -0,0 +1,311 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notification_outbox.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const clearSentOutboxMessages = `-- name: ClearSentOutboxMessages :execrows
+UPDATE notification_outbox
+SET message = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT o.id FROM notification_outbox o
+    WHERE o.status = 'sent' AND o.message <> ''
+    LIMIT ?
+)
+`
+
+func (q *Queries) ClearSentOutboxMessages(ctx context.Context, limit int64) (int64, error) {
+	result, err := q.exec(ctx, q.clearSentOutboxMessagesStmt, clearSentOutboxMessages, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countOutboxEntriesByStatus = `-- name: CountOutboxEntriesByStatus :many
+SELECT status, COUNT(*) AS count FROM notification_outbox
+GROUP BY status
+`
+
+type CountOutboxEntriesByStatusRow struct {
+	Status string `db:"status" json:"status"`
+	Count  int64  `db:"count" json:"count"`
+}
+
+func (q *Queries) CountOutboxEntriesByStatus(ctx context.Context) ([]CountOutboxEntriesByStatusRow, error) {
+	rows, err := q.query(ctx, q.countOutboxEntriesByStatusStmt, countOutboxEntriesByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountOutboxEntriesByStatusRow{}
+	for rows.Next() {
+		var i CountOutboxEntriesByStatusRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createOutboxEntry = `-- name: CreateOutboxEntry :execresult
+INSERT OR IGNORE INTO notification_outbox (
+    idempotency_key,
+    retry_key,
+    user_id,
+    email_id,
+    channel,
+    recipient,
+    message,
+    trace_parent
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateOutboxEntryParams struct {
+	IdempotencyKey string         `db:"idempotency_key" json:"idempotency_key"`
+	RetryKey       string         `db:"retry_key" json:"retry_key"`
+	UserID         string         `db:"user_id" json:"user_id"`
+	EmailID        sql.NullInt64  `db:"email_id" json:"email_id"`
+	Channel        string         `db:"channel" json:"channel"`
+	Recipient      string         `db:"recipient" json:"recipient"`
+	Message        string         `db:"message" json:"message"`
+	TraceParent    sql.NullString `db:"trace_parent" json:"trace_parent"`
+}
+
+func (q *Queries) CreateOutboxEntry(ctx context.Context, arg CreateOutboxEntryParams) (sql.Result, error) {
+	return q.exec(ctx, q.createOutboxEntryStmt, createOutboxEntry,
+		arg.IdempotencyKey,
+		arg.RetryKey,
+		arg.UserID,
+		arg.EmailID,
+		arg.Channel,
+		arg.Recipient,
+		arg.Message,
+		arg.TraceParent,
+	)
+}
+
+const deleteFinishedOutboxEntries = `-- name: DeleteFinishedOutboxEntries :execrows
+DELETE FROM notification_outbox
+WHERE id IN (
+    SELECT o.id FROM notification_outbox o
+    WHERE o.user_id = ? AND o.status IN ('sent', 'failed') AND o.created_at < ?
+    ORDER BY o.created_at
+    LIMIT ?
+)
+`
+
+type DeleteFinishedOutboxEntriesParams struct {
+	UserID    string    `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Limit     int64     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) DeleteFinishedOutboxEntries(ctx context.Context, arg DeleteFinishedOutboxEntriesParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteFinishedOutboxEntriesStmt, deleteFinishedOutboxEntries, arg.UserID, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOutboxEntriesByUserID = `-- name: DeleteOutboxEntriesByUserID :exec
+DELETE FROM notification_outbox
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteOutboxEntriesByUserID(ctx context.Context, userID string) error {
+	_, err := q.exec(ctx, q.deleteOutboxEntriesByUserIDStmt, deleteOutboxEntriesByUserID, userID)
+	return err
+}
+
+const getDueOutboxEntries = `-- name: GetDueOutboxEntries :many
+SELECT id, idempotency_key, retry_key, user_id, email_id, channel, recipient, message, status, attempts, last_error, next_attempt_at, sent_at, trace_parent, created_at, updated_at FROM notification_outbox
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at, id
+LIMIT ?
+`
+
+type GetDueOutboxEntriesParams struct {
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	Limit         int64     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) GetDueOutboxEntries(ctx context.Context, arg GetDueOutboxEntriesParams) ([]NotificationOutbox, error) {
+	rows, err := q.query(ctx, q.getDueOutboxEntriesStmt, getDueOutboxEntries, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationOutbox{}
+	for rows.Next() {
+		var i NotificationOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.RetryKey,
+			&i.UserID,
+			&i.EmailID,
+			&i.Channel,
+			&i.Recipient,
+			&i.Message,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.SentAt,
+			&i.TraceParent,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const leaseOutboxEntry = `-- name: LeaseOutboxEntry :exec
+UPDATE notification_outbox
+SET attempts = attempts + 1,
+    next_attempt_at = ?
+WHERE id = ?
+`
+
+type LeaseOutboxEntryParams struct {
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	ID            int64     `db:"id" json:"id"`
+}
+
+func (q *Queries) LeaseOutboxEntry(ctx context.Context, arg LeaseOutboxEntryParams) error {
+	_, err := q.exec(ctx, q.leaseOutboxEntryStmt, leaseOutboxEntry, arg.NextAttemptAt, arg.ID)
+	return err
+}
+
+const listRecentOutboxEntries = `-- name: ListRecentOutboxEntries :many
+SELECT id, idempotency_key, retry_key, user_id, email_id, channel, recipient, message, status, attempts, last_error, next_attempt_at, sent_at, trace_parent, created_at, updated_at FROM notification_outbox
+WHERE CAST(?1 AS TEXT) = '' OR status = ?1
+ORDER BY created_at DESC, id DESC
+LIMIT ?2
+`
+
+type ListRecentOutboxEntriesParams struct {
+	Status string `db:"status" json:"status"`
+	Limit  int64  `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListRecentOutboxEntries(ctx context.Context, arg ListRecentOutboxEntriesParams) ([]NotificationOutbox, error) {
+	rows, err := q.query(ctx, q.listRecentOutboxEntriesStmt, listRecentOutboxEntries, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationOutbox{}
+	for rows.Next() {
+		var i NotificationOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.RetryKey,
+			&i.UserID,
+			&i.EmailID,
+			&i.Channel,
+			&i.Recipient,
+			&i.Message,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.SentAt,
+			&i.TraceParent,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxEntryFailed = `-- name: MarkOutboxEntryFailed :exec
+UPDATE notification_outbox
+SET status = 'failed',
+    last_error = ?
+WHERE id = ?
+`
+
+type MarkOutboxEntryFailedParams struct {
+	LastError sql.NullString `db:"last_error" json:"last_error"`
+	ID        int64          `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkOutboxEntryFailed(ctx context.Context, arg MarkOutboxEntryFailedParams) error {
+	_, err := q.exec(ctx, q.markOutboxEntryFailedStmt, markOutboxEntryFailed, arg.LastError, arg.ID)
+	return err
+}
+
+const markOutboxEntrySent = `-- name: MarkOutboxEntrySent :exec
+UPDATE notification_outbox
+SET status = 'sent',
+    sent_at = ?,
+    last_error = NULL
+WHERE id = ?
+`
+
+type MarkOutboxEntrySentParams struct {
+	SentAt sql.NullTime `db:"sent_at" json:"sent_at"`
+	ID     int64        `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkOutboxEntrySent(ctx context.Context, arg MarkOutboxEntrySentParams) error {
+	_, err := q.exec(ctx, q.markOutboxEntrySentStmt, markOutboxEntrySent, arg.SentAt, arg.ID)
+	return err
+}
+
+const rescheduleOutboxEntry = `-- name: RescheduleOutboxEntry :exec
+UPDATE notification_outbox
+SET next_attempt_at = ?,
+    last_error = ?
+WHERE id = ?
+`
+
+type RescheduleOutboxEntryParams struct {
+	NextAttemptAt time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     sql.NullString `db:"last_error" json:"last_error"`
+	ID            int64          `db:"id" json:"id"`
+}
+
+func (q *Queries) RescheduleOutboxEntry(ctx context.Context, arg RescheduleOutboxEntryParams) error {
+	_, err := q.exec(ctx, q.rescheduleOutboxEntryStmt, rescheduleOutboxEntry, arg.NextAttemptAt, arg.LastError, arg.ID)
+	return err
+}
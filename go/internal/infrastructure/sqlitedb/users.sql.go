@@ -0,0 +1,451 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: users.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const countUsersWithWatchExpiringBetween = `-- name: CountUsersWithWatchExpiringBetween :one
+SELECT COUNT(*) FROM users
+WHERE is_active = true
+  AND mail_watch_expires_at >= ?1
+  AND mail_watch_expires_at < ?2
+`
+
+type CountUsersWithWatchExpiringBetweenParams struct {
+	FromUnix sql.NullInt64 `db:"from_unix" json:"from_unix"`
+	ToUnix   sql.NullInt64 `db:"to_unix" json:"to_unix"`
+}
+
+func (q *Queries) CountUsersWithWatchExpiringBetween(ctx context.Context, arg CountUsersWithWatchExpiringBetweenParams) (int64, error) {
+	row := q.queryRow(ctx, q.countUsersWithWatchExpiringBetweenStmt, countUsersWithWatchExpiringBetween, arg.FromUnix, arg.ToUnix)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createUser = `-- name: CreateUser :execresult
+INSERT INTO users (
+    id,
+    line_user_id,
+    mail_access_token,
+    mail_refresh_token,
+    mail_token_expires_at,
+    is_active
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateUserParams struct {
+	ID                 string         `db:"id" json:"id"`
+	LineUserID         string         `db:"line_user_id" json:"line_user_id"`
+	MailAccessToken    sql.NullString `db:"mail_access_token" json:"mail_access_token"`
+	MailRefreshToken   sql.NullString `db:"mail_refresh_token" json:"mail_refresh_token"`
+	MailTokenExpiresAt sql.NullInt64  `db:"mail_token_expires_at" json:"mail_token_expires_at"`
+	IsActive           bool           `db:"is_active" json:"is_active"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error) {
+	return q.exec(ctx, q.createUserStmt, createUser,
+		arg.ID,
+		arg.LineUserID,
+		arg.MailAccessToken,
+		arg.MailRefreshToken,
+		arg.MailTokenExpiresAt,
+		arg.IsActive,
+	)
+}
+
+const deactivateUser = `-- name: DeactivateUser :execrows
+UPDATE users
+SET is_active = false,
+    mail_access_token = NULL,
+    mail_refresh_token = NULL,
+    mail_token_expires_at = NULL,
+    mail_watch_expires_at = NULL,
+    mail_watch_id = NULL,
+    mail_sync_cursor = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id string) (int64, error) {
+	result, err := q.exec(ctx, q.deactivateUserStmt, deactivateUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = ?
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id string) (int64, error) {
+	result, err := q.exec(ctx, q.deleteUserStmt, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAllActiveUsers = `-- name: GetAllActiveUsers :many
+SELECT id, line_user_id, mail_access_token, mail_refresh_token, mail_token_expires_at, gmail_history_id, mail_watch_expires_at, is_active, created_at, updated_at, email_retention_days, mail_provider, mail_watch_id, mail_sync_cursor FROM users
+WHERE is_active = true
+`
+
+func (q *Queries) GetAllActiveUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.query(ctx, q.getAllActiveUsersStmt, getAllActiveUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.LineUserID,
+			&i.MailAccessToken,
+			&i.MailRefreshToken,
+			&i.MailTokenExpiresAt,
+			&i.GmailHistoryID,
+			&i.MailWatchExpiresAt,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailRetentionDays,
+			&i.MailProvider,
+			&i.MailWatchID,
+			&i.MailSyncCursor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, line_user_id, mail_access_token, mail_refresh_token, mail_token_expires_at, gmail_history_id, mail_watch_expires_at, is_active, created_at, updated_at, email_retention_days, mail_provider, mail_watch_id, mail_sync_cursor FROM users
+WHERE id = ? AND is_active = true
+LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id string) (User, error) {
+	row := q.queryRow(ctx, q.getUserByIDStmt, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.LineUserID,
+		&i.MailAccessToken,
+		&i.MailRefreshToken,
+		&i.MailTokenExpiresAt,
+		&i.GmailHistoryID,
+		&i.MailWatchExpiresAt,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailRetentionDays,
+		&i.MailProvider,
+		&i.MailWatchID,
+		&i.MailSyncCursor,
+	)
+	return i, err
+}
+
+const getUserByLineUserID = `-- name: GetUserByLineUserID :one
+SELECT id, line_user_id, mail_access_token, mail_refresh_token, mail_token_expires_at, gmail_history_id, mail_watch_expires_at, is_active, created_at, updated_at, email_retention_days, mail_provider, mail_watch_id, mail_sync_cursor FROM users
+WHERE line_user_id = ? AND is_active = true
+LIMIT 1
+`
+
+func (q *Queries) GetUserByLineUserID(ctx context.Context, lineUserID string) (User, error) {
+	row := q.queryRow(ctx, q.getUserByLineUserIDStmt, getUserByLineUserID, lineUserID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.LineUserID,
+		&i.MailAccessToken,
+		&i.MailRefreshToken,
+		&i.MailTokenExpiresAt,
+		&i.GmailHistoryID,
+		&i.MailWatchExpiresAt,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailRetentionDays,
+		&i.MailProvider,
+		&i.MailWatchID,
+		&i.MailSyncCursor,
+	)
+	return i, err
+}
+
+const getUserByMailWatchID = `-- name: GetUserByMailWatchID :one
+SELECT id, line_user_id, mail_access_token, mail_refresh_token, mail_token_expires_at, gmail_history_id, mail_watch_expires_at, is_active, created_at, updated_at, email_retention_days, mail_provider, mail_watch_id, mail_sync_cursor FROM users
+WHERE mail_watch_id = ? AND is_active = true
+LIMIT 1
+`
+
+func (q *Queries) GetUserByMailWatchID(ctx context.Context, mailWatchID sql.NullString) (User, error) {
+	row := q.queryRow(ctx, q.getUserByMailWatchIDStmt, getUserByMailWatchID, mailWatchID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.LineUserID,
+		&i.MailAccessToken,
+		&i.MailRefreshToken,
+		&i.MailTokenExpiresAt,
+		&i.GmailHistoryID,
+		&i.MailWatchExpiresAt,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailRetentionDays,
+		&i.MailProvider,
+		&i.MailWatchID,
+		&i.MailSyncCursor,
+	)
+	return i, err
+}
+
+const getUsersWithWatchExpiringBefore = `-- name: GetUsersWithWatchExpiringBefore :many
+SELECT id, line_user_id, mail_access_token, mail_refresh_token, mail_token_expires_at, gmail_history_id, mail_watch_expires_at, is_active, created_at, updated_at, email_retention_days, mail_provider, mail_watch_id, mail_sync_cursor FROM users
+WHERE is_active = true
+  AND mail_access_token IS NOT NULL AND mail_access_token <> ''
+  AND (mail_watch_expires_at IS NULL OR mail_watch_expires_at < ?1)
+ORDER BY id
+`
+
+func (q *Queries) GetUsersWithWatchExpiringBefore(ctx context.Context, beforeUnix sql.NullInt64) ([]User, error) {
+	rows, err := q.query(ctx, q.getUsersWithWatchExpiringBeforeStmt, getUsersWithWatchExpiringBefore, beforeUnix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.LineUserID,
+			&i.MailAccessToken,
+			&i.MailRefreshToken,
+			&i.MailTokenExpiresAt,
+			&i.GmailHistoryID,
+			&i.MailWatchExpiresAt,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailRetentionDays,
+			&i.MailProvider,
+			&i.MailWatchID,
+			&i.MailSyncCursor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, line_user_id, mail_access_token, mail_refresh_token, mail_token_expires_at, gmail_history_id, mail_watch_expires_at, is_active, created_at, updated_at, email_retention_days, mail_provider, mail_watch_id, mail_sync_cursor FROM users
+WHERE CAST(?1 AS TEXT) = ''
+   OR id = ?1
+   OR line_user_id LIKE ?2
+ORDER BY created_at DESC, id
+LIMIT ?4 OFFSET ?3
+`
+
+type ListUsersParams struct {
+	Query   string `db:"query" json:"query"`
+	Pattern string `db:"pattern" json:"pattern"`
+	Offset  int64  `db:"offset" json:"offset"`
+	Limit   int64  `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.query(ctx, q.listUsersStmt, listUsers,
+		arg.Query,
+		arg.Pattern,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.LineUserID,
+			&i.MailAccessToken,
+			&i.MailRefreshToken,
+			&i.MailTokenExpiresAt,
+			&i.GmailHistoryID,
+			&i.MailWatchExpiresAt,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailRetentionDays,
+			&i.MailProvider,
+			&i.MailWatchID,
+			&i.MailSyncCursor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserEmailRetention = `-- name: UpdateUserEmailRetention :exec
+UPDATE users
+SET email_retention_days = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = ?
+`
+
+type UpdateUserEmailRetentionParams struct {
+	EmailRetentionDays sql.NullInt64 `db:"email_retention_days" json:"email_retention_days"`
+	LineUserID         string        `db:"line_user_id" json:"line_user_id"`
+}
+
+func (q *Queries) UpdateUserEmailRetention(ctx context.Context, arg UpdateUserEmailRetentionParams) error {
+	_, err := q.exec(ctx, q.updateUserEmailRetentionStmt, updateUserEmailRetention, arg.EmailRetentionDays, arg.LineUserID)
+	return err
+}
+
+const updateUserMailSyncCursor = `-- name: UpdateUserMailSyncCursor :exec
+UPDATE users
+SET mail_sync_cursor = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateUserMailSyncCursorParams struct {
+	MailSyncCursor sql.NullString `db:"mail_sync_cursor" json:"mail_sync_cursor"`
+	ID             string         `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserMailSyncCursor(ctx context.Context, arg UpdateUserMailSyncCursorParams) error {
+	_, err := q.exec(ctx, q.updateUserMailSyncCursorStmt, updateUserMailSyncCursor, arg.MailSyncCursor, arg.ID)
+	return err
+}
+
+const updateUserMailTokens = `-- name: UpdateUserMailTokens :exec
+UPDATE users
+SET mail_provider = ?,
+    mail_access_token = ?,
+    mail_refresh_token = ?,
+    mail_token_expires_at = ?,
+    mail_watch_id = NULL,
+    mail_sync_cursor = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = ?
+`
+
+type UpdateUserMailTokensParams struct {
+	MailProvider       string         `db:"mail_provider" json:"mail_provider"`
+	MailAccessToken    sql.NullString `db:"mail_access_token" json:"mail_access_token"`
+	MailRefreshToken   sql.NullString `db:"mail_refresh_token" json:"mail_refresh_token"`
+	MailTokenExpiresAt sql.NullInt64  `db:"mail_token_expires_at" json:"mail_token_expires_at"`
+	LineUserID         string         `db:"line_user_id" json:"line_user_id"`
+}
+
+func (q *Queries) UpdateUserMailTokens(ctx context.Context, arg UpdateUserMailTokensParams) error {
+	_, err := q.exec(ctx, q.updateUserMailTokensStmt, updateUserMailTokens,
+		arg.MailProvider,
+		arg.MailAccessToken,
+		arg.MailRefreshToken,
+		arg.MailTokenExpiresAt,
+		arg.LineUserID,
+	)
+	return err
+}
+
+const updateUserMailWatch = `-- name: UpdateUserMailWatch :exec
+UPDATE users
+SET gmail_history_id = ?,
+    mail_watch_expires_at = ?,
+    mail_watch_id = ?,
+    mail_sync_cursor = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE line_user_id = ?
+`
+
+type UpdateUserMailWatchParams struct {
+	GmailHistoryID     sql.NullInt64  `db:"gmail_history_id" json:"gmail_history_id"`
+	MailWatchExpiresAt sql.NullInt64  `db:"mail_watch_expires_at" json:"mail_watch_expires_at"`
+	MailWatchID        sql.NullString `db:"mail_watch_id" json:"mail_watch_id"`
+	MailSyncCursor     sql.NullString `db:"mail_sync_cursor" json:"mail_sync_cursor"`
+	LineUserID         string         `db:"line_user_id" json:"line_user_id"`
+}
+
+func (q *Queries) UpdateUserMailWatch(ctx context.Context, arg UpdateUserMailWatchParams) error {
+	_, err := q.exec(ctx, q.updateUserMailWatchStmt, updateUserMailWatch,
+		arg.GmailHistoryID,
+		arg.MailWatchExpiresAt,
+		arg.MailWatchID,
+		arg.MailSyncCursor,
+		arg.LineUserID,
+	)
+	return err
+}
+
+const updateUserRefreshedMailTokens = `-- name: UpdateUserRefreshedMailTokens :exec
+UPDATE users
+SET mail_access_token = ?,
+    mail_refresh_token = ?,
+    mail_token_expires_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND mail_provider = ?
+`
+
+type UpdateUserRefreshedMailTokensParams struct {
+	MailAccessToken    sql.NullString `db:"mail_access_token" json:"mail_access_token"`
+	MailRefreshToken   sql.NullString `db:"mail_refresh_token" json:"mail_refresh_token"`
+	MailTokenExpiresAt sql.NullInt64  `db:"mail_token_expires_at" json:"mail_token_expires_at"`
+	ID                 string         `db:"id" json:"id"`
+	MailProvider       string         `db:"mail_provider" json:"mail_provider"`
+}
+
+func (q *Queries) UpdateUserRefreshedMailTokens(ctx context.Context, arg UpdateUserRefreshedMailTokensParams) error {
+	_, err := q.exec(ctx, q.updateUserRefreshedMailTokensStmt, updateUserRefreshedMailTokens,
+		arg.MailAccessToken,
+		arg.MailRefreshToken,
+		arg.MailTokenExpiresAt,
+		arg.ID,
+		arg.MailProvider,
+	)
+	return err
+}
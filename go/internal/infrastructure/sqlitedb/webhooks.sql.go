@@ -0,0 +1,401 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhooks.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const clearDeliveredWebhookPayloads = `-- name: ClearDeliveredWebhookPayloads :execrows
+UPDATE webhook_deliveries
+SET payload = '',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.status = 'delivered' AND d.payload <> ''
+    LIMIT ?
+)
+`
+
+func (q *Queries) ClearDeliveredWebhookPayloads(ctx context.Context, limit int64) (int64, error) {
+	result, err := q.exec(ctx, q.clearDeliveredWebhookPayloadsStmt, clearDeliveredWebhookPayloads, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :execresult
+INSERT INTO webhook_deliveries (
+    endpoint_id,
+    user_id,
+    event_id,
+    event_type,
+    payload,
+    redelivery_of
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateWebhookDeliveryParams struct {
+	EndpointID   int64         `db:"endpoint_id" json:"endpoint_id"`
+	UserID       string        `db:"user_id" json:"user_id"`
+	EventID      string        `db:"event_id" json:"event_id"`
+	EventType    string        `db:"event_type" json:"event_type"`
+	Payload      string        `db:"payload" json:"payload"`
+	RedeliveryOf sql.NullInt64 `db:"redelivery_of" json:"redelivery_of"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (sql.Result, error) {
+	return q.exec(ctx, q.createWebhookDeliveryStmt, createWebhookDelivery,
+		arg.EndpointID,
+		arg.UserID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.RedeliveryOf,
+	)
+}
+
+const deleteFinishedWebhookDeliveries = `-- name: DeleteFinishedWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.user_id = ? AND d.status IN ('delivered', 'failed') AND d.created_at < ?
+    ORDER BY d.created_at
+    LIMIT ?
+)
+`
+
+type DeleteFinishedWebhookDeliveriesParams struct {
+	UserID    string    `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Limit     int64     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) DeleteFinishedWebhookDeliveries(ctx context.Context, arg DeleteFinishedWebhookDeliveriesParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteFinishedWebhookDeliveriesStmt, deleteFinishedWebhookDeliveries, arg.UserID, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhookDeliveriesByUserID = `-- name: DeleteWebhookDeliveriesByUserID :exec
+DELETE FROM webhook_deliveries
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteWebhookDeliveriesByUserID(ctx context.Context, userID string) error {
+	_, err := q.exec(ctx, q.deleteWebhookDeliveriesByUserIDStmt, deleteWebhookDeliveriesByUserID, userID)
+	return err
+}
+
+const deleteWebhookEndpointByUserID = `-- name: DeleteWebhookEndpointByUserID :execrows
+DELETE FROM webhook_endpoints
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteWebhookEndpointByUserID(ctx context.Context, userID string) (int64, error) {
+	result, err := q.exec(ctx, q.deleteWebhookEndpointByUserIDStmt, deleteWebhookEndpointByUserID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDueWebhookDeliveries = `-- name: GetDueWebhookDeliveries :many
+SELECT id, endpoint_id, user_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, delivered_at, redelivery_of, created_at, updated_at FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at, id
+LIMIT ?
+`
+
+type GetDueWebhookDeliveriesParams struct {
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	Limit         int64     `db:"limit" json:"limit"`
+}
+
+func (q *Queries) GetDueWebhookDeliveries(ctx context.Context, arg GetDueWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.query(ctx, q.getDueWebhookDeliveriesStmt, getDueWebhookDeliveries, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.EndpointID,
+			&i.UserID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			&i.RedeliveryOf,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, endpoint_id, user_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, delivered_at, redelivery_of, created_at, updated_at FROM webhook_deliveries
+WHERE id = ? AND user_id = ?
+`
+
+type GetWebhookDeliveryParams struct {
+	ID     int64  `db:"id" json:"id"`
+	UserID string `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.queryRow(ctx, q.getWebhookDeliveryStmt, getWebhookDelivery, arg.ID, arg.UserID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.EndpointID,
+		&i.UserID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		&i.RedeliveryOf,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookEndpoint = `-- name: GetWebhookEndpoint :one
+SELECT id, user_id, url, secret, created_by, created_at, updated_at FROM webhook_endpoints
+WHERE id = ?
+`
+
+func (q *Queries) GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error) {
+	row := q.queryRow(ctx, q.getWebhookEndpointStmt, getWebhookEndpoint, id)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.Secret,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookEndpointByUserID = `-- name: GetWebhookEndpointByUserID :one
+SELECT id, user_id, url, secret, created_by, created_at, updated_at FROM webhook_endpoints
+WHERE user_id = ?
+`
+
+func (q *Queries) GetWebhookEndpointByUserID(ctx context.Context, userID string) (WebhookEndpoint, error) {
+	row := q.queryRow(ctx, q.getWebhookEndpointByUserIDStmt, getWebhookEndpointByUserID, userID)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.Secret,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const leaseWebhookDelivery = `-- name: LeaseWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = ?
+WHERE id = ?
+`
+
+type LeaseWebhookDeliveryParams struct {
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	ID            int64     `db:"id" json:"id"`
+}
+
+func (q *Queries) LeaseWebhookDelivery(ctx context.Context, arg LeaseWebhookDeliveryParams) error {
+	_, err := q.exec(ctx, q.leaseWebhookDeliveryStmt, leaseWebhookDelivery, arg.NextAttemptAt, arg.ID)
+	return err
+}
+
+const listWebhookDeliveriesByUserID = `-- name: ListWebhookDeliveriesByUserID :many
+SELECT id, endpoint_id, user_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, delivered_at, redelivery_of, created_at, updated_at FROM webhook_deliveries
+WHERE user_id = ?1
+  AND (CAST(?2 AS INTEGER) = 0 OR id < ?2)
+ORDER BY id DESC
+LIMIT ?3
+`
+
+type ListWebhookDeliveriesByUserIDParams struct {
+	UserID   string `db:"user_id" json:"user_id"`
+	BeforeID int64  `db:"before_id" json:"before_id"`
+	Limit    int64  `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListWebhookDeliveriesByUserID(ctx context.Context, arg ListWebhookDeliveriesByUserIDParams) ([]WebhookDelivery, error) {
+	rows, err := q.query(ctx, q.listWebhookDeliveriesByUserIDStmt, listWebhookDeliveriesByUserID, arg.UserID, arg.BeforeID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.EndpointID,
+			&i.UserID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			&i.RedeliveryOf,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookDeliveryDelivered = `-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    response_status = ?,
+    delivered_at = ?,
+    last_error = NULL
+WHERE id = ?
+`
+
+type MarkWebhookDeliveryDeliveredParams struct {
+	ResponseStatus sql.NullInt64 `db:"response_status" json:"response_status"`
+	DeliveredAt    sql.NullTime  `db:"delivered_at" json:"delivered_at"`
+	ID             int64         `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error {
+	_, err := q.exec(ctx, q.markWebhookDeliveryDeliveredStmt, markWebhookDeliveryDelivered, arg.ResponseStatus, arg.DeliveredAt, arg.ID)
+	return err
+}
+
+const markWebhookDeliveryFailed = `-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = 'failed',
+    response_status = ?,
+    last_error = ?
+WHERE id = ?
+`
+
+type MarkWebhookDeliveryFailedParams struct {
+	ResponseStatus sql.NullInt64  `db:"response_status" json:"response_status"`
+	LastError      sql.NullString `db:"last_error" json:"last_error"`
+	ID             int64          `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	_, err := q.exec(ctx, q.markWebhookDeliveryFailedStmt, markWebhookDeliveryFailed, arg.ResponseStatus, arg.LastError, arg.ID)
+	return err
+}
+
+const rescheduleWebhookDelivery = `-- name: RescheduleWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET next_attempt_at = ?,
+    response_status = ?,
+    last_error = ?
+WHERE id = ?
+`
+
+type RescheduleWebhookDeliveryParams struct {
+	NextAttemptAt  time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseStatus sql.NullInt64  `db:"response_status" json:"response_status"`
+	LastError      sql.NullString `db:"last_error" json:"last_error"`
+	ID             int64          `db:"id" json:"id"`
+}
+
+func (q *Queries) RescheduleWebhookDelivery(ctx context.Context, arg RescheduleWebhookDeliveryParams) error {
+	_, err := q.exec(ctx, q.rescheduleWebhookDeliveryStmt, rescheduleWebhookDelivery,
+		arg.NextAttemptAt,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const upsertWebhookEndpoint = `-- name: UpsertWebhookEndpoint :exec
+INSERT INTO webhook_endpoints (
+    user_id,
+    url,
+    secret,
+    created_by
+) VALUES (
+    ?, ?, ?, ?
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    url = excluded.url,
+    secret = excluded.secret,
+    created_by = excluded.created_by
+`
+
+type UpsertWebhookEndpointParams struct {
+	UserID    string `db:"user_id" json:"user_id"`
+	Url       string `db:"url" json:"url"`
+	Secret    string `db:"secret" json:"secret"`
+	CreatedBy string `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) UpsertWebhookEndpoint(ctx context.Context, arg UpsertWebhookEndpointParams) error {
+	_, err := q.exec(ctx, q.upsertWebhookEndpointStmt, upsertWebhookEndpoint,
+		arg.UserID,
+		arg.Url,
+		arg.Secret,
+		arg.CreatedBy,
+	)
+	return err
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const collectTimeout = 5 * time.Second
+
+var (
+	jobQueueDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "job_queue_depth"),
+		"Number of jobs in the queue by status.",
+		[]string{"status"}, nil,
+	)
+	jobOldestPendingAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "job_oldest_pending_age_seconds"),
+		"Age of the oldest pending job that is due to run.",
+		nil, nil,
+	)
+	outboxEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "outbox_entries"),
+		"Number of notification outbox entries by status.",
+		[]string{"status"}, nil,
+	)
+	gmailWatchExpiringDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "gmail_watch_expiring_24h"),
+		"Number of Gmail watches expiring within the next 24 hours.",
+		nil, nil,
+	)
+)
+
+// StoreCollector reads queue and watch state from the database on each
+// scrape, so the values are always current even across restarts.
+type StoreCollector struct {
+	jobRepo    jobRepo.JobRepo
+	outboxRepo outboxRepo.OutboxRepo
+	userRepo   userRepo.UserRepo
+}
+
+var _ prometheus.Collector = (*StoreCollector)(nil)
+
+func NewStoreCollector(jobRepo jobRepo.JobRepo, outboxRepo outboxRepo.OutboxRepo, userRepo userRepo.UserRepo) *StoreCollector {
+	return &StoreCollector{
+		jobRepo:    jobRepo,
+		outboxRepo: outboxRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (c *StoreCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- jobQueueDepthDesc
+	ch <- jobOldestPendingAgeDesc
+	ch <- outboxEntriesDesc
+	ch <- gmailWatchExpiringDesc
+}
+
+func (c *StoreCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	if counts, err := c.jobRepo.CountJobsByStatus(ctx); err != nil {
+		slog.Error("failed to collect job queue depth", "error", err)
+	} else {
+		for _, status := range []string{jobRepo.StatusPending, jobRepo.StatusRunning, jobRepo.StatusDone, jobRepo.StatusDead} {
+			ch <- prometheus.MustNewConstMetric(jobQueueDepthDesc, prometheus.GaugeValue, float64(counts[status]), status)
+		}
+	}
+
+	if runAt, err := c.jobRepo.GetOldestPendingRunAt(ctx); err != nil {
+		slog.Error("failed to collect oldest pending job", "error", err)
+	} else {
+		age := 0.0
+		if runAt != nil && now.After(*runAt) {
+			age = now.Sub(*runAt).Seconds()
+		}
+		ch <- prometheus.MustNewConstMetric(jobOldestPendingAgeDesc, prometheus.GaugeValue, age)
+	}
+
+	if counts, err := c.outboxRepo.CountEntriesByStatus(ctx); err != nil {
+		slog.Error("failed to collect outbox entries", "error", err)
+	} else {
+		for _, status := range []string{outboxRepo.StatusPending, outboxRepo.StatusSent, outboxRepo.StatusFailed} {
+			ch <- prometheus.MustNewConstMetric(outboxEntriesDesc, prometheus.GaugeValue, float64(counts[status]), status)
+		}
+	}
+
+	if count, err := c.userRepo.CountWatchesExpiringBetween(ctx, now, now.Add(24*time.Hour)); err != nil {
+		slog.Error("failed to collect expiring Gmail watches", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(gmailWatchExpiringDesc, prometheus.GaugeValue, float64(count))
+	}
+}
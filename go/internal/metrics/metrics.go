@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+const namespace = "flux"
+
+// userBuckets bounds the cardinality of per-user metrics.
+const userBuckets = 16
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route"})
+
+	gmailAPICallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gmail_api_calls_total",
+		Help:      "Number of Gmail API calls by method and status.",
+	}, []string{"method", "status"})
+
+	gmailTokenRefreshFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gmail_token_refresh_failures_total",
+		Help:      "Number of failed Gmail OAuth token refreshes.",
+	})
+
+	graphAPICallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graph_api_calls_total",
+		Help:      "Number of Microsoft Graph API calls by method and status.",
+	}, []string{"method", "status"})
+
+	linePushTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "line_push_total",
+		Help:      "Number of LINE push requests by HTTP status code.",
+	}, []string{"status_code"})
+
+	notificationsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_sent_total",
+		Help:      "Number of notifications delivered by user bucket.",
+	}, []string{"user_bucket"})
+
+	emailsPrunedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_pruned_total",
+		Help:      "Number of stored emails deleted or stripped of content by the retention pruner.",
+	}, []string{"action"})
+)
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InstrumentHandler records request counts and latencies for route.
+func InstrumentHandler(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(rec, r)
+
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		httpRequestDuration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// ObserveGmailCall records the outcome of a Gmail API call, including the
+// token refreshes.
+func ObserveGmailCall(method string, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			status = strconv.Itoa(apiErr.Code)
+		}
+
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			status = "token_refresh_failed"
+			gmailTokenRefreshFailuresTotal.Inc()
+		}
+	}
+
+	gmailAPICallsTotal.WithLabelValues(method, status).Inc()
+}
+
+// ObserveGraphCall records the outcome of a Microsoft Graph call. statusCode
+// is 0 when the request failed before a response was received.
+func ObserveGraphCall(method string, statusCode int, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+		if statusCode > 0 {
+			status = strconv.Itoa(statusCode)
+		}
+
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			status = "token_refresh_failed"
+		}
+	}
+
+	graphAPICallsTotal.WithLabelValues(method, status).Inc()
+}
+
+// ObserveLinePush records a LINE push response. statusCode is 0 when the
+// request failed before a response was received.
+func ObserveLinePush(statusCode int) {
+	label := "error"
+	if statusCode > 0 {
+		label = strconv.Itoa(statusCode)
+	}
+	linePushTotal.WithLabelValues(label).Inc()
+}
+
+func IncNotificationsSent(userID string) {
+	notificationsSentTotal.WithLabelValues(UserBucket(userID)).Inc()
+}
+
+// AddEmailsPruned counts emails handled by the retention pruner; action is
+// "deleted" or "stripped".
+func AddEmailsPruned(action string, n int64) {
+	emailsPrunedTotal.WithLabelValues(action).Add(float64(n))
+}
+
+// UserBucket maps a user ID onto one of a fixed number of buckets so
+// per-user activity can be charted without a label per user.
+func UserBucket(userID string) string {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return strconv.Itoa(int(h.Sum32() % userBuckets))
+}
@@ -0,0 +1,379 @@
+// Package migrate applies the embedded SQL migrations. It reads the dbmate
+// file format and records versions in the same schema_migrations table, so
+// databases migrated by dbmate can be managed by either tool.
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	markerUp   = "-- migrate:up"
+	markerDown = "-- migrate:down"
+
+	// lockName is the advisory lock held while migrating, so that replicas
+	// starting at the same time do not apply migrations twice.
+	lockName    = "flux_schema_migrations"
+	lockTimeout = 60 // seconds
+)
+
+// ErrSchemaNewer means the database has migrations this binary does not
+// know, i.e. it was migrated by a newer release.
+var ErrSchemaNewer = errors.New("database schema is newer than this binary")
+
+type Migration struct {
+	Version string
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+type Status struct {
+	Migration *Migration
+	Applied   bool
+}
+
+// Dialects supported by the migrator.
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []*Migration
+}
+
+// New parses every *.sql file in dir of fsys.
+func New(db *sql.DB, dialect string, fsys fs.FS, dir string) (*Migrator, error) {
+	switch dialect {
+	case MySQL, Postgres, SQLite:
+	default:
+		return nil, fmt.Errorf("unsupported migration dialect %q", dialect)
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []*Migration
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", e.Name(), err)
+		}
+		m, err := parse(e.Name(), string(b), dialect == MySQL)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, m)
+	}
+
+	slices.SortFunc(migrations, func(a, b *Migration) int { return strings.Compare(a.Version, b.Version) })
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+func parse(name, content string, split bool) (*Migration, error) {
+	version, _, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+	if !ok || version == "" {
+		return nil, fmt.Errorf("migration %s: file name must start with a version", name)
+	}
+
+	upIdx := strings.Index(content, markerUp)
+	downIdx := strings.Index(content, markerDown)
+	if upIdx < 0 || downIdx < 0 || downIdx < upIdx {
+		return nil, fmt.Errorf("migration %s: missing %q or %q section", name, markerUp, markerDown)
+	}
+
+	m := &Migration{
+		Version: version,
+		Name:    strings.TrimSuffix(name, ".sql"),
+		Up:      statements(content[upIdx+len(markerUp):downIdx], split),
+		Down:    statements(content[downIdx+len(markerDown):], split),
+	}
+	if len(m.Up) == 0 {
+		return nil, fmt.Errorf("migration %s: empty %q section", name, markerUp)
+	}
+	// Every migration must be reversible, or "migrate down" would only
+	// forget the version and leave the schema in place.
+	if len(m.Down) == 0 {
+		return nil, fmt.Errorf("migration %s: empty %q section", name, markerDown)
+	}
+	return m, nil
+}
+
+// statements returns the statements of a section. The MySQL driver runs one
+// statement per Exec, so its sections are split at lines ending with a
+// semicolon. Other drivers run a whole section at once, which keeps
+// semicolons inside trigger and function bodies intact.
+func statements(section string, split bool) []string {
+	if !split {
+		if strings.TrimSpace(stripComments(section)) == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(section)}
+	}
+
+	var stmts []string
+	var current strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(section))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || (current.Len() == 0 && strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+
+	return stmts
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(128) PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context) (map[string]bool, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// Status lists every known migration in order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		statuses = append(statuses, Status{Migration: mig, Applied: applied[mig.Version]})
+	}
+	return statuses, nil
+}
+
+// Check returns the number of pending migrations, or ErrSchemaNewer when
+// the database has versions unknown to this binary.
+func (m *Migrator) Check(ctx context.Context) (int, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return m.pending(applied)
+}
+
+func (m *Migrator) pending(applied map[string]bool) (int, error) {
+	known := make(map[string]bool, len(m.migrations))
+	pending := 0
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if !applied[mig.Version] {
+			pending++
+		}
+	}
+
+	var unknown []string
+	for version := range applied {
+		if !known[version] {
+			unknown = append(unknown, version)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return pending, fmt.Errorf("%w: unknown versions %s", ErrSchemaNewer, strings.Join(unknown, ", "))
+	}
+	return pending, nil
+}
+
+// Up applies all pending migrations and returns them. It refuses to run on
+// a schema newer than the binary.
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	var done []*Migration
+	err := m.withLock(ctx, func() error {
+		applied, err := m.appliedVersions(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := m.pending(applied); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if applied[mig.Version] {
+				continue
+			}
+			if err := m.apply(ctx, mig.Name, mig.Up, m.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), mig.Version); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the most recently applied migration. It returns nil when
+// nothing is applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var done *Migration
+	err := m.withLock(ctx, func() error {
+		applied, err := m.appliedVersions(ctx)
+		if err != nil {
+			return err
+		}
+		// The latest version may belong to a newer binary whose down
+		// statements are unknown here.
+		if _, err := m.pending(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if !applied[mig.Version] {
+				continue
+			}
+			if err := m.apply(ctx, mig.Name, mig.Down, m.rebind(`DELETE FROM schema_migrations WHERE version = ?`), mig.Version); err != nil {
+				return err
+			}
+			done = mig
+			return nil
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock runs fn while holding the migration advisory lock. The lock
+// belongs to one connection, so it is taken on a dedicated one.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	// SQLite is opened with a single connection, which already serializes
+	// the migrators of this process.
+	if m.dialect == SQLite {
+		return fn()
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	unlock := `DO RELEASE_LOCK(?)`
+	if m.dialect == Postgres {
+		// pg_advisory_lock has no timeout of its own, so the wait is
+		// bounded by the context.
+		lockCtx, cancel := context.WithTimeout(ctx, lockTimeout*time.Second)
+		_, err := conn.ExecContext(lockCtx, `SELECT pg_advisory_lock(hashtext($1))`, lockName)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock %q: %w", lockName, err)
+		}
+		unlock = `SELECT pg_advisory_unlock(hashtext($1))`
+	} else {
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeout).Scan(&got); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if !got.Valid || got.Int64 != 1 {
+			return fmt.Errorf("timed out waiting for migration lock %q", lockName)
+		}
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), unlock, lockName); err != nil {
+			slog.Warn("failed to release migration lock", "error", err)
+		}
+	}()
+
+	return fn()
+}
+
+// rebind converts the ? placeholder of a schema_migrations query to the
+// dialect.
+func (m *Migrator) rebind(query string) string {
+	if m.dialect == Postgres {
+		return strings.Replace(query, "?", "$1", 1)
+	}
+	return query
+}
+
+// apply runs the statements and records the version in one transaction.
+// MySQL commits DDL implicitly, so a failed statement can leave the earlier
+// ones of the same migration applied.
+func (m *Migrator) apply(ctx context.Context, name string, statements []string, record, version string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", name, err)
+	}
+	return nil
+}
+
+func stripComments(section string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(section, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+// Package poller runs the background loops that claim work from the
+// database: the job workers and the notification and webhook dispatchers.
+package poller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PollFunc does one round of work and reports whether more is available,
+// in which case it is called again right away.
+type PollFunc func(ctx context.Context) bool
+
+// Poller calls a PollFunc from a number of goroutines on every tick of an
+// interval and whenever it is notified.
+type Poller struct {
+	name     string
+	interval time.Duration
+	workers  int
+	poll     PollFunc
+
+	trigger chan struct{}
+	cancel  context.CancelFunc
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New returns a poller; name identifies it in shutdown errors.
+func New(name string, interval time.Duration, workers int, poll PollFunc) *Poller {
+	return &Poller{
+		name:     name,
+		interval: interval,
+		workers:  workers,
+		poll:     poll,
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes a waiting goroutine after new work was written.
+func (p *Poller) Notify() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Start launches the goroutines. The PollFunc runs with a context derived
+// from ctx that outlives it, so cancelling ctx does not abort work in
+// progress; Shutdown does.
+func (p *Poller) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.run(ctx)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(p.done)
+	}()
+}
+
+// Shutdown stops polling and waits for the running rounds to finish. When
+// ctx expires first, their context is cancelled; the work they claimed is
+// retried later.
+func (p *Poller) Shutdown(ctx context.Context) error {
+	if p.stop == nil {
+		return nil
+	}
+	p.once.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-p.done
+		return fmt.Errorf("%s did not stop: %w", p.name, ctx.Err())
+	}
+}
+
+func (p *Poller) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		for p.poll(ctx) {
+			select {
+			case <-p.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		case <-p.trigger:
+		}
+	}
+}
+
+// Backoff is the delay before retrying work that failed attempts times: base
+// after the first failure, doubling up to maxDelay.
+func Backoff(attempts int, base, maxDelay time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxDelay {
+			return maxDelay
+		}
+	}
+	return d
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/service/notification"
+	"github.com/huavcjj/flux/internal/service/queue"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Service implements the operator actions exposed by the admin API.
+type Service struct {
+	userRepo            userRepo.UserRepo
+	jobRepo             jobRepo.JobRepo
+	outboxRepo          outboxRepo.OutboxRepo
+	notificationService *notification.Service
+	queueService        *queue.Service
+}
+
+func NewService(userRepo userRepo.UserRepo, jobRepo jobRepo.JobRepo, outboxRepo outboxRepo.OutboxRepo, notificationService *notification.Service, queueService *queue.Service) *Service {
+	return &Service{
+		userRepo:            userRepo,
+		jobRepo:             jobRepo,
+		outboxRepo:          outboxRepo,
+		notificationService: notificationService,
+		queueService:        queueService,
+	}
+}
+
+func (s *Service) ListUsers(ctx context.Context, filter userRepo.ListFilter) ([]userRepo.User, error) {
+	filter.Limit = clampLimit(filter.Limit)
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.userRepo.ListUsers(ctx, filter)
+}
+
+func (s *Service) GetUser(ctx context.Context, userID string) (*userRepo.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+// ResyncUser queues a mailbox check for the user. It runs on the job workers
+// so provider errors are retried like push notifications.
+func (s *Service) ResyncUser(ctx context.Context, userID string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// One resync per user and minute is enough; repeated clicks are ignored.
+	dedupKey := fmt.Sprintf("%s%d", jobRepo.ResyncDedupKeyPrefix(user.ID), time.Now().Unix()/60)
+	return s.queueService.Enqueue(ctx, jobRepo.TypeGmailResync, dedupKey, jobRepo.GmailResyncPayload{LineUserID: user.LineUserID})
+}
+
+func (s *Service) RewatchUser(ctx context.Context, userID string) (*mailRepo.Watch, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.notificationService.RenewWatch(ctx, user.LineUserID)
+}
+
+func (s *Service) DeactivateUser(ctx context.Context, userID string) error {
+	err := s.notificationService.DeactivateUser(ctx, userID)
+	if errors.Is(err, notification.ErrUserNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *Service) ListNotifications(ctx context.Context, status string, limit int) ([]outboxRepo.Entry, error) {
+	return s.outboxRepo.ListRecentEntries(ctx, status, clampLimit(limit))
+}
+
+func (s *Service) ListJobs(ctx context.Context, status string, limit int) ([]jobRepo.Job, error) {
+	if status == "" {
+		status = jobRepo.StatusDead
+	}
+	return s.jobRepo.ListJobsByStatus(ctx, status, clampLimit(limit))
+}
+
+// ReplayJob gives a dead-lettered job a fresh set of attempts.
+func (s *Service) ReplayJob(ctx context.Context, id uint64) error {
+	replayed, err := s.jobRepo.ReplayDeadJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !replayed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	return min(limit, maxLimit)
+}
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+
+	maxUserAgent = 512
+)
+
+// Service records security-relevant events in the append-only audit trail.
+type Service struct {
+	auditRepo auditRepo.AuditRepo
+	log       *slog.Logger
+}
+
+var _ auditRepo.AuditLogger = (*Service)(nil)
+
+func NewService(auditRepo auditRepo.AuditRepo) *Service {
+	return &Service{
+		auditRepo: auditRepo,
+		log:       slog.Default().With("log_type", "audit"),
+	}
+}
+
+// WithRequestClient returns ctx carrying the client of r, which Log adds to
+// events that do not set it themselves. Only the socket address is used, as
+// forwarding headers can be set by anyone.
+func WithRequestClient(ctx context.Context, r *http.Request) context.Context {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	return auditRepo.WithClient(ctx, auditRepo.Client{IPAddress: ip, UserAgent: r.UserAgent()})
+}
+
+// Log stores event. It is also written to the application log, so the trail
+// is not lost when the database is unavailable.
+func (s *Service) Log(ctx context.Context, event auditRepo.Event) {
+	if client, ok := auditRepo.ClientFromContext(ctx); ok {
+		if event.IPAddress == nil && client.IPAddress != "" {
+			event.IPAddress = &client.IPAddress
+		}
+		if event.UserAgent == nil && client.UserAgent != "" {
+			event.UserAgent = &client.UserAgent
+		}
+	}
+	if event.UserAgent != nil {
+		if ua := []rune(*event.UserAgent); len(ua) > maxUserAgent {
+			truncated := string(ua[:maxUserAgent])
+			event.UserAgent = &truncated
+		}
+	}
+
+	s.log.Info("audit event",
+		"actor", event.Actor,
+		"subject", deref(event.SubjectUserID),
+		"action", event.Action,
+		"result", event.Result,
+		"ip_address", deref(event.IPAddress),
+		"detail", deref(event.Detail),
+	)
+
+	// The event is recorded even if the request that caused it is canceled.
+	if err := s.auditRepo.CreateEvent(context.WithoutCancel(ctx), &event); err != nil {
+		s.log.Error("failed to record audit event", "action", event.Action, "error", err)
+	}
+}
+
+// ListEvents returns a page of the events of a user, newest first.
+func (s *Service) ListEvents(ctx context.Context, subjectUserID string, beforeID uint64, limit int) ([]auditRepo.Event, error) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return s.auditRepo.ListEventsBySubject(ctx, subjectUserID, beforeID, limit)
+}
+
+// Record is the JSON form of an event.
+type Record struct {
+	ID            uint64    `json:"id"`
+	Actor         string    `json:"actor"`
+	SubjectUserID *string   `json:"subject_user_id"`
+	Action        string    `json:"action"`
+	Result        string    `json:"result"`
+	IPAddress     *string   `json:"ip_address,omitempty"`
+	UserAgent     *string   `json:"user_agent,omitempty"`
+	Detail        *string   `json:"detail,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func NewRecord(e *auditRepo.Event) Record {
+	return Record{
+		ID:            e.ID,
+		Actor:         e.Actor,
+		SubjectUserID: e.SubjectUserID,
+		Action:        e.Action,
+		Result:        e.Result,
+		IPAddress:     e.IPAddress,
+		UserAgent:     e.UserAgent,
+		Detail:        e.Detail,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+// ExportJSONL writes every event of a user to w as JSON lines, newest
+// first, and returns the number of events written.
+func (s *Service) ExportJSONL(ctx context.Context, subjectUserID string, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+
+	var written int
+	var beforeID uint64
+	for {
+		events, err := s.auditRepo.ListEventsBySubject(ctx, subjectUserID, beforeID, maxLimit)
+		if err != nil {
+			return written, err
+		}
+
+		for i := range events {
+			if err := enc.Encode(NewRecord(&events[i])); err != nil {
+				return written, fmt.Errorf("failed to write audit event: %w", err)
+			}
+			written++
+		}
+
+		if len(events) < maxLimit {
+			return written, nil
+		}
+		beforeID = events[len(events)-1].ID
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
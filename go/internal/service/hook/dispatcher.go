@@ -0,0 +1,118 @@
+package hook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
+	"github.com/huavcjj/flux/internal/poller"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	pollInterval = 2 * time.Second
+	batchSize    = 20
+	// leaseDuration must exceed the request timeout of the sender.
+	leaseDuration = time.Minute
+	maxAttempts   = 10
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 2 * time.Hour
+)
+
+// Dispatcher sends due webhook deliveries. Delivery is at-least-once;
+// receivers deduplicate by the event ID.
+type Dispatcher struct {
+	hookRepo hookRepo.HookRepo
+	sender   hookRepo.Sender
+
+	*poller.Poller
+}
+
+// NewDispatcher returns a dispatcher; Notify wakes it after new deliveries
+// were written.
+func NewDispatcher(hookRepo hookRepo.HookRepo, sender hookRepo.Sender) *Dispatcher {
+	d := &Dispatcher{
+		hookRepo: hookRepo,
+		sender:   sender,
+	}
+	d.Poller = poller.New("webhook dispatcher", pollInterval, 1, func(ctx context.Context) bool {
+		d.DispatchDue(ctx)
+		return false
+	})
+	return d
+}
+
+// DispatchDue sends one batch of due deliveries and reports how many
+// succeeded.
+func (d *Dispatcher) DispatchDue(ctx context.Context) int {
+	now := time.Now()
+	deliveries, err := d.hookRepo.ClaimDueDeliveries(ctx, now, now.Add(leaseDuration), batchSize)
+	if err != nil {
+		slog.Error("failed to claim webhook deliveries", "error", err)
+		return 0
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		if d.deliver(ctx, &deliveries[i]) {
+			delivered++
+		}
+	}
+
+	return delivered
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *hookRepo.Delivery) bool {
+	logger := slog.With("delivery_id", delivery.ID, "user_id", delivery.UserID, "event_type", delivery.EventType, "attempt", delivery.Attempts)
+
+	ctx, span := tracing.Start(ctx, "hook.deliver",
+		tracing.UserID(delivery.UserID),
+		attribute.String("hook.event_type", delivery.EventType),
+		attribute.Int("hook.attempt", delivery.Attempts),
+	)
+	defer span.End()
+
+	endpoint, err := d.hookRepo.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		logger.Error("failed to get webhook endpoint", "error", err)
+		return false
+	}
+	if endpoint == nil {
+		// The endpoint was removed after the claim.
+		return false
+	}
+
+	status, err := d.sender.Send(ctx, endpoint, delivery)
+	tracing.Error(span, err)
+	var responseStatus *int
+	if status != 0 {
+		responseStatus = &status
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+
+	if err == nil {
+		if err := d.hookRepo.MarkDelivered(ctx, delivery.ID, status, time.Now()); err != nil {
+			logger.Error("failed to mark webhook delivery delivered", "error", err)
+		}
+		logger.Info("webhook delivered", "status", status)
+		return true
+	}
+
+	if delivery.Attempts >= maxAttempts {
+		logger.Error("webhook delivery failed permanently", "error", err)
+		if err := d.hookRepo.MarkFailed(ctx, delivery.ID, responseStatus, err.Error()); err != nil {
+			logger.Error("failed to mark webhook delivery failed", "error", err)
+		}
+		return false
+	}
+
+	nextAttemptAt := time.Now().Add(poller.Backoff(delivery.Attempts, baseBackoff, maxBackoff))
+	logger.Warn("webhook delivery failed, scheduling retry", "error", err, "next_attempt_at", nextAttemptAt)
+	if err := d.hookRepo.Reschedule(ctx, delivery.ID, nextAttemptAt, responseStatus, err.Error()); err != nil {
+		logger.Error("failed to reschedule webhook delivery", "error", err)
+	}
+	return false
+}
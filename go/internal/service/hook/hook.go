@@ -0,0 +1,205 @@
+package hook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+
+	secretPrefix = "whsec_"
+)
+
+var (
+	ErrDisabled = errors.New("webhooks are disabled")
+	ErrNotFound = errors.New("webhook not found")
+	// ErrPayloadPruned is returned for deliveries whose payload was dropped
+	// by the retention policy.
+	ErrPayloadPruned = errors.New("webhook delivery payload was pruned")
+)
+
+// Service manages webhook endpoints and queues their events. With webhooks
+// disabled, events are dropped and endpoints cannot be registered.
+type Service struct {
+	hookRepo hookRepo.HookRepo
+	sender   hookRepo.Sender
+	enabled  bool
+}
+
+func NewService(hookRepo hookRepo.HookRepo, sender hookRepo.Sender, enabled bool) *Service {
+	return &Service{
+		hookRepo: hookRepo,
+		sender:   sender,
+		enabled:  enabled,
+	}
+}
+
+func (s *Service) Enabled() bool {
+	return s.enabled
+}
+
+// RegisterEndpoint sets the endpoint of the user with a new secret. The
+// secret is only returned here.
+func (s *Service) RegisterEndpoint(ctx context.Context, userID, rawURL, createdBy string) (*hookRepo.Endpoint, error) {
+	if !s.enabled {
+		return nil, ErrDisabled
+	}
+	if err := s.sender.ValidateURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &hookRepo.Endpoint{
+		UserID:    userID,
+		URL:       rawURL,
+		Secret:    secret,
+		CreatedBy: createdBy,
+	}
+	if err := s.hookRepo.SaveEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *Service) GetEndpoint(ctx context.Context, userID string) (*hookRepo.Endpoint, error) {
+	endpoint, err := s.hookRepo.GetEndpointByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, ErrNotFound
+	}
+	return endpoint, nil
+}
+
+// RemoveEndpoint deletes the endpoint of the user and its delivery log.
+func (s *Service) RemoveEndpoint(ctx context.Context, userID string) error {
+	removed, err := s.hookRepo.DeleteEndpoint(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, userID string, beforeID uint64, limit int) ([]hookRepo.Delivery, error) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	return s.hookRepo.ListDeliveries(ctx, userID, beforeID, min(limit, maxLimit))
+}
+
+// Redeliver queues the event of a delivery again, to the user's current
+// endpoint. The original delivery stays in the log unchanged.
+func (s *Service) Redeliver(ctx context.Context, userID string, deliveryID uint64) (*hookRepo.Delivery, error) {
+	if !s.enabled {
+		return nil, ErrDisabled
+	}
+
+	original, err := s.hookRepo.GetDelivery(ctx, userID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrNotFound
+	}
+	if original.Payload == "" {
+		return nil, ErrPayloadPruned
+	}
+	endpoint, err := s.GetEndpoint(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &hookRepo.Delivery{
+		EndpointID:   endpoint.ID,
+		UserID:       userID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		RedeliveryOf: &original.ID,
+	}
+	if err := s.hookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Deliveries returns the deliveries of an event to the user's endpoint
+// without storing them, for callers that store them in their own
+// transaction. Users without an endpoint get none.
+func (s *Service) Deliveries(ctx context.Context, userID, eventType string, data any) ([]*hookRepo.Delivery, error) {
+	if !s.enabled {
+		return nil, nil
+	}
+
+	endpoint, err := s.hookRepo.GetEndpointByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, nil
+	}
+
+	event := hookRepo.Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Version:   hookRepo.EventVersion,
+		CreatedAt: time.Now().UTC(),
+		UserID:    userID,
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	return []*hookRepo.Delivery{{
+		EndpointID: endpoint.ID,
+		UserID:     userID,
+		EventID:    event.ID,
+		EventType:  eventType,
+		Payload:    string(payload),
+	}}, nil
+}
+
+// Emit queues an event for the user's endpoint. Failures are logged, so a
+// webhook problem never fails the operation that caused the event.
+func (s *Service) Emit(ctx context.Context, userID, eventType string, data any) {
+	deliveries, err := s.Deliveries(ctx, userID, eventType, data)
+	if err == nil {
+		for _, delivery := range deliveries {
+			if err = s.hookRepo.CreateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		slog.Error("failed to queue webhook event", "user_id", userID, "event_type", eventType, "error", err)
+	}
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
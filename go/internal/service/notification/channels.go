@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	notifierRepo "github.com/huavcjj/flux/internal/domain/notifier"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+const (
+	msgChannelUnavailable = "%s通知は現在利用できません。"
+	msgChannelInvalid     = "%sのWebhook URLが正しくありません。URLをそのまま貼り付けてください。"
+	msgChannelTestFailed  = "%sへのテスト送信に失敗しました。Webhook URLが有効か確認してください。"
+	msgChannelAdded       = "✅ %sへの通知を開始しました。新着メールはLINEと%sの両方に届きます。"
+	msgChannelRemoved     = "%sへの通知を停止しました。"
+	msgChannelNotAdded    = "%sは通知先に登録されていません。"
+	msgChannels           = "🔔 通知先\n\n%s"
+
+	channelOn  = "✅"
+	channelOff = "—"
+
+	testTitle   = "🔔 Flux テスト通知"
+	testSender  = "Flux"
+	testSubject = "通知先の登録"
+	testSnippet = "このチャンネルにGmailの新着メールが届きます。"
+)
+
+// extraChannels are the channels users can add, in display order.
+var extraChannels = []struct {
+	channel string
+	name    string
+}{
+	{outboxRepo.ChannelSlack, "Slack"},
+	{outboxRepo.ChannelDiscord, "Discord"},
+	{outboxRepo.ChannelTelegram, "Telegram"},
+}
+
+func channelName(channel string) string {
+	for _, c := range extraChannels {
+		if c.channel == channel {
+			return c.name
+		}
+	}
+	return channel
+}
+
+// AddChannel registers target as the user's destination on channel, which
+// then receives every new email notification in addition to LINE. A test
+// notification is sent first, so only working targets are saved.
+func (s *Service) AddChannel(ctx context.Context, userID, channel, target string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.AddChannel", tracing.UserID(userID))
+	defer span.End()
+
+	name := channelName(channel)
+	notifier, ok := s.notifiers[channel]
+	if !ok {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannelUnavailable, name)))
+	}
+	if err := notifier.ValidateRecipient(target); err != nil {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannelInvalid, name)))
+	}
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	if err := notifier.Send(ctx, target, testNotification()); err != nil {
+		slog.Warn("channel test notification failed", "user_id", userID, "channel", channel, "error", err)
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannelTestFailed, name)))
+	}
+
+	err = s.channelRepo.SaveChannel(ctx, &notifierRepo.Channel{
+		UserID:  user.ID,
+		Channel: channel,
+		Target:  target,
+	})
+	s.auditDetail(ctx, auditRepo.ActionChannelAdd, user, channel, err)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannelAdded, name, name)))
+}
+
+func testNotification() notifierRepo.Notification {
+	return notifierRepo.Notification{
+		Title:   testTitle,
+		Sender:  testSender,
+		Subject: testSubject,
+		Snippet: testSnippet,
+		Text:    testTitle + "\n\n" + testSnippet,
+	}
+}
+
+func (s *Service) RemoveChannel(ctx context.Context, userID, channel string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.RemoveChannel", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	removed, err := s.channelRepo.DeleteChannel(ctx, user.ID, channel)
+	if err != nil {
+		s.auditDetail(ctx, auditRepo.ActionChannelRemove, user, channel, err)
+		return tracing.Error(span, err)
+	}
+
+	name := channelName(channel)
+	if !removed {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannelNotAdded, name)))
+	}
+	s.auditDetail(ctx, auditRepo.ActionChannelRemove, user, channel, nil)
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannelRemoved, name)))
+}
+
+// SendChannels lists where the user receives notifications. Channels that
+// are disabled on this server are left out.
+func (s *Service) SendChannels(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	channels, err := s.channelRepo.ListChannels(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	added := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		added[ch.Channel] = true
+	}
+
+	lines := []string{"LINE " + channelOn}
+	for _, c := range extraChannels {
+		if _, ok := s.notifiers[c.channel]; !ok {
+			continue
+		}
+		mark := channelOff
+		if added[c.channel] {
+			mark = channelOn
+		}
+		lines = append(lines, c.name+" "+mark)
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgChannels, strings.Join(lines, "\n"))))
+}
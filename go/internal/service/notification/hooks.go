@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	"github.com/huavcjj/flux/internal/service/hook"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+const (
+	msgWebhookUnavailable  = "Webhook送信は現在利用できません。"
+	msgWebhookInvalid      = "Webhook URLが正しくありません。https:// で始まる公開URLを指定してください。"
+	msgWebhookAdded        = "✅ Webhookを登録しました。\n\nURL: %s\n署名シークレット: %s\n\nシークレットは再表示できません。リクエストの X-Flux-Signature ヘッダーは、「X-Flux-Timestamp の値 + \".\" + 本文」をシークレットで HMAC-SHA256 した16進数に「sha256=」を付けたものです。"
+	msgWebhookRemoved      = "Webhookの登録を解除しました。"
+	msgWebhookNotAdded     = "Webhookは登録されていません。"
+	msgWebhookNoDeliveries = "Webhookの送信履歴はありません。"
+	msgWebhookDeliveries   = "📮 Webhook送信履歴\n\n%s\n\n再送するには「Webhook再送 番号」を送信してください。"
+	msgWebhookRedelivered  = "✅ #%d を再送します。新しい送信は #%d です。"
+	msgWebhookNoDelivery   = "#%d の送信履歴が見つかりません。"
+
+	webhookHistoryLimit = 10
+)
+
+var deliveryStatusLabels = map[string]string{
+	hookRepo.StatusPending:   "送信待ち",
+	hookRepo.StatusDelivered: "成功",
+	hookRepo.StatusFailed:    "失敗",
+}
+
+// RegisterWebhook sets the HTTPS endpoint that receives the user's events
+// and replies with its signing secret, which is not shown again.
+func (s *Service) RegisterWebhook(ctx context.Context, userID, rawURL string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.RegisterWebhook", tracing.UserID(userID))
+	defer span.End()
+
+	if !s.hooks.Enabled() {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgWebhookUnavailable))
+	}
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	endpoint, err := s.hooks.RegisterEndpoint(ctx, user.ID, rawURL, auditRepo.ActorUser)
+	if errors.Is(err, hookRepo.ErrInvalidURL) {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgWebhookInvalid))
+	}
+	s.audit(ctx, auditRepo.ActionWebhookAdd, user, err)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgWebhookAdded, endpoint.URL, endpoint.Secret)))
+}
+
+func (s *Service) RemoveWebhook(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.RemoveWebhook", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	err = s.hooks.RemoveEndpoint(ctx, user.ID)
+	if errors.Is(err, hook.ErrNotFound) {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgWebhookNotAdded))
+	}
+	s.audit(ctx, auditRepo.ActionWebhookRemove, user, err)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgWebhookRemoved))
+}
+
+// SendWebhookDeliveries lists the latest deliveries to the user's endpoint.
+func (s *Service) SendWebhookDeliveries(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	deliveries, err := s.hooks.ListDeliveries(ctx, user.ID, 0, webhookHistoryLimit)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgWebhookNoDeliveries))
+	}
+
+	lines := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		line := fmt.Sprintf("#%d %s %s %s", d.ID, d.CreatedAt.Local().Format("01/02 15:04"), d.EventType, deliveryStatusLabels[d.Status])
+		if d.ResponseStatus != nil {
+			line += " (HTTP " + strconv.Itoa(*d.ResponseStatus) + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgWebhookDeliveries, strings.Join(lines, "\n"))))
+}
+
+// RedeliverWebhook sends the event of one of the user's deliveries again.
+func (s *Service) RedeliverWebhook(ctx context.Context, userID string, deliveryID uint64, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.RedeliverWebhook", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	delivery, err := s.hooks.Redeliver(ctx, user.ID, deliveryID)
+	switch {
+	case errors.Is(err, hook.ErrDisabled):
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgWebhookUnavailable))
+	case errors.Is(err, hook.ErrNotFound):
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgWebhookNoDelivery, deliveryID)))
+	case err != nil:
+		return tracing.Error(span, err)
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgWebhookRedelivered, deliveryID, delivery.ID)))
+}
@@ -2,274 +2,946 @@ package notification
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
 	emailRepo "github.com/huavcjj/flux/internal/domain/email"
-	gmailRepo "github.com/huavcjj/flux/internal/domain/gmail"
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
 	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	notifierRepo "github.com/huavcjj/flux/internal/domain/notifier"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
 	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/service/hook"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/oauth2"
 )
 
 const (
-	maxUnreadEmails = 10
-	maxPushEmails   = 5
-
-	msgGmailUnavailable     = "Gmail機能は現在利用できません。設定を確認してください。"
-	msgGmailUnavailableAuth = "Gmail機能は現在利用できません。管理者にお問い合わせください。"
-	msgAuthRequired         = "Gmail連携が必要です。「Gmail連携」を送信して認証してください。"
-	msgNoUnreadEmails       = "📭 未読メールはありません"
-	msgNoEmails             = "📭 メールはありません"
-	msgAuthComplete         = "✅ Gmail連携が完了しました！\n\n新着メールが届くと自動で通知されます。\n\n手動確認: 「未読mail」または「mail一覧」を送信"
-	msgAuthStart            = "Gmail連携を開始します。\n\n次のメッセージのURLからGoogleアカウントで認証してください。\n\n認証が完了すると自動的に連携されます。"
+	// tokenRefreshMargin is how long before their expiry tokens are
+	// refreshed, so they stay valid through the calls of one operation.
+	tokenRefreshMargin = 5 * time.Minute
+
+	defaultMaxUnreadEmails = 10
+	defaultMaxPushEmails   = 5
+	// maxListResults is the largest page Gmail lists; postbacks asking
+	// for more get the default page size.
+	maxListResults = 500
+
+	msgMailUnavailable     = "メール機能は現在利用できません。設定を確認してください。"
+	msgMailUnavailableAuth = "%s連携は現在利用できません。管理者にお問い合わせください。"
+	msgAuthRequired        = "%s連携が必要です。%sを送信して認証してください。"
+	msgNoUnreadEmails      = "📭 未読メールはありません"
+	msgNoEmails            = "📭 メールはありません"
+	msgAuthComplete        = "✅ %s連携が完了しました！\n\n新着メールが届くと自動で通知されます。\n\n手動確認: 「%s」または「%s」を送信"
+	msgAuthUnlinked        = "%s連携を解除しました。\n\n再度連携する場合は「%s」を送信してください。"
+	msgAuthStart           = "%s連携を開始します。\n\n次のメッセージのURLから%sで認証してください。\n\n認証が完了すると自動的に連携されます。"
+	msgNotLinked           = "%sは連携されていません。"
+	msgMarkedAsRead        = "✅ %d件を既読にしました"
+	msgReauthRequired      = "既読にするには%sの再連携が必要です。「%s」を送信してください。"
+	msgMarkReadFailed      = "既読にできませんでした。しばらくしてから再度お試しください。"
+
+	labelMore       = "もっと見る"
+	labelUnreadOnly = "未読のみ"
+	labelMarkRead   = "既読にする"
+	labelMailList   = "mail一覧"
+	labelMail       = "メール"
 
 	titleUnreadEmails = "📬 未読メール"
 	titleLatestEmails = "📨 最新メール"
 	titleNewEmail     = "📧 新着メール"
 )
 
+// Text commands of the bot. The webhook handlers route them and replies
+// offer them as quick replies and in instructions.
+const (
+	CommandHelp           = "ヘルプ"
+	CommandGmailAuth      = "Gmail連携"
+	CommandGmailUnlink    = "Gmail連携解除"
+	CommandOutlookAuth    = "Outlook連携"
+	CommandOutlookUnlink  = "Outlook連携解除"
+	CommandMailUnlink     = "メール連携解除"
+	CommandUnreadMail     = "未読mail"
+	CommandMailList       = "mail一覧"
+	CommandRetention      = "保存期間"
+	CommandSearch         = "検索"
+	CommandDataExport     = "データ出力"
+	CommandDataErase      = "データ削除"
+	CommandSlackLink      = "Slack連携"
+	CommandSlackUnlink    = "Slack連携解除"
+	CommandDiscordLink    = "Discord連携"
+	CommandDiscordUnlink  = "Discord連携解除"
+	CommandTelegramLink   = "Telegram連携"
+	CommandTelegramUnlink = "Telegram連携解除"
+	CommandChannels       = "通知先"
+	CommandHookRegister   = "Webhook登録"
+	CommandHookRemove     = "Webhook解除"
+	CommandHookHistory    = "Webhook履歴"
+	CommandHookRedeliver  = "Webhook再送"
+)
+
+// providerInfo describes a mail provider to users and the audit trail.
+type providerInfo struct {
+	label string
+	// account is what the user signs in with.
+	account      string
+	linkCommand  string
+	linkAction   string
+	unlinkAction string
+}
+
+var mailProviders = map[string]providerInfo{
+	mailRepo.ProviderGmail: {
+		label:        "Gmail",
+		account:      "Googleアカウント",
+		linkCommand:  CommandGmailAuth,
+		linkAction:   auditRepo.ActionGmailLink,
+		unlinkAction: auditRepo.ActionGmailUnlink,
+	},
+	mailRepo.ProviderOutlook: {
+		label:        "Outlook",
+		account:      "Microsoftアカウント",
+		linkCommand:  CommandOutlookAuth,
+		linkAction:   auditRepo.ActionOutlookLink,
+		unlinkAction: auditRepo.ActionOutlookUnlink,
+	},
+}
+
+// providerOrder is the order providers are offered to users in.
+var providerOrder = []string{mailRepo.ProviderGmail, mailRepo.ProviderOutlook}
+
+// Postback actions carried in quick reply data.
+const (
+	PostbackActionMailList = "mail_list"
+	PostbackActionMarkRead = "mark_read"
+	PostbackActionSearch   = "search"
+	// PostbackActionStoredList pages the stored emails shown when Gmail
+	// cannot be reached.
+	PostbackActionStoredList = "stored_list"
+	PostbackActionErase      = "erase"
+)
+
+type Config struct {
+	MaxUnreadEmails int64
+	MaxPushEmails   int64
+	// HashMessageIDs stores only a hash of each Gmail message ID and no
+	// content.
+	HashMessageIDs bool
+	// EmailRetentionDays is the default retention of stored emails; 0
+	// keeps them forever.
+	EmailRetentionDays int
+	// PublicURL is the base of links sent to users.
+	PublicURL string
+	// ExportSigningKey signs data export links; exports are disabled
+	// without it.
+	ExportSigningKey []byte
+	ExportURLTTL     time.Duration
+	// TelegramBotUsername makes Telegram link codes open the bot directly.
+	TelegramBotUsername string
+}
+
 type Service struct {
-	gmailRepo   gmailRepo.GmailRepo
+	// providers holds the mail providers that are configured, by name.
+	providers   map[string]mailRepo.MailProvider
 	lineRepo    lineRepo.LineRepo
 	userRepo    userRepo.UserRepo
 	emailRepo   emailRepo.EmailRepo
-	pendingAuth map[string]bool
+	outboxRepo  outboxRepo.OutboxRepo
+	channelRepo notifierRepo.ChannelRepo
+	auditRepo   auditRepo.AuditLogger
+	// notifiers holds the delivery channels that are enabled, by name.
+	notifiers   map[string]notifierRepo.Notifier
+	hooks       *hook.Service
+	cfg         Config
+	authMu      sync.Mutex
+	pendingAuth map[string]string
 }
 
-func NewService(gmailRepo gmailRepo.GmailRepo, lineRepo lineRepo.LineRepo, userRepo userRepo.UserRepo, emailRepo emailRepo.EmailRepo) *Service {
+func NewService(providers map[string]mailRepo.MailProvider, lineRepo lineRepo.LineRepo, userRepo userRepo.UserRepo, emailRepo emailRepo.EmailRepo, outboxRepo outboxRepo.OutboxRepo, channelRepo notifierRepo.ChannelRepo, notifiers map[string]notifierRepo.Notifier, auditRepo auditRepo.AuditLogger, hooks *hook.Service, cfg Config) *Service {
+	if cfg.MaxUnreadEmails <= 0 {
+		cfg.MaxUnreadEmails = defaultMaxUnreadEmails
+	}
+	if cfg.MaxPushEmails <= 0 {
+		cfg.MaxPushEmails = defaultMaxPushEmails
+	}
+
 	return &Service{
-		gmailRepo:   gmailRepo,
+		providers:   providers,
 		lineRepo:    lineRepo,
 		userRepo:    userRepo,
 		emailRepo:   emailRepo,
-		pendingAuth: make(map[string]bool),
+		outboxRepo:  outboxRepo,
+		channelRepo: channelRepo,
+		auditRepo:   auditRepo,
+		notifiers:   notifiers,
+		hooks:       hooks,
+		cfg:         cfg,
+		pendingAuth: make(map[string]string),
 	}
 }
 
 func (s *Service) IsAuthPending(userID string) bool {
-	return s.pendingAuth[userID]
+	_, ok := s.pendingProvider(userID)
+	return ok
+}
+
+// pendingProvider returns the provider whose auth the user started.
+func (s *Service) pendingProvider(userID string) (string, bool) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	provider, ok := s.pendingAuth[userID]
+	return provider, ok
+}
+
+func (s *Service) setPendingAuth(userID, provider string) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	s.pendingAuth[userID] = provider
+}
+
+func (s *Service) clearPendingAuth(userID string) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	delete(s.pendingAuth, userID)
+}
+
+func (s *Service) IsMailLinked(ctx context.Context, userID string) (bool, error) {
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user != nil && isMailLinked(user), nil
+}
+
+func (s *Service) SendAuthRequired(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+}
+
+func isMailLinked(user *userRepo.User) bool {
+	return user.MailAccessToken != nil && *user.MailAccessToken != ""
+}
+
+// mailProvider returns the provider the user linked, if it is configured.
+func (s *Service) mailProvider(user *userRepo.User) (mailRepo.MailProvider, bool) {
+	provider, ok := s.providers[user.MailProvider]
+	return provider, ok
 }
 
-func (s *Service) getUserToken(user *userRepo.User) *oauth2.Token {
+func mailWatchID(user *userRepo.User) string {
+	if user.MailWatchID == nil {
+		return ""
+	}
+	return *user.MailWatchID
+}
+
+func (s *Service) storedToken(user *userRepo.User) *oauth2.Token {
 	var expiry time.Time
-	if user.GmailTokenExpiresAt != nil {
-		expiry = time.Unix(*user.GmailTokenExpiresAt, 0)
+	if user.MailTokenExpiresAt != nil {
+		expiry = time.Unix(*user.MailTokenExpiresAt, 0)
 	}
 
 	token := &oauth2.Token{Expiry: expiry}
-	if user.GmailAccessToken != nil {
-		token.AccessToken = *user.GmailAccessToken
+	if user.MailAccessToken != nil {
+		token.AccessToken = *user.MailAccessToken
 	}
-	if user.GmailRefreshToken != nil {
-		token.RefreshToken = *user.GmailRefreshToken
+	if user.MailRefreshToken != nil {
+		token.RefreshToken = *user.MailRefreshToken
 	}
 
 	return token
 }
 
+// userToken returns the mail token of the user, refreshed first when it is
+// about to expire. Providers never refresh tokens themselves, so every
+// refresh is saved and audited here. When the refresh fails the stored token
+// is returned and the provider call reports the failure.
+func (s *Service) userToken(ctx context.Context, user *userRepo.User) *oauth2.Token {
+	token := s.storedToken(user)
+	provider, ok := s.mailProvider(user)
+	if !ok || token.RefreshToken == "" || token.Expiry.IsZero() || time.Until(token.Expiry) > tokenRefreshMargin {
+		return token
+	}
+
+	refreshed, err := provider.RefreshToken(ctx, token)
+	if err != nil {
+		s.auditSystem(ctx, auditRepo.ActionMailTokenRefresh, user, err)
+		slog.Warn("failed to refresh mail token", "user_id", user.LineUserID, "provider", user.MailProvider, "error", err)
+		return token
+	}
+	// Providers that do not rotate refresh tokens leave it out.
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+
+	err = s.userRepo.UpdateRefreshedMailTokens(ctx, user.ID, user.MailProvider, refreshed)
+	s.auditSystem(ctx, auditRepo.ActionMailTokenRefresh, user, err)
+	if err != nil {
+		slog.Warn("failed to save refreshed mail token", "user_id", user.LineUserID, "error", err)
+	} else {
+		// Later calls for the same user object reuse the token.
+		user.MailAccessToken = &refreshed.AccessToken
+		user.MailRefreshToken = &refreshed.RefreshToken
+		expiry := refreshed.Expiry.Unix()
+		user.MailTokenExpiresAt = &expiry
+	}
+	return refreshed
+}
+
 func (s *Service) getAuthenticatedUser(ctx context.Context, userID string) (*userRepo.User, error) {
 	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if user == nil || user.GmailAccessToken == nil || *user.GmailAccessToken == "" {
-		return nil, fmt.Errorf("user not authenticated with Gmail")
+	if user == nil || !isMailLinked(user) {
+		return nil, fmt.Errorf("user has not linked a mailbox")
 	}
 
 	return user, nil
 }
 
-func (s *Service) SendUnreadEmailList(ctx context.Context, userID string) error {
-	if s.gmailRepo == nil {
-		return s.lineRepo.PushMessage(ctx, userID, msgGmailUnavailable)
+func (s *Service) SendUnreadEmailList(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	return s.sendEmailPage(ctx, userID, mailRepo.ListOptions{MaxResults: s.cfg.MaxUnreadEmails, UnreadOnly: true}, replyToken)
+}
+
+func (s *Service) SendEmailList(ctx context.Context, userID string, maxResults int64, replyToken lineRepo.ReplyToken) error {
+	return s.sendEmailPage(ctx, userID, mailRepo.ListOptions{MaxResults: maxResults}, replyToken)
+}
+
+// SendMoreEmails continues a list from the page token of a "もっと見る"
+// postback, with the page size of the list it continues.
+func (s *Service) SendMoreEmails(ctx context.Context, userID string, unreadOnly bool, pageToken string, limit int64, replyToken lineRepo.ReplyToken) error {
+	return s.sendEmailPage(ctx, userID, mailRepo.ListOptions{MaxResults: s.pageLimit(limit), UnreadOnly: unreadOnly, PageToken: pageToken}, replyToken)
+}
+
+// pageLimit returns the page size a postback carries, or the default when
+// it has none or one out of range.
+func (s *Service) pageLimit(limit int64) int64 {
+	if limit <= 0 || limit > maxListResults {
+		return s.cfg.MaxUnreadEmails
 	}
+	return limit
+}
+
+func (s *Service) sendEmailPage(ctx context.Context, userID string, opts mailRepo.ListOptions, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.sendEmailPage",
+		tracing.UserID(userID),
+		attribute.Bool("gmail.unread_only", opts.UnreadOnly),
+		attribute.Bool("gmail.next_page", opts.PageToken != ""),
+	)
+	defer span.End()
 
 	user, err := s.getAuthenticatedUser(ctx, userID)
 	if err != nil {
-		return s.lineRepo.PushMessage(ctx, userID, msgAuthRequired)
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+	provider, ok := s.mailProvider(user)
+	if !ok {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgMailUnavailable))
 	}
 
-	messages, err := s.gmailRepo.GetUnreadMessages(ctx, s.getUserToken(user), maxUnreadEmails)
+	list, err := provider.ListMessages(ctx, s.userToken(ctx, user), opts)
 	if err != nil {
-		return fmt.Errorf("failed to get unread messages: %w", err)
+		// The latest emails are also stored locally, so show those
+		// rather than nothing while the provider is failing.
+		if opts.UnreadOnly || opts.PageToken != "" {
+			return tracing.Error(span, fmt.Errorf("failed to list messages: %w", err))
+		}
+		tracing.RecordError(span, err)
+		slog.Warn("failed to list messages, showing stored emails", "user_id", userID, "error", err)
+
+		message, err := s.storedEmailList(ctx, user, emailRepo.PageRequest{Limit: int(opts.MaxResults)})
+		if err != nil {
+			return tracing.Error(span, err)
+		}
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgStoredFallback), message)
+	}
+	span.SetAttributes(tracing.MessageCount(len(list.Messages)))
+
+	if len(list.Messages) == 0 {
+		if opts.UnreadOnly {
+			return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgNoUnreadEmails).
+				WithQuickReply(lineRepo.MessageAction(labelMailList, CommandMailList)))
+		}
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgNoEmails).
+			WithQuickReply(lineRepo.MessageAction(labelUnreadOnly, CommandUnreadMail)))
 	}
 
-	if len(messages) == 0 {
-		return s.lineRepo.PushMessage(ctx, userID, msgNoUnreadEmails)
+	title := titleLatestEmails
+	if opts.UnreadOnly {
+		title = titleUnreadEmails
 	}
 
-	slog.Info("unread email list sent", "user_id", userID, "count", len(messages))
-	return s.lineRepo.PushMessage(ctx, userID, s.formatEmailList(titleUnreadEmails, messages))
+	message := lineRepo.NewTextMessage(s.formatEmailList(title, list.Messages)).
+		WithQuickReply(emailListQuickReply(list, opts)...)
+
+	slog.Info("email list sent", "user_id", userID, "unread_only", opts.UnreadOnly, "count", len(list.Messages))
+	return s.Respond(ctx, userID, replyToken, message)
 }
 
-func (s *Service) SendEmailList(ctx context.Context, userID string, maxResults int64) error {
-	if s.gmailRepo == nil {
-		return s.lineRepo.PushMessage(ctx, userID, msgGmailUnavailable)
-	}
+// MarkAsRead marks the messages listed in a "既読にする" postback.
+func (s *Service) MarkAsRead(ctx context.Context, userID string, messageIDs []string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.MarkAsRead", tracing.UserID(userID), tracing.MessageCount(len(messageIDs)))
+	defer span.End()
 
 	user, err := s.getAuthenticatedUser(ctx, userID)
 	if err != nil {
-		return s.lineRepo.PushMessage(ctx, userID, msgAuthRequired)
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+	provider, ok := s.mailProvider(user)
+	if !ok {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgMailUnavailable))
 	}
 
-	messages, err := s.gmailRepo.GetLatestMessages(ctx, s.getUserToken(user), maxResults)
-	if err != nil {
-		return fmt.Errorf("failed to get latest messages: %w", err)
+	if err := provider.MarkAsRead(ctx, s.userToken(ctx, user), messageIDs); err != nil {
+		tracing.RecordError(span, err)
+		// Mailboxes linked before the provider asked for write access
+		// have to be linked again.
+		if errors.Is(err, mailRepo.ErrInsufficientScope) {
+			slog.Info("mail grant lacks a scope, asking to link again", "user_id", userID, "provider", user.MailProvider)
+			info := mailProviders[user.MailProvider]
+			return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgReauthRequired, info.label, info.linkCommand)).
+				WithQuickReply(lineRepo.MessageAction(info.linkCommand, info.linkCommand)))
+		}
+		slog.Warn("failed to mark messages as read", "user_id", userID, "error", err)
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgMarkReadFailed))
 	}
 
-	if len(messages) == 0 {
-		return s.lineRepo.PushMessage(ctx, userID, msgNoEmails)
+	slog.Info("messages marked as read", "user_id", userID, "count", len(messageIDs))
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgMarkedAsRead, len(messageIDs))).
+		WithQuickReply(lineRepo.MessageAction(labelUnreadOnly, CommandUnreadMail), lineRepo.MessageAction(labelMailList, CommandMailList)))
+}
+
+// StartMailAuth sends the sign-in URL of provider. The next text the user
+// sends is taken as the authorization code.
+func (s *Service) StartMailAuth(ctx context.Context, userID, provider string, replyToken lineRepo.ReplyToken) error {
+	info := mailProviders[provider]
+	repo, ok := s.providers[provider]
+	if !ok {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgMailUnavailableAuth, info.label)))
 	}
 
-	slog.Info("email list sent", "user_id", userID, "count", len(messages))
-	return s.lineRepo.PushMessage(ctx, userID, s.formatEmailList(titleLatestEmails, messages))
+	s.setPendingAuth(userID, provider)
+	authURL := repo.GetAuthURL(userID)
+
+	if err := s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgAuthStart, info.label, info.account)), lineRepo.NewTextMessage(authURL)); err != nil {
+		return fmt.Errorf("failed to send auth instructions: %w", err)
+	}
+
+	slog.Info("mail auth started", "user_id", userID, "provider", provider)
+	return nil
 }
 
-func (s *Service) StartGmailAuth(ctx context.Context, userID string) error {
-	if s.gmailRepo == nil {
-		return s.lineRepo.PushMessage(ctx, userID, msgGmailUnavailableAuth)
+// CompletePendingAuth completes the auth the user started with authCode.
+func (s *Service) CompletePendingAuth(ctx context.Context, userID, authCode string, replyToken lineRepo.ReplyToken) error {
+	provider, _ := s.pendingProvider(userID)
+	return s.CompleteMailAuth(ctx, userID, provider, authCode, replyToken)
+}
+
+// CompleteMailAuth links the mailbox of provider, replacing any mailbox the
+// user linked before.
+func (s *Service) CompleteMailAuth(ctx context.Context, userID, provider, authCode string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.CompleteMailAuth", tracing.UserID(userID), attribute.String("mail.provider", provider))
+	defer span.End()
+
+	repo, ok := s.providers[provider]
+	if !ok {
+		return fmt.Errorf("mail provider %q is not configured", provider)
 	}
+	info := mailProviders[provider]
 
-	s.pendingAuth[userID] = true
-	authURL := s.gmailRepo.GetAuthURL(userID)
+	user, token, err := s.linkMailbox(ctx, userID, provider, repo, authCode)
+	s.audit(ctx, info.linkAction, user, err)
+	if err != nil {
+		return err
+	}
+	s.hooks.Emit(ctx, user.ID, hookRepo.EventAuthLinked, hookRepo.AuthData{Provider: provider})
 
-	if err := s.lineRepo.PushMessage(ctx, userID, msgAuthStart); err != nil {
-		return fmt.Errorf("failed to send instruction: %w", err)
+	if _, err := s.watchMailbox(ctx, user, token); err != nil {
+		slog.Warn("failed to setup mail watch", "user_id", userID, "provider", provider, "error", err)
 	}
 
-	if err := s.lineRepo.PushMessage(ctx, userID, authURL); err != nil {
-		return fmt.Errorf("failed to send auth URL: %w", err)
+	s.clearPendingAuth(userID)
+	s.switchRichMenu(ctx, userID, true)
+
+	message := lineRepo.NewTextMessage(fmt.Sprintf(msgAuthComplete, info.label, CommandUnreadMail, CommandMailList)).
+		WithQuickReply(lineRepo.MessageAction(labelUnreadOnly, CommandUnreadMail), lineRepo.MessageAction(labelMailList, CommandMailList))
+	if err := s.Respond(ctx, userID, replyToken, message); err != nil {
+		return fmt.Errorf("failed to send success message: %w", err)
 	}
 
-	slog.Info("Gmail auth started", "user_id", userID)
+	slog.Info("mail auth completed", "user_id", userID, "provider", provider)
 	return nil
 }
 
-func (s *Service) CompleteGmailAuth(ctx context.Context, userID, authCode string) error {
-	if s.gmailRepo == nil {
-		return fmt.Errorf("gmail repository not initialized")
-	}
-
-	token, err := s.gmailRepo.ExchangeCode(ctx, authCode)
+func (s *Service) linkMailbox(ctx context.Context, userID, provider string, repo mailRepo.MailProvider, authCode string) (*userRepo.User, *oauth2.Token, error) {
+	token, err := repo.ExchangeCode(ctx, authCode)
 	if err != nil {
-		delete(s.pendingAuth, userID)
-		return fmt.Errorf("failed to exchange code: %w", err)
+		s.clearPendingAuth(userID)
+		return nil, nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 
 	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	if user == nil {
 		user = &userRepo.User{LineUserID: userID}
 		if err := s.userRepo.CreateUser(ctx, user); err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
+			return nil, nil, fmt.Errorf("failed to create user: %w", err)
+		}
+	} else if previous, ok := s.mailProvider(user); ok && isMailLinked(user) {
+		// Saving the new tokens forgets the previous watch.
+		if err := previous.StopWatch(ctx, s.userToken(ctx, user), mailWatchID(user)); err != nil {
+			slog.Warn("failed to stop mail watch", "user_id", userID, "provider", user.MailProvider, "error", err)
 		}
 	}
 
-	if err := s.userRepo.UpdateGmailTokens(ctx, userID, token); err != nil {
-		delete(s.pendingAuth, userID)
-		return fmt.Errorf("failed to save tokens: %w", err)
+	if err := s.userRepo.UpdateMailTokens(ctx, userID, provider, token); err != nil {
+		s.clearPendingAuth(userID)
+		return user, nil, fmt.Errorf("failed to save tokens: %w", err)
 	}
+	user.MailProvider = provider
+	user.MailWatchID = nil
+	user.MailSyncCursor = nil
 
-	if err := s.gmailRepo.WatchMailbox(ctx, token, os.Getenv("PUBSUB_TOPIC")); err != nil {
-		slog.Warn("failed to setup Gmail watch", "user_id", userID, "error", err)
-	} else {
-		slog.Info("Gmail watch setup successfully", "user_id", userID)
+	return user, token, nil
+}
+
+// UnlinkMail unlinks the mailbox of the user. An empty provider unlinks
+// whichever mailbox is linked.
+func (s *Service) UnlinkMail(ctx context.Context, userID, provider string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.UnlinkMail", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.getAuthenticatedUser(ctx, userID)
+	if err != nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
 	}
+	if provider != "" && user.MailProvider != provider {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgNotLinked, mailProviders[provider].label)))
+	}
+	info := mailProviders[user.MailProvider]
 
-	delete(s.pendingAuth, userID)
+	if repo, ok := s.mailProvider(user); ok {
+		if err := repo.StopWatch(ctx, s.userToken(ctx, user), mailWatchID(user)); err != nil {
+			slog.Warn("failed to stop mail watch", "user_id", userID, "provider", user.MailProvider, "error", err)
+		}
+	}
 
-	if err := s.lineRepo.PushMessage(ctx, userID, msgAuthComplete); err != nil {
-		return fmt.Errorf("failed to send success message: %w", err)
+	err = s.userRepo.UpdateMailTokens(ctx, userID, user.MailProvider, &oauth2.Token{})
+	s.audit(ctx, info.unlinkAction, user, err)
+	if err != nil {
+		return fmt.Errorf("failed to clear tokens: %w", err)
 	}
+	s.hooks.Emit(ctx, user.ID, hookRepo.EventAuthRevoked, hookRepo.AuthData{Provider: user.MailProvider})
 
-	slog.Info("Gmail auth completed", "user_id", userID)
-	return nil
+	s.switchRichMenu(ctx, userID, false)
+
+	slog.Info("mail unlinked", "user_id", userID, "provider", user.MailProvider)
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgAuthUnlinked, info.label, info.linkCommand)).
+		WithQuickReply(lineRepo.MessageAction(info.linkCommand, info.linkCommand)))
 }
 
-func (s *Service) ProcessGmailPushNotification(ctx context.Context) error {
+// audit records an action the user took on their own account.
+func (s *Service) audit(ctx context.Context, action string, user *userRepo.User, err error) {
+	s.auditDetail(ctx, action, user, "", err)
+}
+
+func (s *Service) auditDetail(ctx context.Context, action string, user *userRepo.User, detail string, err error) {
+	s.logAudit(ctx, auditRepo.ActorUser, action, user, detail, err)
+}
+
+// auditSystem records an action flux took on its own for the user.
+func (s *Service) auditSystem(ctx context.Context, action string, user *userRepo.User, err error) {
+	s.logAudit(ctx, auditRepo.ActorSystem, action, user, "", err)
+}
+
+func (s *Service) logAudit(ctx context.Context, actor, action string, user *userRepo.User, detail string, err error) {
+	event := auditRepo.Event{
+		Actor:  actor,
+		Action: action,
+		Result: auditRepo.ResultSuccess,
+	}
+	if user != nil && user.ID != "" {
+		event.SubjectUserID = &user.ID
+	}
+	if err != nil {
+		event.Result = auditRepo.ResultFailure
+		if detail != "" {
+			detail += ": "
+		}
+		detail += err.Error()
+	}
+	if detail != "" {
+		event.Detail = &detail
+	}
+	s.auditRepo.Log(ctx, event)
+}
+
+// switchRichMenu attaches the linked menu to the user, or detaches it so the
+// default menu is shown again. Menus are optional, so failures are only logged.
+func (s *Service) switchRichMenu(ctx context.Context, userID string, linked bool) {
+	if !linked {
+		if err := s.lineRepo.UnlinkRichMenu(ctx, userID); err != nil {
+			slog.Warn("failed to unlink rich menu", "user_id", userID, "error", err)
+		}
+		return
+	}
+
+	richMenuID, err := s.lineRepo.GetRichMenuIDByAlias(ctx, lineRepo.RichMenuAliasLinked)
+	if err != nil {
+		slog.Warn("failed to resolve rich menu", "alias", lineRepo.RichMenuAliasLinked, "error", err)
+		return
+	}
+	if richMenuID == "" {
+		return
+	}
+
+	if err := s.lineRepo.LinkRichMenu(ctx, userID, richMenuID); err != nil {
+		slog.Warn("failed to link rich menu", "user_id", userID, "error", err)
+	}
+}
+
+// LinkedGmailUsers returns the LINE user IDs of the users with a linked
+// Gmail mailbox. Gmail push notifications only name the address, which is
+// not stored, so each of them is synced.
+func (s *Service) LinkedGmailUsers(ctx context.Context) ([]string, error) {
 	users, err := s.userRepo.GetAllActiveUsers(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get active users: %w", err)
+		return nil, fmt.Errorf("failed to get active users: %w", err)
 	}
 
+	var userIDs []string
 	for _, user := range users {
-		if user.GmailAccessToken == nil || *user.GmailAccessToken == "" {
-			continue
+		if user.MailProvider == mailRepo.ProviderGmail && isMailLinked(&user) {
+			userIDs = append(userIDs, user.LineUserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// ProcessGmailPush syncs the Gmail mailbox of userID after a push notification
+// for historyID. Users whose sync cursor already reached it are skipped.
+func (s *Service) ProcessGmailPush(ctx context.Context, userID string, historyID uint64) error {
+	ctx, span := tracing.Start(ctx, "notification.ProcessGmailPush", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if user == nil || user.MailProvider != mailRepo.ProviderGmail || !isMailLinked(user) {
+		return nil
+	}
+
+	if historyID != 0 && user.MailSyncCursor != nil {
+		if cursor, err := strconv.ParseUint(*user.MailSyncCursor, 10, 64); err == nil && cursor >= historyID {
+			return nil
+		}
+	}
+
+	return tracing.Error(span, s.syncNewEmails(ctx, user))
+}
+
+// queueNewEmails stores unread messages not seen before and queues a
+// notification for each of them.
+func (s *Service) queueNewEmails(ctx context.Context, user *userRepo.User) error {
+	ctx, span := tracing.Start(ctx, "notification.queueNewEmails", tracing.UserID(user.LineUserID))
+	defer span.End()
+
+	provider, ok := s.mailProvider(user)
+	if !ok {
+		return tracing.Error(span, fmt.Errorf("mail provider %s is not configured", user.MailProvider))
+	}
+
+	messages, err := provider.GetUnreadMessages(ctx, s.userToken(ctx, user), s.cfg.MaxPushEmails)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get unread messages: %w", err))
+	}
+	span.SetAttributes(tracing.MessageCount(len(messages)))
+
+	return tracing.Error(span, s.storeNewEmails(ctx, user, messages))
+}
+
+// ProcessMailboxChange syncs the mailbox whose watch reported a change and
+// queues a notification for each new unread message.
+func (s *Service) ProcessMailboxChange(ctx context.Context, watchID string) error {
+	ctx, span := tracing.Start(ctx, "notification.ProcessMailboxChange")
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByMailWatchID(ctx, watchID)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if user == nil || !isMailLinked(user) {
+		slog.Info("ignoring change of unknown mail watch", "watch_id", watchID)
+		return nil
+	}
+	span.SetAttributes(tracing.UserID(user.LineUserID))
+
+	return tracing.Error(span, s.syncNewEmails(ctx, user))
+}
+
+// syncNewEmails queues the messages added since the sync cursor of the user.
+// Without a usable cursor it checks the unread messages instead and starts
+// a new sync.
+func (s *Service) syncNewEmails(ctx context.Context, user *userRepo.User) error {
+	provider, ok := s.mailProvider(user)
+	if !ok {
+		return fmt.Errorf("mail provider %s is not configured", user.MailProvider)
+	}
+	token := s.userToken(ctx, user)
+
+	var cursor string
+	if user.MailSyncCursor != nil {
+		cursor = *user.MailSyncCursor
+	}
+
+	var changes *mailRepo.Changes
+	var err error
+	if cursor != "" {
+		changes, err = provider.GetChanges(ctx, token, cursor)
+	}
+	if cursor == "" || errors.Is(err, mailRepo.ErrCursorExpired) {
+		slog.Warn("mail sync cursor missing or expired, checking unread messages", "user_id", user.LineUserID)
+		if err := s.queueNewEmails(ctx, user); err != nil {
+			return err
 		}
+		changes, err = provider.GetChanges(ctx, token, "")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get mailbox changes: %w", err)
+	}
+
+	// The cursor only advances once every message is stored, so a retry
+	// fetches the failed ones again.
+	if err := s.storeNewEmails(ctx, user, changes.Messages); err != nil {
+		return err
+	}
+
+	return s.userRepo.UpdateMailSyncCursor(ctx, user.ID, changes.Cursor)
+}
+
+// storeNewEmails stores messages and queues their notifications. It tries
+// every message and returns the joined errors of the failed ones.
+func (s *Service) storeNewEmails(ctx context.Context, user *userRepo.User, messages []*mailRepo.Message) error {
+	var traceParent *string
+	if tp := tracing.TraceParent(ctx); tp != "" {
+		traceParent = &tp
+	}
 
-		messages, err := s.gmailRepo.GetUnreadMessages(ctx, s.getUserToken(&user), maxPushEmails)
+	var errs []error
+	for _, msg := range messages {
+		stored, err := s.storeEmail(ctx, user, msg, traceParent, true)
 		if err != nil {
-			slog.Error("failed to get unread messages", "user_id", user.LineUserID, "error", err)
+			slog.Error("failed to create email record", "message_id", msg.ID, "error", err)
+			errs = append(errs, fmt.Errorf("failed to store message %s: %w", msg.ID, err))
 			continue
 		}
+		if stored {
+			slog.Info("push notification queued", "user_id", user.LineUserID, "message_id", msg.ID)
+		}
+	}
+
+	return errors.Join(errs...)
+}
 
-		for _, msg := range messages {
-			existingEmail, err := s.emailRepo.GetEmailByGmailMessageID(ctx, msg.ID)
-			if err != nil {
-				slog.Error("failed to check email existence", "message_id", msg.ID, "error", err)
-				continue
-			}
-
-			if existingEmail != nil {
-				continue
-			}
-
-			email := &emailRepo.Email{
-				UserID:         user.ID,
-				GmailMessageID: msg.ID,
-				SenderEmail:    msg.From,
-				Subject:        &msg.Subject,
-				BodyPreview:    &msg.Snippet,
-				ReceivedAt:     msg.Date,
-				IsNotified:     false,
-			}
-
-			if err := s.emailRepo.CreateEmail(ctx, email); err != nil {
-				slog.Error("failed to create email record", "message_id", msg.ID, "error", err)
-				continue
-			}
+// storeEmail saves msg unless it is already known and reports whether it
+// did. With notify the notification is queued in the same transaction, so a
+// crash cannot leave a stored email without a pending notification.
+func (s *Service) storeEmail(ctx context.Context, user *userRepo.User, msg *mailRepo.Message, traceParent *string, notify bool) (bool, error) {
+	messageKey := msg.ID
+	if s.cfg.HashMessageIDs {
+		messageKey = hashMessageID(msg.ID)
+	}
+
+	existingEmail, err := s.emailRepo.GetEmailByGmailMessageID(ctx, messageKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email existence: %w", err)
+	}
+	if existingEmail != nil {
+		return false, nil
+	}
+
+	email := &emailRepo.Email{
+		UserID:         user.ID,
+		GmailMessageID: messageKey,
+		ReceivedAt:     msg.Date,
+		IsNotified:     !notify,
+	}
+	if !s.cfg.HashMessageIDs {
+		email.SenderEmail = msg.From
+		email.Subject = &msg.Subject
+		email.BodyPreview = &msg.Snippet
+	}
+
+	if !notify {
+		return true, s.emailRepo.CreateEmail(ctx, email)
+	}
+
+	entries, err := s.notificationEntries(ctx, user, "email:"+messageKey, s.newEmailNotification(msg), traceParent)
+	if err != nil {
+		return false, err
+	}
+	deliveries, err := s.hooks.Deliveries(ctx, user.ID, hookRepo.EventEmailReceived, s.newEmailReceivedData(msg, messageKey))
+	if err != nil {
+		return false, err
+	}
+	return true, s.emailRepo.CreateEmailWithOutbox(ctx, email, entries, deliveries)
+}
+
+// notificationEntries fans n out to LINE and every enabled channel the user
+// registered, one outbox entry each.
+func (s *Service) notificationEntries(ctx context.Context, user *userRepo.User, key string, n notifierRepo.Notification, traceParent *string) ([]*outboxRepo.Entry, error) {
+	message, err := json.Marshal(n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	entries := []*outboxRepo.Entry{{
+		IdempotencyKey: key,
+		RetryKey:       uuid.NewString(),
+		Channel:        outboxRepo.ChannelLine,
+		Recipient:      user.LineUserID,
+		Message:        string(message),
+		TraceParent:    traceParent,
+	}}
+
+	channels, err := s.channelRepo.ListChannels(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range channels {
+		if _, ok := s.notifiers[ch.Channel]; !ok {
+			continue
 		}
+		entries = append(entries, &outboxRepo.Entry{
+			IdempotencyKey: key + ":" + ch.Channel,
+			RetryKey:       uuid.NewString(),
+			Channel:        ch.Channel,
+			Recipient:      ch.Target,
+			Message:        string(message),
+			TraceParent:    traceParent,
+		})
+	}
 
-		unnotifiedEmails, err := s.emailRepo.GetUnnotifiedEmailsByUserID(ctx, user.ID)
-		if err != nil {
-			slog.Error("failed to get unnotified emails", "user_id", user.LineUserID, "error", err)
+	return entries, nil
+}
+
+type replyChannelKey struct{}
+
+type replyChannel struct {
+	channel   string
+	recipient string
+}
+
+// WithReplyChannel makes Respond answer on channel instead of LINE, for
+// commands that arrived there from recipient.
+func WithReplyChannel(ctx context.Context, channel, recipient string) context.Context {
+	return context.WithValue(ctx, replyChannelKey{}, replyChannel{channel: channel, recipient: recipient})
+}
+
+// Respond answers a user command. It uses the reply token while it is still
+// valid, since replies do not count against the push quota, and falls back
+// to push messages when the token has expired or the reply is rejected.
+func (s *Service) Respond(ctx context.Context, userID string, replyToken lineRepo.ReplyToken, messages ...lineRepo.Message) error {
+	ctx, span := tracing.Start(ctx, "notification.Respond", tracing.UserID(userID))
+	defer span.End()
+
+	if target, ok := ctx.Value(replyChannelKey{}).(replyChannel); ok {
+		span.SetAttributes(attribute.String("notification.channel", target.channel))
+		responder, ok := s.notifiers[target.channel].(notifierRepo.Responder)
+		if !ok {
+			return tracing.Error(span, fmt.Errorf("channel %s cannot respond to commands", target.channel))
+		}
+		return tracing.Error(span, responder.Respond(ctx, target.recipient, messages...))
+	}
+
+	if replyToken.IsValid(time.Now()) {
+		err := s.lineRepo.ReplyMessage(ctx, replyToken.Token, messages...)
+		if err == nil {
+			span.SetAttributes(attribute.Bool("line.replied", true))
+			return nil
+		}
+		slog.Warn("failed to reply, falling back to push", "user_id", userID, "error", err)
+	}
+
+	span.SetAttributes(attribute.Bool("line.replied", false))
+	return tracing.Error(span, s.lineRepo.PushMessages(ctx, userID, messages...))
+}
+
+// authRequiredMessage asks the user to link one of the configured providers.
+func (s *Service) authRequiredMessage() lineRepo.Message {
+	var labels, commands []string
+	var actions []lineRepo.Action
+	for _, name := range providerOrder {
+		if _, ok := s.providers[name]; !ok {
 			continue
 		}
+		info := mailProviders[name]
+		labels = append(labels, info.label)
+		commands = append(commands, "「"+info.linkCommand+"」")
+		actions = append(actions, lineRepo.MessageAction(info.linkCommand, info.linkCommand))
+	}
+
+	label := labelMail
+	if len(labels) == 1 {
+		label = labels[0]
+	}
+	return lineRepo.NewTextMessage(fmt.Sprintf(msgAuthRequired, label, strings.Join(commands, "または"))).
+		WithQuickReply(actions...)
+}
 
-		for _, email := range unnotifiedEmails {
-			msg := &gmailRepo.Message{
-				ID:      email.GmailMessageID,
-				From:    email.SenderEmail,
-				Subject: *email.Subject,
-				Snippet: *email.BodyPreview,
-				Date:    email.ReceivedAt,
-			}
-
-			if err := s.lineRepo.PushMessage(ctx, user.LineUserID, s.formatNewEmail(msg)); err != nil {
-				slog.Error("failed to send LINE notification", "user_id", user.LineUserID, "message_id", msg.ID, "error", err)
-				continue
-			}
-
-			if err := s.emailRepo.MarkEmailAsNotified(ctx, email.GmailMessageID); err != nil {
-				slog.Error("failed to mark email as notified", "message_id", email.GmailMessageID, "error", err)
-				continue
-			}
-
-			slog.Info("push notification sent", "user_id", user.LineUserID, "message_id", msg.ID, "subject", msg.Subject)
+// emailListQuickReply offers the next steps after a list listed with opts:
+// the next page, the unread-only view and marking the listed messages as
+// read.
+func emailListQuickReply(list *mailRepo.MessageList, opts mailRepo.ListOptions) []lineRepo.Action {
+	var actions []lineRepo.Action
+
+	if list.NextPageToken != "" {
+		data := url.Values{}
+		data.Set("action", PostbackActionMailList)
+		data.Set("page", list.NextPageToken)
+		data.Set("limit", strconv.FormatInt(opts.MaxResults, 10))
+		if opts.UnreadOnly {
+			data.Set("unread", "1")
 		}
+		actions = append(actions, lineRepo.PostbackAction(labelMore, data.Encode(), labelMore))
 	}
 
-	return nil
+	if !opts.UnreadOnly {
+		actions = append(actions, lineRepo.MessageAction(labelUnreadOnly, CommandUnreadMail))
+	}
+
+	ids := make([]string, 0, len(list.Messages))
+	for _, msg := range list.Messages {
+		ids = append(ids, msg.ID)
+	}
+	data := url.Values{}
+	data.Set("action", PostbackActionMarkRead)
+	data.Set("ids", strings.Join(ids, ","))
+	// Some providers use IDs too long to fit a page of them.
+	if encoded := data.Encode(); len(encoded) <= maxPostbackData {
+		actions = append(actions, lineRepo.PostbackAction(labelMarkRead, encoded, labelMarkRead))
+	}
+
+	return actions
 }
 
-func (s *Service) formatEmailList(title string, messages []*gmailRepo.Message) string {
+func (s *Service) formatEmailList(title string, messages []*mailRepo.Message) string {
 	text := fmt.Sprintf("%s (%d件)\n\n", title, len(messages))
 	for i, msg := range messages {
 		text += fmt.Sprintf("%d. %s\n件名: %s\n%s\n\n", i+1, msg.From, msg.Subject, msg.Snippet)
@@ -277,6 +949,34 @@ func (s *Service) formatEmailList(title string, messages []*gmailRepo.Message) s
 	return text
 }
 
-func (s *Service) formatNewEmail(msg *gmailRepo.Message) string {
-	return fmt.Sprintf("%s\n\n差出人: %s\n件名: %s\n\n%s", titleNewEmail, msg.From, msg.Subject, msg.Snippet)
+func (s *Service) newEmailNotification(msg *mailRepo.Message) notifierRepo.Notification {
+	n := notifierRepo.Notification{
+		Title:   titleNewEmail,
+		Sender:  msg.From,
+		Subject: msg.Subject,
+		Snippet: msg.Snippet,
+		Text:    fmt.Sprintf("%s\n\n差出人: %s\n件名: %s\n\n%s", titleNewEmail, msg.From, msg.Subject, msg.Snippet),
+	}
+	// The link would keep the message ID that is otherwise only stored
+	// as a hash.
+	if !s.cfg.HashMessageIDs {
+		n.Link = msg.Link
+	}
+	return n
+}
+
+func (s *Service) newEmailReceivedData(msg *mailRepo.Message, messageKey string) hookRepo.EmailReceivedData {
+	data := hookRepo.EmailReceivedData{
+		MessageID:  messageKey,
+		From:       msg.From,
+		To:         msg.To,
+		Subject:    msg.Subject,
+		Snippet:    msg.Snippet,
+		ReceivedAt: msg.Date.UTC(),
+	}
+	if !s.cfg.HashMessageIDs {
+		data.ThreadID = msg.ThreadID
+		data.Link = msg.Link
+	}
+	return data
 }
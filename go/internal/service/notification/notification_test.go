@@ -0,0 +1,252 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	flux_db "github.com/huavcjj/flux/db"
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	"github.com/huavcjj/flux/internal/infrastructure/repository/email"
+	hook_repo "github.com/huavcjj/flux/internal/infrastructure/repository/hook"
+	"github.com/huavcjj/flux/internal/infrastructure/repository/job"
+	"github.com/huavcjj/flux/internal/infrastructure/repository/notifier"
+	"github.com/huavcjj/flux/internal/infrastructure/repository/outbox"
+	"github.com/huavcjj/flux/internal/infrastructure/repository/user"
+	"github.com/huavcjj/flux/internal/migrate"
+	"github.com/huavcjj/flux/internal/service/hook"
+	"golang.org/x/oauth2"
+	_ "modernc.org/sqlite"
+)
+
+// fakeMail is a linked mailbox without mail. Methods the tests do not reach
+// panic through the nil interface.
+type fakeMail struct {
+	mailRepo.MailProvider
+	revoked bool
+}
+
+func (m *fakeMail) GetAuthURL(state string) string {
+	return "https://accounts.example.com/auth?state=" + state
+}
+
+func (m *fakeMail) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: "access-" + code, RefreshToken: "refresh"}, nil
+}
+
+func (m *fakeMail) WatchMailbox(ctx context.Context, token *oauth2.Token, watchID string) (*mailRepo.Watch, error) {
+	return &mailRepo.Watch{ID: "sub-1", Cursor: "1", Expiration: time.Now().Add(7 * 24 * time.Hour)}, nil
+}
+
+func (m *fakeMail) StopWatch(ctx context.Context, token *oauth2.Token, watchID string) error {
+	return nil
+}
+
+func (m *fakeMail) RevokeToken(ctx context.Context, token *oauth2.Token) error {
+	m.revoked = true
+	return nil
+}
+
+// fakeLine records the messages pushed to users.
+type fakeLine struct {
+	lineRepo.LineRepo
+	pushed []string
+}
+
+func (l *fakeLine) PushMessages(ctx context.Context, userID string, messages ...lineRepo.Message) error {
+	for _, m := range messages {
+		l.pushed = append(l.pushed, m.Text)
+	}
+	return nil
+}
+
+func (l *fakeLine) GetRichMenuIDByAlias(ctx context.Context, aliasID string) (string, error) {
+	return "", nil
+}
+
+func (l *fakeLine) UnlinkRichMenu(ctx context.Context, userID string) error {
+	return nil
+}
+
+type auditLog struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (a *auditLog) Log(ctx context.Context, event auditRepo.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, event.Action+"="+event.Result)
+}
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)&_time_format=sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrate.New(db, "sqlite", flux_db.Migrations, flux_db.MigrationsDir("sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// within fails the test instead of hanging when fn blocks.
+func within(t *testing.T, name string, fn func() error) {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s did not return", name)
+	}
+}
+
+func TestLinkAndErase(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	mail := &fakeMail{}
+	line := &fakeLine{}
+	audit := &auditLog{}
+	users := user.NewSQLiteUserRepo(db)
+	s := NewService(
+		map[string]mailRepo.MailProvider{mailRepo.ProviderGmail: mail},
+		line,
+		users,
+		email.NewSQLiteEmailRepo(db),
+		outbox.NewSQLiteOutboxRepo(db),
+		notifier.NewSQLiteChannelRepo(db),
+		nil,
+		audit,
+		hook.NewService(hook_repo.NewSQLiteHookRepo(db), nil, false),
+		Config{},
+	)
+
+	within(t, "StartMailAuth", func() error {
+		return s.StartMailAuth(ctx, "U1", mailRepo.ProviderGmail, lineRepo.ReplyToken{})
+	})
+	if !s.IsAuthPending("U1") {
+		t.Fatal("auth is not pending after StartMailAuth")
+	}
+
+	within(t, "CompletePendingAuth", func() error {
+		return s.CompletePendingAuth(ctx, "U1", "code", lineRepo.ReplyToken{})
+	})
+	if s.IsAuthPending("U1") {
+		t.Error("auth is still pending after linking")
+	}
+	linked, err := s.IsMailLinked(ctx, "U1")
+	if err != nil || !linked {
+		t.Fatalf("IsMailLinked = %v, %v", linked, err)
+	}
+	if last := line.pushed[len(line.pushed)-1]; !strings.Contains(last, "連携が完了しました") {
+		t.Errorf("last message = %q", last)
+	}
+
+	u, err := users.GetUserByLineUserID(ctx, "U1")
+	if err != nil || u == nil {
+		t.Fatalf("GetUserByLineUserID = %+v, %v", u, err)
+	}
+	jobs := job.NewSQLiteJobRepo(db)
+	for _, j := range []struct {
+		typ      string
+		dedupKey string
+		payload  any
+	}{
+		{jobRepo.TypeGmailSync, "gmail-sync:U1:5", jobRepo.GmailSyncPayload{LineUserID: "U1", HistoryID: 5}},
+		{jobRepo.TypeGmailResync, jobRepo.ResyncDedupKeyPrefix(u.ID) + "1", jobRepo.GmailResyncPayload{LineUserID: "U1"}},
+		{jobRepo.TypeOutlookPush, "outlook:sub-1:abc", jobRepo.OutlookPushPayload{SubscriptionID: "sub-1"}},
+		{jobRepo.TypeWatchRenew, "", jobRepo.WatchRenewPayload{WatchID: "sub-1"}},
+		{jobRepo.TypeGmailSync, "gmail-sync:U2:5", jobRepo.GmailSyncPayload{LineUserID: "U2", HistoryID: 5}},
+	} {
+		payload, _ := json.Marshal(j.payload)
+		created := &jobRepo.Job{Type: j.typ, Payload: payload, MaxAttempts: 1, RunAt: time.Now()}
+		if j.dedupKey != "" {
+			created.DedupKey = &j.dedupKey
+		}
+		if err := jobs.CreateJob(ctx, created); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A pending auth is dropped with the rest of the user.
+	s.setPendingAuth("U1", mailRepo.ProviderGmail)
+	within(t, "EraseUserData", func() error {
+		return s.EraseUserData(ctx, "U1", time.Now(), lineRepo.ReplyToken{})
+	})
+	if s.IsAuthPending("U1") {
+		t.Error("auth is still pending after erasure")
+	}
+	if !mail.revoked {
+		t.Error("mail token was not revoked")
+	}
+	u, err = users.GetUserByLineUserID(ctx, "U1")
+	if err != nil || u != nil {
+		t.Errorf("GetUserByLineUserID after erasure = %+v, %v", u, err)
+	}
+	var count int
+	var remaining string
+	if err := db.QueryRow(`SELECT COUNT(*), group_concat(dedup_key) FROM jobs`).Scan(&count, &remaining); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || remaining != "gmail-sync:U2:5" {
+		t.Errorf("%d jobs left after erasure (%q), want only the one of U2", count, remaining)
+	}
+	if last := line.pushed[len(line.pushed)-1]; last != msgErased {
+		t.Errorf("last message = %q", last)
+	}
+	want := []string{
+		auditRepo.ActionGmailLink + "=" + auditRepo.ResultSuccess,
+		auditRepo.ActionGmailTokenRevoke + "=" + auditRepo.ResultSuccess,
+		auditRepo.ActionErase + "=" + auditRepo.ResultSuccess,
+	}
+	if strings.Join(audit.actions, ",") != strings.Join(want, ",") {
+		t.Errorf("audit = %v, want %v", audit.actions, want)
+	}
+}
+
+func TestEmailListQuickReplyKeepsPageSize(t *testing.T) {
+	s := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, Config{MaxUnreadEmails: 10})
+	list := &mailRepo.MessageList{NextPageToken: "next", Messages: []*mailRepo.Message{{ID: "m1"}}}
+
+	actions := emailListQuickReply(list, mailRepo.ListOptions{MaxResults: 25})
+	data, err := url.ParseQuery(actions[0].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Get("action") != PostbackActionMailList || data.Get("page") != "next" {
+		t.Fatalf("postback = %v", data)
+	}
+	limit, _ := strconv.ParseInt(data.Get("limit"), 10, 64)
+	if got := s.pageLimit(limit); got != 25 {
+		t.Errorf("next page size = %d, want 25", got)
+	}
+
+	for _, limit := range []int64{0, -1, maxListResults + 1} {
+		if got := s.pageLimit(limit); got != 10 {
+			t.Errorf("pageLimit(%d) = %d, want the default 10", limit, got)
+		}
+	}
+}
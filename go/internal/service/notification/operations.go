@@ -0,0 +1,242 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	emailRepo "github.com/huavcjj/flux/internal/domain/email"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/tracing"
+	"golang.org/x/oauth2"
+)
+
+const (
+	backfillPageSize    = 100
+	msgTestNotification = "🔔 テスト通知です。\n\nこのメッセージが届いていれば通知は正常に設定されています。"
+)
+
+// ErrUserNotFound is returned by operator actions for unknown or inactive users.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrNotLinked is returned by operator actions for users without mail tokens.
+var ErrNotLinked = errors.New("user has not linked a mailbox")
+
+// ResyncUser checks the mailbox of one user for unread messages that were
+// missed, e.g. while the watch had expired, and queues notifications for them.
+func (s *Service) ResyncUser(ctx context.Context, lineUserID string) error {
+	user, err := s.getAuthenticatedUser(ctx, lineUserID)
+	if err != nil {
+		return ErrNotLinked
+	}
+
+	return s.queueNewEmails(ctx, user)
+}
+
+// RenewWatch registers the mail watch of a linked user again.
+func (s *Service) RenewWatch(ctx context.Context, lineUserID string) (*mailRepo.Watch, error) {
+	user, err := s.getAuthenticatedUser(ctx, lineUserID)
+	if err != nil {
+		return nil, ErrNotLinked
+	}
+
+	return s.watchMailbox(ctx, user, s.userToken(ctx, user))
+}
+
+// RenewWatchByID renews the watch with the ID, for providers that announce
+// when a watch needs renewing. Unknown watches are ignored.
+func (s *Service) RenewWatchByID(ctx context.Context, watchID string) error {
+	user, err := s.userRepo.GetUserByMailWatchID(ctx, watchID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !isMailLinked(user) {
+		slog.Info("ignoring renewal of unknown mail watch", "watch_id", watchID)
+		return nil
+	}
+
+	_, err = s.watchMailbox(ctx, user, s.userToken(ctx, user))
+	return err
+}
+
+// DeactivateUser stops the watch, drops the stored tokens and hides the user
+// from all active user queries.
+func (s *Service) DeactivateUser(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "notification.DeactivateUser")
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	span.SetAttributes(tracing.UserID(user.LineUserID))
+
+	if provider, ok := s.mailProvider(user); ok && isMailLinked(user) {
+		if err := provider.StopWatch(ctx, s.userToken(ctx, user), mailWatchID(user)); err != nil {
+			slog.Warn("failed to stop mail watch", "user_id", user.LineUserID, "error", err)
+		}
+	}
+
+	found, err := s.userRepo.DeactivateUser(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+
+	s.switchRichMenu(ctx, user.LineUserID, false)
+
+	slog.Info("user deactivated", "user_id", user.LineUserID)
+	return nil
+}
+
+// Backfill stores the messages received since the given time that were
+// never seen, e.g. after an outage of the push endpoint. Without notify they
+// are stored as already notified. It returns the number of stored messages.
+func (s *Service) Backfill(ctx context.Context, lineUserID string, since time.Time, notify bool) (int, error) {
+	ctx, span := tracing.Start(ctx, "notification.Backfill", tracing.UserID(lineUserID))
+	defer span.End()
+
+	user, err := s.getAuthenticatedUser(ctx, lineUserID)
+	if err != nil {
+		return 0, ErrNotLinked
+	}
+	provider, ok := s.mailProvider(user)
+	if !ok {
+		return 0, fmt.Errorf("mail provider %s is not configured", user.MailProvider)
+	}
+	token := s.userToken(ctx, user)
+
+	stored := 0
+	opts := mailRepo.ListOptions{MaxResults: backfillPageSize, After: since}
+	for {
+		list, err := provider.ListMessages(ctx, token, opts)
+		if err != nil {
+			return stored, tracing.Error(span, err)
+		}
+
+		for _, msg := range list.Messages {
+			ok, err := s.storeEmail(ctx, user, msg, nil, notify)
+			if err != nil {
+				slog.Error("failed to create email record", "message_id", msg.ID, "error", err)
+				continue
+			}
+			if ok {
+				stored++
+			}
+		}
+
+		if list.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = list.NextPageToken
+	}
+
+	span.SetAttributes(tracing.MessageCount(stored))
+	return stored, nil
+}
+
+// BackfillOutbox queues the notifications of the emails that are still not
+// notified but have none queued, such as emails stored before notifications
+// went through the outbox. Queued ones are left alone, so it is safe to run
+// at every start. It returns the number of emails it checked.
+func (s *Service) BackfillOutbox(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "notification.BackfillOutbox")
+	defer span.End()
+
+	users, err := s.userRepo.GetAllActiveUsers(ctx)
+	if err != nil {
+		return 0, tracing.Error(span, fmt.Errorf("failed to get active users: %w", err))
+	}
+
+	checked := 0
+	for _, user := range users {
+		req := emailRepo.PageRequest{Limit: backfillPageSize}
+		for {
+			page, err := s.emailRepo.GetUnnotifiedEmailsByUserID(ctx, user.ID, req)
+			if err != nil {
+				return checked, tracing.Error(span, err)
+			}
+
+			for _, email := range page.Emails {
+				if err := s.queueStoredEmail(ctx, &user, &email); err != nil {
+					return checked, tracing.Error(span, err)
+				}
+				checked++
+			}
+
+			if page.Next == nil {
+				break
+			}
+			req.Cursor = page.Next
+		}
+	}
+
+	span.SetAttributes(tracing.MessageCount(checked))
+	return checked, nil
+}
+
+// queueStoredEmail queues the notifications of an email from what is stored
+// of it. The outbox ignores the entries that are already queued.
+func (s *Service) queueStoredEmail(ctx context.Context, user *userRepo.User, email *emailRepo.Email) error {
+	msg := &mailRepo.Message{
+		ID:   email.GmailMessageID,
+		From: email.SenderEmail,
+		Date: email.ReceivedAt,
+	}
+	if email.Subject != nil {
+		msg.Subject = *email.Subject
+	}
+	if email.BodyPreview != nil {
+		msg.Snippet = *email.BodyPreview
+	}
+
+	entries, err := s.notificationEntries(ctx, user, "email:"+email.GmailMessageID, s.newEmailNotification(msg), nil)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry.UserID = user.ID
+		entry.EmailID = &email.ID
+		if err := s.outboxRepo.CreateEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendTestNotification pushes a fixed message to check the LINE channel.
+func (s *Service) SendTestNotification(ctx context.Context, lineUserID string) error {
+	return s.lineRepo.PushMessage(ctx, lineUserID, msgTestNotification)
+}
+
+// watchMailbox registers or renews the watch of the user. Providers that
+// renew a watch without a new cursor keep syncing from the stored one.
+func (s *Service) watchMailbox(ctx context.Context, user *userRepo.User, token *oauth2.Token) (*mailRepo.Watch, error) {
+	provider, ok := s.mailProvider(user)
+	if !ok {
+		return nil, fmt.Errorf("mail provider %s is not configured", user.MailProvider)
+	}
+
+	watch, err := provider.WatchMailbox(ctx, token, mailWatchID(user))
+	if err != nil {
+		return nil, err
+	}
+	if watch.Cursor == "" && user.MailSyncCursor != nil {
+		watch.Cursor = *user.MailSyncCursor
+	}
+
+	if err := s.userRepo.UpdateMailWatch(ctx, user.LineUserID, watch); err != nil {
+		return nil, fmt.Errorf("failed to save mail watch: %w", err)
+	}
+
+	slog.Info("mail watch setup successfully", "user_id", user.LineUserID, "provider", user.MailProvider, "expires_at", watch.Expiration)
+	return watch, nil
+}
@@ -0,0 +1,355 @@
+package notification
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	emailRepo "github.com/huavcjj/flux/internal/domain/email"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/service/hook"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+const (
+	msgExportUnavailable = "データ出力は現在利用できません。管理者にお問い合わせください。"
+	msgExportLink        = "📦 データ出力の準備ができました。\n\n次のメッセージのURLからZIPファイルをダウンロードしてください（%s まで有効）。"
+	msgEraseConfirm      = "⚠️ メール連携、設定、保存済みメールなど、flux に保存されているすべてのデータを削除します。この操作は元に戻せません。\n\n削除する場合は5分以内に「削除する」を押してください。"
+	msgEraseExpired      = "確認の有効期限が切れました。もう一度「データ削除」を送信してください。"
+	msgErased            = "🗑 すべてのデータを削除しました。\n\nご利用ありがとうございました。"
+
+	labelErase = "削除する"
+
+	// ExportPath serves the downloads of signed data export links.
+	ExportPath = "/export"
+
+	eraseConfirmTTL  = 5 * time.Minute
+	exportEmailBatch = 500
+
+	// exportedWebhookChannel marks a registered webhook endpoint in the
+	// exported notification channels.
+	exportedWebhookChannel = "webhook"
+)
+
+var (
+	ErrInvalidExportLink = errors.New("invalid export link")
+	ErrExportLinkExpired = errors.New("export link expired")
+)
+
+// SendDataExportLink replies with a temporary signed link to a ZIP of all
+// data flux keeps about the user.
+func (s *Service) SendDataExportLink(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	if len(s.cfg.ExportSigningKey) == 0 {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgExportUnavailable))
+	}
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	expires := time.Now().Add(s.cfg.ExportURLTTL)
+	link := s.exportURL(user.ID, expires)
+
+	slog.Info("data export link issued", "user_id", userID, "expires_at", expires)
+	s.audit(ctx, auditRepo.ActionExportLink, user, nil)
+	return s.Respond(ctx, userID, replyToken,
+		lineRepo.NewTextMessage(fmt.Sprintf(msgExportLink, expires.Local().Format("01/02 15:04"))),
+		lineRepo.NewTextMessage(link))
+}
+
+func (s *Service) exportURL(userID string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{}
+	query.Set("u", userID)
+	query.Set("exp", exp)
+	query.Set("sig", s.exportSignature(userID, exp))
+	return strings.TrimSuffix(s.cfg.PublicURL, "/") + ExportPath + "?" + query.Encode()
+}
+
+func (s *Service) exportSignature(userID, exp string) string {
+	mac := hmac.New(sha256.New, s.cfg.ExportSigningKey)
+	mac.Write([]byte(userID + "." + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyExportLink checks the query of an export link and returns the user
+// it was issued for.
+func (s *Service) VerifyExportLink(query url.Values, now time.Time) (string, error) {
+	if len(s.cfg.ExportSigningKey) == 0 {
+		return "", ErrInvalidExportLink
+	}
+
+	userID, exp := query.Get("u"), query.Get("exp")
+	if !hmac.Equal([]byte(query.Get("sig")), []byte(s.exportSignature(userID, exp))) {
+		return "", ErrInvalidExportLink
+	}
+
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidExportLink
+	}
+	if now.After(time.Unix(expires, 0)) {
+		return "", ErrExportLinkExpired
+	}
+
+	return userID, nil
+}
+
+type exportedUser struct {
+	ID                 string     `json:"id"`
+	LineUserID         string     `json:"line_user_id"`
+	GmailLinked        bool       `json:"gmail_linked"`
+	MailProvider       string     `json:"mail_provider,omitempty"`
+	MailWatchExpiresAt *time.Time `json:"mail_watch_expires_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type exportedSettings struct {
+	// EmailRetentionDays is null when the default retention applies.
+	EmailRetentionDays        *int `json:"email_retention_days"`
+	DefaultEmailRetentionDays int  `json:"default_email_retention_days"`
+	// NotificationChannels lists the channels notified besides LINE.
+	// Their webhooks are credentials and are left out.
+	NotificationChannels []string `json:"notification_channels"`
+}
+
+type exportedEmail struct {
+	GmailMessageID string    `json:"gmail_message_id"`
+	SenderEmail    string    `json:"sender_email"`
+	Subject        *string   `json:"subject"`
+	BodyPreview    *string   `json:"body_preview"`
+	ReceivedAt     time.Time `json:"received_at"`
+	IsNotified     bool      `json:"is_notified"`
+}
+
+// WriteDataExport writes a ZIP of the user record, settings and stored
+// emails of the user. OAuth tokens and webhooks are credentials and are
+// left out.
+func (s *Service) WriteDataExport(ctx context.Context, userID string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "notification.WriteDataExport")
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	span.SetAttributes(tracing.UserID(user.LineUserID))
+
+	err = s.writeDataExport(ctx, user, w)
+	s.audit(ctx, auditRepo.ActionExport, user, err)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	slog.Info("data exported", "user_id", user.LineUserID)
+	return nil
+}
+
+func (s *Service) writeDataExport(ctx context.Context, user *userRepo.User, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	if err := writeJSONFile(archive, "user.json", exportUser(user)); err != nil {
+		return err
+	}
+	channels, err := s.channelRepo.ListChannels(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	settings := exportedSettings{
+		EmailRetentionDays:        user.EmailRetentionDays,
+		DefaultEmailRetentionDays: s.cfg.EmailRetentionDays,
+		NotificationChannels:      make([]string, 0, len(channels)),
+	}
+	for _, ch := range channels {
+		settings.NotificationChannels = append(settings.NotificationChannels, ch.Channel)
+	}
+	if _, err := s.hooks.GetEndpoint(ctx, user.ID); err == nil {
+		settings.NotificationChannels = append(settings.NotificationChannels, exportedWebhookChannel)
+	} else if !errors.Is(err, hook.ErrNotFound) {
+		return err
+	}
+	if err := writeJSONFile(archive, "settings.json", settings); err != nil {
+		return err
+	}
+	if err := s.writeExportedEmails(ctx, archive, user.ID); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish export: %w", err)
+	}
+	return nil
+}
+
+func exportUser(user *userRepo.User) exportedUser {
+	exported := exportedUser{
+		ID:          user.ID,
+		LineUserID:  user.LineUserID,
+		GmailLinked: isMailLinked(user) && user.MailProvider == mailRepo.ProviderGmail,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+	if isMailLinked(user) {
+		exported.MailProvider = user.MailProvider
+	}
+	if user.MailWatchExpiresAt != nil {
+		expires := time.Unix(*user.MailWatchExpiresAt, 0)
+		exported.MailWatchExpiresAt = &expires
+	}
+	return exported
+}
+
+// writeExportedEmails streams the stored emails as a JSON array, a page at
+// a time, so large mailboxes are never held in memory.
+func (s *Service) writeExportedEmails(ctx context.Context, archive *zip.Writer, userID string) error {
+	f, err := archive.Create("emails.json")
+	if err != nil {
+		return fmt.Errorf("failed to add emails.json: %w", err)
+	}
+
+	if _, err := io.WriteString(f, "["); err != nil {
+		return err
+	}
+
+	req := emailRepo.PageRequest{Limit: exportEmailBatch}
+	sep := "\n  "
+	for {
+		page, err := s.emailRepo.GetEmailsByUserID(ctx, userID, req)
+		if err != nil {
+			return err
+		}
+
+		for _, email := range page.Emails {
+			b, err := json.Marshal(exportedEmail{
+				GmailMessageID: email.GmailMessageID,
+				SenderEmail:    email.SenderEmail,
+				Subject:        email.Subject,
+				BodyPreview:    email.BodyPreview,
+				ReceivedAt:     email.ReceivedAt,
+				IsNotified:     email.IsNotified,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to encode email: %w", err)
+			}
+			if _, err := io.WriteString(f, sep); err != nil {
+				return err
+			}
+			if _, err := f.Write(b); err != nil {
+				return err
+			}
+			sep = ",\n  "
+		}
+
+		if page.Next == nil {
+			break
+		}
+		req.Cursor = page.Next
+	}
+
+	_, err = io.WriteString(f, "\n]\n")
+	return err
+}
+
+func writeJSONFile(archive *zip.Writer, name string, v any) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// ConfirmDataErasure asks the user to confirm the deletion of all their data
+// with a postback that expires after a few minutes.
+func (s *Service) ConfirmDataErasure(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	data := url.Values{}
+	data.Set("action", PostbackActionErase)
+	data.Set("at", strconv.FormatInt(time.Now().Unix(), 10))
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgEraseConfirm).
+		WithQuickReply(lineRepo.PostbackAction(labelErase, data.Encode(), labelErase)))
+}
+
+// EraseUserData deletes everything flux keeps about the user after the
+// confirmation requested at requestedAt. The mail grant is revoked and the
+// watch stopped first, so no new data arrives for the user.
+func (s *Service) EraseUserData(ctx context.Context, userID string, requestedAt time.Time, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.EraseUserData", tracing.UserID(userID))
+	defer span.End()
+
+	if time.Since(requestedAt) > eraseConfirmTTL {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgEraseExpired))
+	}
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+
+	if user != nil {
+		if provider, ok := s.mailProvider(user); ok && isMailLinked(user) {
+			token := s.userToken(ctx, user)
+			if err := provider.StopWatch(ctx, token, mailWatchID(user)); err != nil {
+				slog.Warn("failed to stop mail watch", "user_id", userID, "error", err)
+			}
+			// Grants that cannot be revoked through the API stay listed
+			// in the account of the user until they remove them.
+			if err := provider.RevokeToken(ctx, token); !errors.Is(err, mailRepo.ErrRevokeUnsupported) {
+				s.audit(ctx, auditRepo.ActionGmailTokenRevoke, user, err)
+				if err != nil {
+					slog.Warn("failed to revoke mail token", "user_id", userID, "error", err)
+				}
+			}
+		}
+
+		// The trail keeps only users.id, which nothing maps back to the
+		// person once the user row is gone; the IP addresses and user
+		// agents of earlier events are cleared with it.
+		_, err := s.userRepo.EraseUser(ctx, user.ID)
+		s.audit(ctx, auditRepo.ActionErase, user, err)
+		if err != nil {
+			return tracing.Error(span, err)
+		}
+	}
+
+	s.clearPendingAuth(userID)
+	s.switchRichMenu(ctx, userID, false)
+
+	// Only a hash is logged, so the entry cannot be tied back to the user.
+	slog.Info("user data erased", "subject", anonymize(userID))
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgErased))
+}
+
+// anonymize returns a short one-way hash of id for records that must
+// outlive the user.
+func anonymize(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
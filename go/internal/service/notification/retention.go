@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+const (
+	msgRetentionCurrent  = "🗂 保存済みメールの保存期間: %s"
+	msgRetentionDefault  = "既定の保存期間: %s"
+	msgRetentionSet      = "✅ メールの保存期間を%d日に設定しました"
+	msgRetentionReset    = "✅ メールの保存期間を既定（%s）に戻しました"
+	msgRetentionTooLong  = "保存期間は%d日以下で指定してください"
+	msgRetentionChangeIt = "変更: 「%[1]s 日数」\n既定に戻す: 「%[1]s 既定」"
+	retentionForever     = "無期限"
+)
+
+// hashMessageID returns the form of a Gmail message ID stored when only
+// deduplication data is kept.
+func hashMessageID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+func formatRetention(days int) string {
+	if days <= 0 {
+		return retentionForever
+	}
+	return fmt.Sprintf("%d日", days)
+}
+
+// SendEmailRetention tells the user how long flux keeps their stored emails.
+func (s *Service) SendEmailRetention(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	text := fmt.Sprintf(msgRetentionCurrent, formatRetention(s.cfg.EmailRetentionDays))
+	if user.EmailRetentionDays != nil {
+		text = fmt.Sprintf(msgRetentionCurrent, formatRetention(*user.EmailRetentionDays)) + "\n" +
+			fmt.Sprintf(msgRetentionDefault, formatRetention(s.cfg.EmailRetentionDays))
+	}
+
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(text+"\n\n"+fmt.Sprintf(msgRetentionChangeIt, CommandRetention)))
+}
+
+// SetEmailRetention sets the retention override of the user; nil restores
+// the default. Overrides may only shorten the default retention.
+func (s *Service) SetEmailRetention(ctx context.Context, userID string, days *int, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.SetEmailRetention", tracing.UserID(userID))
+	defer span.End()
+
+	if days != nil && s.cfg.EmailRetentionDays > 0 && *days > s.cfg.EmailRetentionDays {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgRetentionTooLong, s.cfg.EmailRetentionDays)))
+	}
+
+	if err := s.userRepo.UpdateEmailRetention(ctx, userID, days); err != nil {
+		return tracing.Error(span, err)
+	}
+
+	if days == nil {
+		slog.Info("email retention reset", "user_id", userID)
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgRetentionReset, formatRetention(s.cfg.EmailRetentionDays))))
+	}
+
+	slog.Info("email retention updated", "user_id", userID, "days", *days)
+	return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgRetentionSet, *days)))
+}
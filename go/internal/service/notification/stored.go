@@ -0,0 +1,142 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+
+	emailRepo "github.com/huavcjj/flux/internal/domain/email"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+const (
+	msgNoSearchResults = "🔍 「%s」に一致する保存済みメールはありません"
+	msgStoredFallback  = "メールサービスに接続できないため、受信済みのメールを表示しています。"
+	titleSearchResults = "🔍 「%s」の検索結果"
+	titleStoredEmails  = "📦 保存済みメール"
+
+	// maxPostbackData is the LINE limit on postback data; longer queries
+	// are answered without a "もっと見る" button.
+	maxPostbackData = 300
+)
+
+// SearchStoredEmails searches the emails flux has stored for the user, so it
+// works without calling Gmail. after continues from a "もっと見る" postback.
+func (s *Service) SearchStoredEmails(ctx context.Context, userID, query, after string, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.SearchStoredEmails", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	cursor, err := parseAfter(after)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	page, err := s.emailRepo.SearchEmails(ctx, user.ID, query, emailRepo.PageRequest{Limit: int(s.cfg.MaxUnreadEmails), Cursor: cursor})
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	span.SetAttributes(tracing.MessageCount(len(page.Emails)))
+
+	if len(page.Emails) == 0 {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(fmt.Sprintf(msgNoSearchResults, query)))
+	}
+
+	more := url.Values{}
+	more.Set("action", PostbackActionSearch)
+	more.Set("q", query)
+
+	slog.Info("stored emails searched", "user_id", userID, "count", len(page.Emails))
+	return s.Respond(ctx, userID, replyToken, storedEmailMessage(fmt.Sprintf(titleSearchResults, query), page, more))
+}
+
+// SendMoreStoredEmails continues the stored email list from a "もっと見る"
+// postback, with the page size of the list it continues.
+func (s *Service) SendMoreStoredEmails(ctx context.Context, userID, after string, limit int64, replyToken lineRepo.ReplyToken) error {
+	ctx, span := tracing.Start(ctx, "notification.SendMoreStoredEmails", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	cursor, err := parseAfter(after)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	message, err := s.storedEmailList(ctx, user, emailRepo.PageRequest{Limit: int(s.pageLimit(limit)), Cursor: cursor})
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	return s.Respond(ctx, userID, replyToken, message)
+}
+
+// storedEmailList lists the emails flux has stored for the user, which
+// stands in for the Gmail list when Gmail cannot be reached.
+func (s *Service) storedEmailList(ctx context.Context, user *userRepo.User, req emailRepo.PageRequest) (lineRepo.Message, error) {
+	page, err := s.emailRepo.GetEmailsByUserID(ctx, user.ID, req)
+	if err != nil {
+		return lineRepo.Message{}, err
+	}
+
+	if len(page.Emails) == 0 {
+		return lineRepo.NewTextMessage(msgNoEmails), nil
+	}
+
+	more := url.Values{}
+	more.Set("action", PostbackActionStoredList)
+	more.Set("limit", strconv.Itoa(req.Limit))
+
+	slog.Info("stored email list sent", "user_id", user.LineUserID, "count", len(page.Emails))
+	return storedEmailMessage(titleStoredEmails, page, more), nil
+}
+
+func parseAfter(after string) (*emailRepo.Cursor, error) {
+	if after == "" {
+		return nil, nil
+	}
+	return emailRepo.ParseCursor(after)
+}
+
+// storedEmailMessage lists a page of stored emails and offers the next page
+// through a postback carrying more and the cursor.
+func storedEmailMessage(title string, page *emailRepo.Page, more url.Values) lineRepo.Message {
+	message := lineRepo.NewTextMessage(formatStoredEmailList(title, page.Emails))
+	if page.Next == nil {
+		return message
+	}
+
+	more.Set("after", page.Next.String())
+	if data := more.Encode(); len(data) <= maxPostbackData {
+		message = message.WithQuickReply(lineRepo.PostbackAction(labelMore, data, labelMore))
+	}
+	return message
+}
+
+func formatStoredEmailList(title string, emails []emailRepo.Email) string {
+	text := fmt.Sprintf("%s (%d件)\n\n", title, len(emails))
+	for i, email := range emails {
+		subject := ""
+		if email.Subject != nil {
+			subject = *email.Subject
+		}
+		text += fmt.Sprintf("%d. %s %s\n件名: %s\n\n", i+1, email.ReceivedAt.Local().Format("01/02 15:04"), email.SenderEmail, subject)
+	}
+	return text
+}
@@ -0,0 +1,155 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	auditRepo "github.com/huavcjj/flux/internal/domain/audit"
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	notifierRepo "github.com/huavcjj/flux/internal/domain/notifier"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/tracing"
+)
+
+const (
+	msgTelegramUnavailable  = "Telegram連携は現在利用できません。"
+	msgTelegramLinkCode     = "Telegramでfluxのボットを開き、次のメッセージを送信してください（%d分間有効）。"
+	msgTelegramLinkURL      = "次のURLからTelegramでfluxのボットを開き「開始」を押してください（%d分間有効）。"
+	msgTelegramLinked       = "✅ Telegram連携が完了しました。\n\n新着メールはLINEとTelegramの両方に届き、このチャットでもコマンドを使えます。「help」でコマンドの一覧を表示します。"
+	msgTelegramCodeInvalid  = "連携コードが正しくないか、有効期限が切れています。LINEで「Telegram連携」を送信して新しいコードを取得してください。"
+	msgTelegramLinkRequired = "このチャットはまだfluxと連携されていません。LINEで「Telegram連携」を送信し、表示されたコードを「/start コード」の形で送信してください。"
+	msgTelegramChatInUse    = "このチャットは別のアカウントと連携されています。先にそのアカウントで「Telegram連携解除」を送信してください。"
+
+	telegramLinkTTL = 10 * time.Minute
+	telegramBotURL  = "https://t.me/"
+
+	// linkCodeAlphabet leaves out characters that are easily confused.
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	linkCodeLength   = 8
+)
+
+// StartTelegramLink issues a one-time code that links a Telegram chat to the
+// user when it is sent to the bot.
+func (s *Service) StartTelegramLink(ctx context.Context, userID string, replyToken lineRepo.ReplyToken) error {
+	if _, ok := s.notifiers[outboxRepo.ChannelTelegram].(notifierRepo.Responder); !ok {
+		return s.Respond(ctx, userID, replyToken, lineRepo.NewTextMessage(msgTelegramUnavailable))
+	}
+
+	user, err := s.userRepo.GetUserByLineUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return s.Respond(ctx, userID, replyToken, s.authRequiredMessage())
+	}
+
+	code, err := s.newTelegramLinkCode(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	minutes := int(telegramLinkTTL / time.Minute)
+	if s.cfg.TelegramBotUsername != "" {
+		return s.Respond(ctx, userID, replyToken,
+			lineRepo.NewTextMessage(fmt.Sprintf(msgTelegramLinkURL, minutes)),
+			lineRepo.NewTextMessage(telegramBotURL+s.cfg.TelegramBotUsername+"?start="+code))
+	}
+	return s.Respond(ctx, userID, replyToken,
+		lineRepo.NewTextMessage(fmt.Sprintf(msgTelegramLinkCode, minutes)),
+		lineRepo.NewTextMessage("/start "+code))
+}
+
+func (s *Service) newTelegramLinkCode(ctx context.Context, userID string) (string, error) {
+	b := make([]byte, linkCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate link code: %w", err)
+	}
+	for i := range b {
+		b[i] = linkCodeAlphabet[int(b[i])%len(linkCodeAlphabet)]
+	}
+	code := string(b)
+
+	err := s.channelRepo.CreateLinkCode(ctx, &notifierRepo.LinkCode{
+		Code:      code,
+		Channel:   outboxRepo.ChannelTelegram,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(telegramLinkTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// LinkTelegram links chatID to the user who was issued code. ctx must carry
+// the Telegram reply channel.
+func (s *Service) LinkTelegram(ctx context.Context, chatID, code string) error {
+	ctx, span := tracing.Start(ctx, "notification.LinkTelegram")
+	defer span.End()
+
+	userID, err := s.channelRepo.TakeLinkCode(ctx, outboxRepo.ChannelTelegram, strings.ToUpper(strings.TrimSpace(code)), time.Now())
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if userID == "" {
+		return s.Respond(ctx, chatID, lineRepo.ReplyToken{}, lineRepo.NewTextMessage(msgTelegramCodeInvalid))
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return s.Respond(ctx, chatID, lineRepo.ReplyToken{}, lineRepo.NewTextMessage(msgTelegramCodeInvalid))
+	}
+	span.SetAttributes(tracing.UserID(user.LineUserID))
+
+	existing, err := s.channelRepo.GetChannelByTarget(ctx, outboxRepo.ChannelTelegram, chatID)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	if existing != nil && existing.UserID != user.ID {
+		return s.Respond(ctx, chatID, lineRepo.ReplyToken{}, lineRepo.NewTextMessage(msgTelegramChatInUse))
+	}
+
+	err = s.channelRepo.SaveChannel(ctx, &notifierRepo.Channel{
+		UserID:  user.ID,
+		Channel: outboxRepo.ChannelTelegram,
+		Target:  chatID,
+	})
+	s.auditDetail(ctx, auditRepo.ActionChannelAdd, user, outboxRepo.ChannelTelegram, err)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	return s.Respond(ctx, user.LineUserID, lineRepo.ReplyToken{}, lineRepo.NewTextMessage(msgTelegramLinked).
+		WithQuickReply(lineRepo.MessageAction(labelUnreadOnly, CommandUnreadMail), lineRepo.MessageAction(labelMailList, CommandMailList)))
+}
+
+// TelegramUser returns the LINE user ID of the user linked to chatID, or an
+// empty string if the chat is not linked. Commands from the chat run as that
+// user.
+func (s *Service) TelegramUser(ctx context.Context, chatID string) (string, error) {
+	ch, err := s.channelRepo.GetChannelByTarget(ctx, outboxRepo.ChannelTelegram, chatID)
+	if err != nil || ch == nil {
+		return "", err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, ch.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return "", nil
+	}
+	return user.LineUserID, nil
+}
+
+// SendTelegramLinkRequired explains how to link an unknown chat. ctx must
+// carry the Telegram reply channel.
+func (s *Service) SendTelegramLinkRequired(ctx context.Context, chatID string) error {
+	return s.Respond(ctx, chatID, lineRepo.ReplyToken{}, lineRepo.NewTextMessage(msgTelegramLinkRequired))
+}
@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	notifierRepo "github.com/huavcjj/flux/internal/domain/notifier"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	"github.com/huavcjj/flux/internal/metrics"
+	"github.com/huavcjj/flux/internal/poller"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	pollInterval = 2 * time.Second
+	batchSize    = 20
+	// leaseDuration hides claimed entries from other dispatchers while they
+	// are being delivered. It must exceed the time a LINE push can take.
+	leaseDuration = time.Minute
+	maxAttempts   = 8
+
+	baseBackoff = 15 * time.Second
+	maxBackoff  = 30 * time.Minute
+)
+
+// Dispatcher delivers notification outbox entries through the notifier of
+// their channel. Delivery is at-least-once; the entry's retry key keeps LINE
+// from showing a retried push twice.
+type Dispatcher struct {
+	outboxRepo outboxRepo.OutboxRepo
+	notifiers  map[string]notifierRepo.Notifier
+
+	*poller.Poller
+}
+
+// NewDispatcher returns a dispatcher; Notify wakes it after new entries
+// were written.
+func NewDispatcher(outboxRepo outboxRepo.OutboxRepo, notifiers map[string]notifierRepo.Notifier) *Dispatcher {
+	d := &Dispatcher{
+		outboxRepo: outboxRepo,
+		notifiers:  notifiers,
+	}
+	d.Poller = poller.New("outbox dispatcher", pollInterval, 1, func(ctx context.Context) bool {
+		d.DispatchDue(ctx)
+		return false
+	})
+	return d
+}
+
+// DispatchDue delivers one batch of due entries and reports how many were sent.
+func (d *Dispatcher) DispatchDue(ctx context.Context) int {
+	now := time.Now()
+	entries, err := d.outboxRepo.ClaimDueEntries(ctx, now, now.Add(leaseDuration), batchSize)
+	if err != nil {
+		slog.Error("failed to claim outbox entries", "error", err)
+		return 0
+	}
+
+	sent := 0
+	for i := range entries {
+		if d.deliver(ctx, &entries[i]) {
+			sent++
+		}
+	}
+
+	return sent
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, entry *outboxRepo.Entry) bool {
+	logger := slog.With("outbox_id", entry.ID, "user_id", subject(entry), "channel", entry.Channel, "attempt", entry.Attempts)
+
+	if entry.TraceParent != nil {
+		ctx = tracing.WithTraceParent(ctx, *entry.TraceParent)
+	}
+	ctx, span := tracing.Start(ctx, "outbox.deliver",
+		tracing.UserID(subject(entry)),
+		attribute.String("outbox.channel", entry.Channel),
+		attribute.Int("outbox.attempt", entry.Attempts),
+	)
+	defer span.End()
+
+	err := tracing.Error(span, d.send(ctx, entry))
+	if err == nil {
+		if err := d.outboxRepo.MarkSent(ctx, entry, time.Now()); err != nil {
+			logger.Error("failed to mark outbox entry sent", "error", err)
+		}
+		metrics.IncNotificationsSent(subject(entry))
+		logger.Info("push notification sent", "idempotency_key", entry.IdempotencyKey)
+		return true
+	}
+
+	if entry.Attempts >= maxAttempts {
+		logger.Error("notification delivery failed permanently", "error", err)
+		if err := d.outboxRepo.MarkFailed(ctx, entry.ID, err.Error()); err != nil {
+			logger.Error("failed to mark outbox entry failed", "error", err)
+		}
+		return false
+	}
+
+	delay := poller.Backoff(entry.Attempts, baseBackoff, maxBackoff)
+	var rateLimit *notifierRepo.RateLimitError
+	if errors.As(err, &rateLimit) && rateLimit.RetryAfter > delay {
+		delay = rateLimit.RetryAfter
+	}
+	nextAttemptAt := time.Now().Add(delay)
+	logger.Warn("notification delivery failed, scheduling retry", "error", err, "next_attempt_at", nextAttemptAt)
+	if err := d.outboxRepo.Reschedule(ctx, entry.ID, nextAttemptAt, err.Error()); err != nil {
+		logger.Error("failed to reschedule outbox entry", "error", err)
+	}
+	return false
+}
+
+func (d *Dispatcher) send(ctx context.Context, entry *outboxRepo.Entry) error {
+	notifier, ok := d.notifiers[entry.Channel]
+	if !ok {
+		return fmt.Errorf("unsupported channel: %q", entry.Channel)
+	}
+
+	notification := decodeNotification(entry.Message)
+	notification.RetryKey = entry.RetryKey
+	return notifier.Send(ctx, entry.Recipient, notification)
+}
+
+// decodeNotification reads the message of an entry. Entries queued before
+// notifications were stored as JSON hold the LINE text itself.
+func decodeNotification(message string) notifierRepo.Notification {
+	var n notifierRepo.Notification
+	if err := json.Unmarshal([]byte(message), &n); err != nil || n.Text == "" {
+		return notifierRepo.Notification{Text: message}
+	}
+	return n
+}
+
+// subject identifies the user of entry in logs and metrics. Only LINE
+// recipients are user IDs; the others are webhooks, which are secrets.
+func subject(entry *outboxRepo.Entry) string {
+	if entry.Channel == outboxRepo.ChannelLine {
+		return entry.Recipient
+	}
+	return entry.UserID
+}
@@ -0,0 +1,231 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	jobRepo "github.com/huavcjj/flux/internal/domain/job"
+	"github.com/huavcjj/flux/internal/poller"
+	"github.com/huavcjj/flux/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultWorkers      = 4
+	defaultPollInterval = time.Second
+	defaultMaxAttempts  = 5
+	defaultStaleAfter   = 5 * time.Minute
+	defaultJobTimeout   = 2 * time.Minute
+
+	pruneBatchSize = 500
+
+	baseBackoff = 10 * time.Second
+	maxBackoff  = 10 * time.Minute
+)
+
+type HandlerFunc func(ctx context.Context, job *jobRepo.Job) error
+
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	// StaleAfter is how long a job may stay running before it is assumed lost
+	// with its worker and handed out again.
+	StaleAfter time.Duration
+	JobTimeout time.Duration
+	// DoneRetention is how long finished jobs are kept; 0 keeps them forever.
+	DoneRetention time.Duration
+}
+
+type Service struct {
+	jobRepo  jobRepo.JobRepo
+	cfg      Config
+	handlers map[string]HandlerFunc
+
+	workers    *poller.Poller
+	maintainer *poller.Poller
+}
+
+func NewService(jobRepo jobRepo.JobRepo, cfg Config) *Service {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = defaultStaleAfter
+	}
+	if cfg.JobTimeout <= 0 {
+		cfg.JobTimeout = defaultJobTimeout
+	}
+
+	s := &Service{
+		jobRepo:  jobRepo,
+		cfg:      cfg,
+		handlers: make(map[string]HandlerFunc),
+	}
+	s.workers = poller.New("job workers", cfg.PollInterval, cfg.Workers, s.processNext)
+	s.maintainer = poller.New("job maintenance", cfg.StaleAfter/2, 1, func(ctx context.Context) bool {
+		s.maintain(ctx)
+		return false
+	})
+	return s
+}
+
+func (s *Service) Register(jobType string, handler HandlerFunc) {
+	s.handlers[jobType] = handler
+}
+
+// Enqueue stores a job for asynchronous processing. A non-empty dedupKey makes
+// repeated enqueues of the same work a no-op.
+func (s *Service) Enqueue(ctx context.Context, jobType, dedupKey string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &jobRepo.Job{
+		Type:        jobType,
+		Payload:     b,
+		MaxAttempts: s.cfg.MaxAttempts,
+		RunAt:       time.Now(),
+	}
+	if dedupKey != "" {
+		job.DedupKey = &dedupKey
+	}
+	if traceParent := tracing.TraceParent(ctx); traceParent != "" {
+		job.TraceParent = &traceParent
+	}
+
+	return s.jobRepo.CreateJob(ctx, job)
+}
+
+// Start launches the worker pool. Jobs run with a context derived from ctx,
+// but only Shutdown aborts in-flight work; it stops claiming and drains first.
+func (s *Service) Start(ctx context.Context) {
+	s.workers.Start(ctx)
+	s.maintainer.Start(ctx)
+
+	slog.Info("job workers started", "workers", s.cfg.Workers)
+}
+
+// Shutdown stops claiming new jobs and waits for running ones to finish. When
+// ctx expires first, in-flight jobs are cancelled; they are retried later.
+func (s *Service) Shutdown(ctx context.Context) error {
+	if err := s.workers.Shutdown(ctx); err != nil {
+		return err
+	}
+	slog.Info("job workers drained")
+
+	return s.maintainer.Shutdown(ctx)
+}
+
+func (s *Service) processNext(ctx context.Context) bool {
+	job, err := s.jobRepo.ClaimJob(ctx, time.Now())
+	if err != nil {
+		slog.Error("failed to claim job", "error", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	s.run(ctx, job)
+	return true
+}
+
+func (s *Service) run(ctx context.Context, job *jobRepo.Job) {
+	logger := slog.With("job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts)
+
+	handler, ok := s.handlers[job.Type]
+	if !ok {
+		logger.Error("no handler registered for job type")
+		if err := s.jobRepo.DeadLetterJob(ctx, job.ID, "no handler registered"); err != nil {
+			logger.Error("failed to dead-letter job", "error", err)
+		}
+		return
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, s.cfg.JobTimeout)
+	if job.TraceParent != nil {
+		jobCtx = tracing.WithTraceParent(jobCtx, *job.TraceParent)
+	}
+	jobCtx, span := tracing.Start(jobCtx, "job "+job.Type,
+		attribute.Int64("job.id", int64(job.ID)),
+		attribute.Int("job.attempt", job.Attempts),
+	)
+	err := tracing.Error(span, handler(jobCtx, job))
+	span.End()
+	cancel()
+
+	if err == nil {
+		if err := s.jobRepo.CompleteJob(ctx, job.ID); err != nil {
+			logger.Error("failed to complete job", "error", err)
+		}
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		logger.Error("job failed permanently, moving to dead letter", "error", err)
+		if err := s.jobRepo.DeadLetterJob(ctx, job.ID, err.Error()); err != nil {
+			logger.Error("failed to dead-letter job", "error", err)
+		}
+		return
+	}
+
+	runAt := time.Now().Add(poller.Backoff(job.Attempts, baseBackoff, maxBackoff))
+	logger.Warn("job failed, scheduling retry", "error", err, "run_at", runAt)
+	if err := s.jobRepo.RetryJob(ctx, job.ID, runAt, err.Error()); err != nil {
+		logger.Error("failed to reschedule job", "error", err)
+	}
+}
+
+// maintain recovers the jobs of lost workers and deletes old finished jobs.
+func (s *Service) maintain(ctx context.Context) {
+	s.requeueStale(ctx)
+	if s.cfg.DoneRetention > 0 {
+		s.pruneDone(ctx)
+	}
+}
+
+// requeueStale hands out the jobs of lost workers again. The attempt was
+// counted when the job was claimed, so jobs that keep crashing their worker
+// end up dead-lettered like any other failing job.
+func (s *Service) requeueStale(ctx context.Context) {
+	lockedBefore := time.Now().Add(-s.cfg.StaleAfter)
+
+	n, err := s.jobRepo.DeadLetterStaleJobs(ctx, lockedBefore)
+	if err != nil {
+		slog.Error("failed to dead-letter stale jobs", "error", err)
+	} else if n > 0 {
+		slog.Error("stale jobs ran out of attempts, moved to dead letter", "count", n)
+	}
+
+	n, err = s.jobRepo.RequeueStaleJobs(ctx, lockedBefore)
+	if err != nil {
+		slog.Error("failed to requeue stale jobs", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Warn("requeued stale jobs", "count", n)
+	}
+}
+
+// pruneDone deletes a batch of the jobs that finished before the retention.
+func (s *Service) pruneDone(ctx context.Context) {
+	n, err := s.jobRepo.DeleteDoneJobs(ctx, time.Now().Add(-s.cfg.DoneRetention), pruneBatchSize)
+	if err != nil {
+		slog.Error("failed to delete done jobs", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("deleted done jobs", "count", n)
+	}
+}
@@ -0,0 +1,224 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	emailRepo "github.com/huavcjj/flux/internal/domain/email"
+	hookRepo "github.com/huavcjj/flux/internal/domain/hook"
+	outboxRepo "github.com/huavcjj/flux/internal/domain/outbox"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+	"github.com/huavcjj/flux/internal/metrics"
+)
+
+const (
+	defaultInterval  = time.Hour
+	defaultBatchSize = 500
+	userPageSize     = 500
+)
+
+type Config struct {
+	// Days is the default retention in days; 0 keeps emails forever unless
+	// a user set an override.
+	Days             int
+	StripAfterNotify bool
+	// HashMessageIDs means email content is not stored, so it is also
+	// dropped from sent notifications and delivered webhook events.
+	HashMessageIDs bool
+	Interval       time.Duration
+	BatchSize      int
+}
+
+// Result summarizes one pruning run. Deleted and Stripped count emails;
+// the notifications and webhook deliveries that copied their content are
+// counted separately.
+type Result struct {
+	Deleted            int64
+	Stripped           int64
+	DeletedDeliveries  int64
+	StrippedDeliveries int64
+}
+
+// Pruner enforces the email retention policy in the background, on the
+// emails and on the notification outbox and webhook deliveries holding
+// their content. Rows are changed in small batches so a large backlog does
+// not hold long locks.
+type Pruner struct {
+	emailRepo  emailRepo.EmailRepo
+	outboxRepo outboxRepo.OutboxRepo
+	hookRepo   hookRepo.HookRepo
+	userRepo   userRepo.UserRepo
+	cfg        Config
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewPruner(emailRepo emailRepo.EmailRepo, outboxRepo outboxRepo.OutboxRepo, hookRepo hookRepo.HookRepo, userRepo userRepo.UserRepo, cfg Config) *Pruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	return &Pruner{
+		emailRepo:  emailRepo,
+		outboxRepo: outboxRepo,
+		hookRepo:   hookRepo,
+		userRepo:   userRepo,
+		cfg:        cfg,
+	}
+}
+
+func (p *Pruner) Start(ctx context.Context) {
+	// Shutdown cancels a run in progress; the rows it did not reach are
+	// pruned by the next one.
+	ctx, p.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := p.Prune(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				slog.Error("email pruning failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *Pruner) Shutdown(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.once.Do(p.cancel)
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("email pruner did not stop: %w", ctx.Err())
+	}
+}
+
+// Prune applies the retention policy as of now.
+func (p *Pruner) Prune(ctx context.Context, now time.Time) (Result, error) {
+	var result Result
+
+	if p.cfg.StripAfterNotify {
+		n, err := p.inBatches(ctx, func(limit int) (int64, error) {
+			return p.emailRepo.ClearNotifiedEmailContent(ctx, limit)
+		})
+		result.Stripped = n
+		metrics.AddEmailsPruned("stripped", n)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if p.cfg.StripAfterNotify || p.cfg.HashMessageIDs {
+		for _, strip := range []func(limit int) (int64, error){
+			func(limit int) (int64, error) { return p.outboxRepo.ClearSentMessages(ctx, limit) },
+			func(limit int) (int64, error) { return p.hookRepo.ClearDeliveredPayloads(ctx, limit) },
+		} {
+			n, err := p.inBatches(ctx, strip)
+			result.StrippedDeliveries += n
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	for offset := 0; ; offset += userPageSize {
+		users, err := p.userRepo.ListUsers(ctx, userRepo.ListFilter{Limit: userPageSize, Offset: offset})
+		if err != nil {
+			return result, err
+		}
+
+		for _, user := range users {
+			days := p.retentionDays(&user)
+			if days <= 0 {
+				continue
+			}
+
+			before := now.AddDate(0, 0, -days)
+			n, err := p.inBatches(ctx, func(limit int) (int64, error) {
+				return p.emailRepo.DeleteEmailsOlderThan(ctx, user.ID, before, limit)
+			})
+			result.Deleted += n
+			metrics.AddEmailsPruned("deleted", n)
+			if err != nil {
+				return result, err
+			}
+
+			for _, del := range []func(limit int) (int64, error){
+				func(limit int) (int64, error) {
+					return p.outboxRepo.DeleteFinishedEntries(ctx, user.ID, before, limit)
+				},
+				func(limit int) (int64, error) {
+					return p.hookRepo.DeleteFinishedDeliveries(ctx, user.ID, before, limit)
+				},
+			} {
+				n, err := p.inBatches(ctx, del)
+				result.DeletedDeliveries += n
+				if err != nil {
+					return result, err
+				}
+			}
+		}
+
+		if len(users) < userPageSize {
+			break
+		}
+	}
+
+	if result != (Result{}) {
+		slog.Info("pruned stored emails", "deleted", result.Deleted, "stripped", result.Stripped,
+			"deleted_deliveries", result.DeletedDeliveries, "stripped_deliveries", result.StrippedDeliveries)
+	}
+	return result, nil
+}
+
+// retentionDays returns the retention of user. An override can only
+// shorten the default, so users cannot keep mail longer than the operator
+// allows.
+func (p *Pruner) retentionDays(user *userRepo.User) int {
+	days := p.cfg.Days
+	if user.EmailRetentionDays != nil && (days == 0 || *user.EmailRetentionDays < days) {
+		days = *user.EmailRetentionDays
+	}
+	return days
+}
+
+// inBatches calls fn until it handles fewer rows than a full batch.
+func (p *Pruner) inBatches(ctx context.Context, fn func(limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, err := fn(p.cfg.BatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(p.cfg.BatchSize) {
+			return total, nil
+		}
+	}
+}
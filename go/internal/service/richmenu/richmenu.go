@@ -0,0 +1,250 @@
+package richmenu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lineRepo "github.com/huavcjj/flux/internal/domain/line"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+)
+
+// Definition is the declarative rich menu file kept in the repository.
+type Definition struct {
+	Menus []MenuDefinition `json:"menus"`
+}
+
+type MenuDefinition struct {
+	Alias       string           `json:"alias"`
+	Default     bool             `json:"default"`
+	Image       string           `json:"image"`
+	Name        string           `json:"name"`
+	ChatBarText string           `json:"chatBarText"`
+	Selected    bool             `json:"selected"`
+	Size        SizeDefinition   `json:"size"`
+	Areas       []AreaDefinition `json:"areas"`
+}
+
+type SizeDefinition struct {
+	Width  int64 `json:"width"`
+	Height int64 `json:"height"`
+}
+
+type AreaDefinition struct {
+	Bounds struct {
+		X      int64 `json:"x"`
+		Y      int64 `json:"y"`
+		Width  int64 `json:"width"`
+		Height int64 `json:"height"`
+	} `json:"bounds"`
+	Action struct {
+		Type  string `json:"type"`
+		Label string `json:"label"`
+		Text  string `json:"text"`
+		Data  string `json:"data"`
+		URI   string `json:"uri"`
+	} `json:"action"`
+}
+
+type Service struct {
+	lineRepo lineRepo.LineRepo
+	userRepo userRepo.UserRepo
+}
+
+func NewService(lineRepo lineRepo.LineRepo, userRepo userRepo.UserRepo) *Service {
+	return &Service{
+		lineRepo: lineRepo,
+		userRepo: userRepo,
+	}
+}
+
+func LoadDefinition(path string) (*Definition, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rich menu definition: %w", err)
+	}
+
+	var def Definition
+	if err := json.Unmarshal(b, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse rich menu definition: %w", err)
+	}
+
+	baseDir := filepath.Dir(path)
+	for i, menu := range def.Menus {
+		if menu.Alias == "" {
+			return nil, fmt.Errorf("menu %d: alias is required", i)
+		}
+		if menu.Image == "" {
+			return nil, fmt.Errorf("menu %q: image is required", menu.Alias)
+		}
+		if !filepath.IsAbs(menu.Image) {
+			def.Menus[i].Image = filepath.Join(baseDir, menu.Image)
+		}
+	}
+
+	return &def, nil
+}
+
+// Apply creates every menu in the definition, points its alias at the new
+// menu and removes the menu the alias previously referred to. Linked users are
+// re-attached afterwards because deleting a menu also drops its user links.
+func (s *Service) Apply(ctx context.Context, def *Definition) error {
+	var staleMenuIDs []string
+
+	for _, menu := range def.Menus {
+		richMenuID, err := s.createMenu(ctx, menu)
+		if err != nil {
+			return fmt.Errorf("menu %q: %w", menu.Alias, err)
+		}
+
+		previousID, err := s.lineRepo.GetRichMenuIDByAlias(ctx, menu.Alias)
+		if err != nil {
+			return fmt.Errorf("menu %q: %w", menu.Alias, err)
+		}
+
+		if err := s.lineRepo.SetRichMenuAlias(ctx, menu.Alias, richMenuID); err != nil {
+			return fmt.Errorf("menu %q: %w", menu.Alias, err)
+		}
+
+		if menu.Default {
+			if err := s.lineRepo.SetDefaultRichMenu(ctx, richMenuID); err != nil {
+				return fmt.Errorf("menu %q: %w", menu.Alias, err)
+			}
+		}
+
+		if previousID != "" && previousID != richMenuID {
+			staleMenuIDs = append(staleMenuIDs, previousID)
+		}
+
+		slog.Info("rich menu applied", "alias", menu.Alias, "rich_menu_id", richMenuID, "default", menu.Default)
+	}
+
+	if err := s.LinkAuthenticatedUsers(ctx); err != nil {
+		return err
+	}
+
+	for _, richMenuID := range staleMenuIDs {
+		if err := s.lineRepo.DeleteRichMenu(ctx, richMenuID); err != nil {
+			slog.Warn("failed to delete stale rich menu", "rich_menu_id", richMenuID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// LinkAuthenticatedUsers attaches the linked menu to every user with mail tokens.
+func (s *Service) LinkAuthenticatedUsers(ctx context.Context) error {
+	richMenuID, err := s.lineRepo.GetRichMenuIDByAlias(ctx, lineRepo.RichMenuAliasLinked)
+	if err != nil {
+		return err
+	}
+	if richMenuID == "" {
+		return nil
+	}
+
+	users, err := s.userRepo.GetAllActiveUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	linked := 0
+	for _, user := range users {
+		if user.MailAccessToken == nil || *user.MailAccessToken == "" {
+			continue
+		}
+		if err := s.lineRepo.LinkRichMenu(ctx, user.LineUserID, richMenuID); err != nil {
+			slog.Warn("failed to link rich menu", "user_id", user.LineUserID, "error", err)
+			continue
+		}
+		linked++
+	}
+
+	slog.Info("rich menu linked to users", "alias", lineRepo.RichMenuAliasLinked, "count", linked)
+	return nil
+}
+
+func (s *Service) Link(ctx context.Context, lineUserID, alias string) error {
+	richMenuID, err := s.lineRepo.GetRichMenuIDByAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if richMenuID == "" {
+		return fmt.Errorf("rich menu alias %q not found", alias)
+	}
+
+	return s.lineRepo.LinkRichMenu(ctx, lineUserID, richMenuID)
+}
+
+func (s *Service) Unlink(ctx context.Context, lineUserID string) error {
+	return s.lineRepo.UnlinkRichMenu(ctx, lineUserID)
+}
+
+func (s *Service) createMenu(ctx context.Context, menu MenuDefinition) (string, error) {
+	contentType, err := imageContentType(menu.Image)
+	if err != nil {
+		return "", err
+	}
+
+	image, err := os.Open(menu.Image)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer image.Close()
+
+	richMenuID, err := s.lineRepo.CreateRichMenu(ctx, toRichMenu(menu))
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.lineRepo.UploadRichMenuImage(ctx, richMenuID, contentType, image); err != nil {
+		if delErr := s.lineRepo.DeleteRichMenu(ctx, richMenuID); delErr != nil {
+			slog.Warn("failed to delete incomplete rich menu", "rich_menu_id", richMenuID, "error", delErr)
+		}
+		return "", err
+	}
+
+	return richMenuID, nil
+}
+
+func toRichMenu(menu MenuDefinition) *lineRepo.RichMenu {
+	areas := make([]lineRepo.RichMenuArea, 0, len(menu.Areas))
+	for _, area := range menu.Areas {
+		areas = append(areas, lineRepo.RichMenuArea{
+			X:      area.Bounds.X,
+			Y:      area.Bounds.Y,
+			Width:  area.Bounds.Width,
+			Height: area.Bounds.Height,
+			Action: lineRepo.Action{
+				Type:  area.Action.Type,
+				Label: area.Action.Label,
+				Text:  area.Action.Text,
+				Data:  area.Action.Data,
+				URI:   area.Action.URI,
+			},
+		})
+	}
+
+	return &lineRepo.RichMenu{
+		Name:        menu.Name,
+		ChatBarText: menu.ChatBarText,
+		Width:       menu.Size.Width,
+		Height:      menu.Size.Height,
+		Selected:    menu.Selected,
+		Areas:       areas,
+	}
+}
+
+func imageContentType(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return "image/png", nil
+	case ".jpg", ".jpeg":
+		return "image/jpeg", nil
+	default:
+		return "", fmt.Errorf("unsupported image format: %s", path)
+	}
+}
@@ -0,0 +1,128 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	mailRepo "github.com/huavcjj/flux/internal/domain/mail"
+	userRepo "github.com/huavcjj/flux/internal/domain/user"
+)
+
+const (
+	defaultInterval    = time.Hour
+	defaultRenewBefore = 24 * time.Hour
+)
+
+type Config struct {
+	Interval time.Duration
+	// RenewBefore is how long before its expiration a watch is renewed. It
+	// must exceed Interval, or watches may lapse between two runs.
+	RenewBefore time.Duration
+}
+
+// WatchRenewer renews the mail watch of a user.
+type WatchRenewer interface {
+	RenewWatch(ctx context.Context, lineUserID string) (*mailRepo.Watch, error)
+}
+
+// Renewer keeps the mail watches alive in the background. Gmail watches
+// last a week and Outlook subscriptions about three days, and neither
+// provider renews them.
+type Renewer struct {
+	userRepo userRepo.UserRepo
+	renewer  WatchRenewer
+	cfg      Config
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewRenewer(userRepo userRepo.UserRepo, renewer WatchRenewer, cfg Config) *Renewer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.RenewBefore <= 0 {
+		cfg.RenewBefore = defaultRenewBefore
+	}
+
+	return &Renewer{
+		userRepo: userRepo,
+		renewer:  renewer,
+		cfg:      cfg,
+	}
+}
+
+func (r *Renewer) Start(ctx context.Context) {
+	// Shutdown cancels a run in progress; the watches it did not reach are
+	// still due on the next one.
+	ctx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.Renew(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				slog.Error("watch renewal failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *Renewer) Shutdown(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.once.Do(r.cancel)
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("watch renewer did not stop: %w", ctx.Err())
+	}
+}
+
+// Renew renews the watches that expire within RenewBefore of now, or were
+// never set up, and reports how many were renewed. A failed user does not
+// stop the others.
+func (r *Renewer) Renew(ctx context.Context, now time.Time) (int, error) {
+	users, err := r.userRepo.GetUsersWithWatchExpiringBefore(ctx, now.Add(r.cfg.RenewBefore))
+	if err != nil {
+		return 0, err
+	}
+
+	renewed, failed := 0, 0
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return renewed, ctx.Err()
+		}
+
+		watch, err := r.renewer.RenewWatch(ctx, u.LineUserID)
+		if err != nil {
+			slog.Warn("failed to renew watch", "user_id", u.LineUserID, "error", err)
+			failed++
+			continue
+		}
+		slog.Info("renewed watch", "user_id", u.LineUserID, "expires_at", watch.Expiration)
+		renewed++
+	}
+
+	if failed > 0 {
+		return renewed, fmt.Errorf("failed to renew %d of %d watches", failed, len(users))
+	}
+	return renewed, nil
+}
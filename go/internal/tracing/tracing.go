@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	tracerName = "github.com/huavcjj/flux"
+)
+
+type Config struct {
+	// Exporter is one of ExporterNone, ExporterOTLP or ExporterStdout.
+	Exporter string
+	// Endpoint is the OTLP/HTTP endpoint URL. When empty the exporter falls
+	// back to the standard OTEL_EXPORTER_OTLP_* environment variables.
+	Endpoint    string
+	ServiceName string
+	// SampleRatio is the fraction of new traces that are recorded; 0
+	// records none.
+	SampleRatio float64
+}
+
+var propagator = propagation.TraceContext{}
+
+// Setup installs the global tracer provider. The returned function flushes
+// pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagator, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "flux"
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	// A ratio of 0 records no new traces, but spans of requests whose
+	// caller sampled them are still recorded.
+	sampler := sdktrace.NeverSample()
+	if cfg.SampleRatio > 0 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError marks span as failed with err. A nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Error records err on span and returns it unchanged.
+func Error(span trace.Span, err error) error {
+	RecordError(span, err)
+	return err
+}
+
+// TraceParent returns the W3C traceparent of the span in ctx so the trace can
+// be resumed after a hop through the database, or "" if there is none.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// WithTraceParent returns ctx with the remote span described by traceParent
+// as its parent.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
+
+// UserID identifies a LINE user without exporting the ID itself. The hash
+// is stable, so spans of one user can still be correlated.
+func UserID(userID string) attribute.KeyValue {
+	return attribute.String("user.id_hash", hash(userID))
+}
+
+func MessageID(messageID string) attribute.KeyValue {
+	return attribute.String("gmail.message_id", messageID)
+}
+
+func MessageCount(n int) attribute.KeyValue {
+	return attribute.Int("gmail.message_count", n)
+}
+
+// SenderDomain keeps only the domain of an address such as
+// "Alice <alice@example.com>".
+func SenderDomain(address string) attribute.KeyValue {
+	domain := ""
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		domain = strings.TrimRight(address[i+1:], "> ")
+	}
+	return attribute.String("email.sender_domain", domain)
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
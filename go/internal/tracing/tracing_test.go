@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetupSampleRatio(t *testing.T) {
+	tests := []struct {
+		ratio float64
+		want  bool
+	}{
+		{0, false},
+		{1, true},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		shutdown, err := Setup(ctx, Config{Exporter: ExporterStdout, SampleRatio: tt.ratio})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, span := Start(ctx, "test")
+		if got := span.SpanContext().IsSampled(); got != tt.want {
+			t.Errorf("ratio %g: sampled = %v, want %v", tt.ratio, got, tt.want)
+		}
+		span.End()
+
+		if err := shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}